@@ -1,6 +1,10 @@
 package config
 
-import "github.com/antoniosarro/gofi/internal/config/parser"
+import (
+	"fmt"
+
+	"github.com/antoniosarro/gofi/internal/config/parser"
+)
 
 // Type aliases for parser types
 type tomlTable = parser.TOMLTable
@@ -18,18 +22,37 @@ type Config struct {
 
 // ModuleConfig represents configuration for a specific module
 type ModuleConfig struct {
-	Enabled           bool
-	EnablePagination  bool
-	ItemsPerPage      int
-	EnableTags        bool
-	EnableHighlight   bool
-	EnableFavorites   bool
-	ScanGameLaunchers bool
-	CustomCSS         string
+	Enabled               bool
+	EnablePagination      bool
+	ItemsPerPage          int
+	EnableTags            bool
+	EnableHighlight       bool
+	EnableFavorites       bool
+	ScanGameLaunchers     bool
+	SortGamesByLastPlayed bool
+	TypoThreshold         int
+	RankMatchWeight       float64
+	RankFrecencyWeight    float64
+	RankAdaptiveWeight    float64
+	Favorites             FavoritesConfig
+	CustomCSS             string
 	// Module-specific settings stored as generic map
 	Settings map[string]interface{}
 }
 
+// FavoritesConfig represents how favorites are scored, from the favorites
+// table of a module such as [module.application.favorites]
+type FavoritesConfig struct {
+	Scorer        string  // How usage is scored, decay or context
+	LaunchWeight  float64 // Importance of a launch
+	SearchWeight  float64 // Importance of a search event
+	DecayLambda   float64 // How fast old events lose importance
+	Threshold     float64 // Score apps become favorites at
+	TopN          int     // Highest scoring apps that are favorites, instead of the threshold if set
+	MaxEvents     int     // Events kept per app
+	RetentionDays int     // Days events are kept
+}
+
 // Load loads configuration from a TOML file
 func Load(path string) (*Config, error) {
 	config := Default()
@@ -78,7 +101,9 @@ func applyConfig(config *Config, data tomlTable) error {
 			}
 
 			// Apply module settings
-			applyModuleConfig(mc, moduleConfig)
+			if err := applyModuleConfig(mc, moduleConfig); err != nil {
+				return fmt.Errorf("module.%s.%w", moduleName, err)
+			}
 			config.Modules[moduleName] = mc
 		}
 	}
@@ -87,7 +112,7 @@ func applyConfig(config *Config, data tomlTable) error {
 }
 
 // applyModuleConfig applies module-specific configuration
-func applyModuleConfig(mc *ModuleConfig, data tomlTable) {
+func applyModuleConfig(mc *ModuleConfig, data tomlTable) error {
 	if enabled, ok := data.GetBool("enabled"); ok {
 		mc.Enabled = enabled
 	}
@@ -109,12 +134,94 @@ func applyModuleConfig(mc *ModuleConfig, data tomlTable) {
 	if scanGames, ok := data.GetBool("scan_game_launchers"); ok {
 		mc.ScanGameLaunchers = scanGames
 	}
+	if sortGames, ok := data.GetBool("sort_games_by_last_played"); ok {
+		mc.SortGamesByLastPlayed = sortGames
+	}
+	if typoThreshold, ok := data.GetInt("typo_threshold"); ok {
+		mc.TypoThreshold = typoThreshold
+	}
+	if matchWeight, ok := data.GetFloat("rank_match_weight"); ok {
+		mc.RankMatchWeight = matchWeight
+	}
+	if frecencyWeight, ok := data.GetFloat("rank_frecency_weight"); ok {
+		mc.RankFrecencyWeight = frecencyWeight
+	}
+	if adaptiveWeight, ok := data.GetFloat("rank_adaptive_weight"); ok {
+		mc.RankAdaptiveWeight = adaptiveWeight
+	}
 	if customCSS, ok := data.GetString("custom_css"); ok {
 		mc.CustomCSS = customCSS
 	}
+	if favorites, ok := data.GetTable("favorites"); ok {
+		if err := applyFavoritesConfig(&mc.Favorites, favorites); err != nil {
+			return fmt.Errorf("favorites.%w", err)
+		}
+	}
 
 	// Store all settings for module-specific use
 	for key, value := range data {
 		mc.Settings[key] = value
 	}
+
+	return nil
+}
+
+// applyFavoritesConfig applies the favorites table of a module, rejecting
+// values scoring can't work with
+func applyFavoritesConfig(fc *FavoritesConfig, data tomlTable) error {
+	if scorer, ok := data.GetString("scorer"); ok {
+		if scorer != "decay" && scorer != "context" {
+			return invalidValue("scorer", scorer, "is neither decay nor context")
+		}
+		fc.Scorer = scorer
+	}
+	if launchWeight, ok := data.GetFloat("launch_weight"); ok {
+		if launchWeight <= 0 {
+			return invalidValue("launch_weight", launchWeight, "must be positive")
+		}
+		fc.LaunchWeight = launchWeight
+	}
+	if searchWeight, ok := data.GetFloat("search_weight"); ok {
+		if searchWeight < 0 {
+			return invalidValue("search_weight", searchWeight, "must not be negative")
+		}
+		fc.SearchWeight = searchWeight
+	}
+	if decayLambda, ok := data.GetFloat("decay_lambda"); ok {
+		if decayLambda < 0 {
+			return invalidValue("decay_lambda", decayLambda, "must not be negative")
+		}
+		fc.DecayLambda = decayLambda
+	}
+	if threshold, ok := data.GetFloat("threshold"); ok {
+		if threshold <= 0 {
+			return invalidValue("threshold", threshold, "must be positive")
+		}
+		fc.Threshold = threshold
+	}
+	if topN, ok := data.GetInt("top_n"); ok {
+		if topN < 0 {
+			return invalidValue("top_n", topN, "must not be negative")
+		}
+		fc.TopN = topN
+	}
+	if maxEvents, ok := data.GetInt("max_events"); ok {
+		if maxEvents <= 0 {
+			return invalidValue("max_events", maxEvents, "must be positive")
+		}
+		fc.MaxEvents = maxEvents
+	}
+	if retentionDays, ok := data.GetInt("retention_days"); ok {
+		if retentionDays <= 0 {
+			return invalidValue("retention_days", retentionDays, "must be positive")
+		}
+		fc.RetentionDays = retentionDays
+	}
+
+	return nil
+}
+
+// invalidValue returns the error of a key set out of its valid range
+func invalidValue(key string, value interface{}, reason string) error {
+	return fmt.Errorf("%s: %w: %v %s", key, ErrInvalidValue, value, reason)
 }
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -56,6 +58,11 @@ enable_tags = true
 enable_highlight = true
 enable_favorites = true
 scan_game_launchers = false
+sort_games_by_last_played = true
+typo_threshold = 5
+rank_match_weight = 2
+rank_frecency_weight = 50.5
+rank_adaptive_weight = 300
 custom_css = "/path/to/custom.css"
 `
 
@@ -107,11 +114,106 @@ custom_css = "/path/to/custom.css"
 		t.Error("ScanGameLaunchers should be false")
 	}
 
+	if !appConfig.SortGamesByLastPlayed {
+		t.Error("SortGamesByLastPlayed should be true")
+	}
+
+	if appConfig.TypoThreshold != 5 {
+		t.Errorf("TypoThreshold = %d, want 5", appConfig.TypoThreshold)
+	}
+
+	if appConfig.RankMatchWeight != 2 || appConfig.RankFrecencyWeight != 50.5 || appConfig.RankAdaptiveWeight != 300 {
+		t.Errorf("Rank weights = %v, %v, %v, want 2, 50.5, 300",
+			appConfig.RankMatchWeight, appConfig.RankFrecencyWeight, appConfig.RankAdaptiveWeight)
+	}
+
 	if appConfig.CustomCSS != "/path/to/custom.css" {
 		t.Errorf("CustomCSS = %q, want %q", appConfig.CustomCSS, "/path/to/custom.css")
 	}
 }
 
+func TestLoadFavorites(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		want    FavoritesConfig
+		wantErr string
+	}{
+		{
+			name:  "Defaults",
+			table: "",
+			want:  defaultFavoritesConfig(),
+		},
+		{
+			name: "Custom",
+			table: `[module.application.favorites]
+scorer = "context"
+launch_weight = 2
+search_weight = 0
+decay_lambda = 0.05
+top_n = 6
+max_events = 200
+retention_days = 30
+`,
+			want: FavoritesConfig{
+				Scorer:        "context",
+				LaunchWeight:  2,
+				SearchWeight:  0,
+				DecayLambda:   0.05,
+				Threshold:     5.0,
+				TopN:          6,
+				MaxEvents:     200,
+				RetentionDays: 30,
+			},
+		},
+		{
+			name:    "Unknown scorer",
+			table:   "[module.application.favorites]\nscorer = \"frequency\"\n",
+			wantErr: "module.application.favorites.scorer",
+		},
+		{
+			name:    "Negative threshold",
+			table:   "[module.application.favorites]\nthreshold = -1\n",
+			wantErr: "module.application.favorites.threshold",
+		},
+		{
+			name:    "Negative top N",
+			table:   "[module.application.favorites]\ntop_n = -3\n",
+			wantErr: "module.application.favorites.top_n",
+		},
+		{
+			name:    "No events kept",
+			table:   "[module.application.favorites]\nmax_events = 0\n",
+			wantErr: "module.application.favorites.max_events",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.toml")
+			content := "[module.application]\nenable_favorites = true\n\n" + tt.table
+			if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test config: %v", err)
+			}
+
+			cfg, err := Load(configPath)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidValue) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want %v for %s", err, ErrInvalidValue, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if got := cfg.Modules["application"].Favorites; got != tt.want {
+				t.Errorf("Favorites = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetConfigPath(t *testing.T) {
 	// Save original env
 	originalXDG := os.Getenv("XDG_CONFIG_HOME")
@@ -16,15 +16,36 @@ func Default() *Config {
 // defaultApplicationConfig returns default config for application module
 func defaultApplicationConfig() *ModuleConfig {
 	return &ModuleConfig{
-		Enabled:           true,
-		EnablePagination:  false,
-		ItemsPerPage:      8,
-		EnableTags:        false,
-		EnableHighlight:   false,
-		EnableFavorites:   false,
-		ScanGameLaunchers: true,
-		CustomCSS:         "",
-		Settings:          make(map[string]interface{}),
+		Enabled:               true,
+		EnablePagination:      false,
+		ItemsPerPage:          8,
+		EnableTags:            false,
+		EnableHighlight:       false,
+		EnableFavorites:       false,
+		ScanGameLaunchers:     true,
+		SortGamesByLastPlayed: false,
+		TypoThreshold:         4,    // Query characters per tolerated typo
+		RankMatchWeight:       1,    // Per point of match score
+		RankFrecencyWeight:    200,  // For frecency scaled to [0, 1)
+		RankAdaptiveWeight:    1500, // For launches after typing the query
+		Favorites:             defaultFavoritesConfig(),
+		CustomCSS:             "",
+		Settings:              make(map[string]interface{}),
+	}
+}
+
+// defaultFavoritesConfig returns default config for favorites scoring,
+// matching the defaults of the favorites package
+func defaultFavoritesConfig() FavoritesConfig {
+	return FavoritesConfig{
+		Scorer:        "decay",
+		LaunchWeight:  1.0,
+		SearchWeight:  0.3,
+		DecayLambda:   0.1, // Noticeable decay after ~7 days
+		Threshold:     5.0,
+		TopN:          0, // Use the threshold
+		MaxEvents:     1000,
+		RetentionDays: 90,
 	}
 }
 
@@ -0,0 +1,8 @@
+package config
+
+import "errors"
+
+var (
+	// ErrInvalidValue indicates a setting out of its valid range
+	ErrInvalidValue = errors.New("config: invalid value")
+)
@@ -241,6 +241,17 @@ func (t TOMLTable) GetInt(key string) (int, bool) {
 	return intVal, ok
 }
 
+// GetFloat gets a number from the table, integers included
+func (t TOMLTable) GetFloat(key string) (float64, bool) {
+	switch val := t[key].(type) {
+	case float64:
+		return val, true
+	case int:
+		return float64(val), true
+	}
+	return 0, false
+}
+
 // GetBool gets a boolean value from the table
 func (t TOMLTable) GetBool(key string) (bool, bool) {
 	val, ok := t[key]
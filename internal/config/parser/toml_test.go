@@ -178,3 +178,33 @@ disabled = false
 		t.Error("disabled should be false")
 	}
 }
+
+func TestParseFloats(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "test.toml")
+
+	content := `ratio = 0.5
+count = 3
+name = "3.5"
+`
+
+	os.WriteFile(configPath, []byte(content), 0644)
+
+	parser := New()
+	data, err := parser.ParseFile(configPath)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	if ratio, ok := data.GetFloat("ratio"); !ok || ratio != 0.5 {
+		t.Errorf("ratio = %v, want 0.5", ratio)
+	}
+
+	if count, ok := data.GetFloat("count"); !ok || count != 3 {
+		t.Errorf("count = %v, want 3", count)
+	}
+
+	if _, ok := data.GetFloat("name"); ok {
+		t.Error("name should not be a number")
+	}
+}
@@ -0,0 +1,86 @@
+package entry
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// applicationInterface is the D-Bus interface of activatable applications
+// See: https://specifications.freedesktop.org/desktop-entry-spec/latest/dbus.html
+const applicationInterface = "org.freedesktop.Application"
+
+// usesDBus reports whether the entry is started over the session bus
+func (e *Entry) usesDBus() bool {
+	if e.Parent != nil {
+		return e.Parent.DBusActivatable
+	}
+	return e.DBusActivatable
+}
+
+// launchDBus activates the application through org.freedesktop.Application.
+// Files and URIs are passed to Open, actions go through ActivateAction.
+func (e *Entry) launchDBus(files []string, uris []string) error {
+	app := e
+	if e.Parent != nil {
+		app = e.Parent
+	}
+
+	busName := dbusName(app)
+	args := []string{
+		"call", "--session",
+		"--dest", busName,
+		"--object-path", dbusObjectPath(busName),
+	}
+
+	targets := asURIs(files, uris)
+	switch {
+	case e.Parent != nil:
+		actionID := strings.TrimPrefix(e.Path, app.Path+"#")
+		args = append(args, "--method", applicationInterface+".ActivateAction",
+			gvariantString(actionID), "[]", "{}")
+	case len(targets) > 0:
+		args = append(args, "--method", applicationInterface+".Open",
+			gvariantStringArray(targets), "{}")
+	default:
+		args = append(args, "--method", applicationInterface+".Activate", "{}")
+	}
+
+	output, err := exec.Command("gdbus", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v: %s", ErrLaunchFailed, e.Name, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// dbusName returns the well-known bus name of an application, which is its
+// desktop file ID without the .desktop suffix
+func dbusName(e *Entry) string {
+	id := e.ID
+	if id == "" {
+		id = filepath.Base(e.Path)
+	}
+	return strings.TrimSuffix(id, ".desktop")
+}
+
+// dbusObjectPath derives the object path from a bus name,
+// e.g. org.gnome.Nautilus becomes /org/gnome/Nautilus
+func dbusObjectPath(busName string) string {
+	return "/" + strings.NewReplacer(".", "/", "-", "_").Replace(busName)
+}
+
+// gvariantString formats a string in GVariant text format
+func gvariantString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+}
+
+// gvariantStringArray formats a string array in GVariant text format
+func gvariantStringArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = gvariantString(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -13,10 +13,70 @@ type Entry struct {
 	Comment     string
 	Exec        string
 	Icon        string
+	Image       string // Artwork file path or URL shown instead of the icon
 	Terminal    bool
 	Categories  []string
+	Keywords    []string
 	Path        string // Path to .desktop file or unique identifier
+	ID          string // Desktop file ID, empty for game launcher entries
 	LastUsed    time.Time
+	Actions     []Action
+	Parent      *Entry // Entry this action belongs to, nil for applications
+
+	// Metadata holds optional facts about the entry, such as the playtime
+	// of games, keyed by the Meta* constants
+	Metadata map[string]string
+
+	// DBusActivatable entries are started through org.freedesktop.Application
+	DBusActivatable bool
+}
+
+// Action represents an additional way to launch an application,
+// declared in a [Desktop Action <ID>] group
+type Action struct {
+	ID   string
+	Name string
+	Exec string
+	Icon string
+}
+
+// ActionSeparator separates the application name from the action name
+const ActionSeparator = " › "
+
+// ActionEntries returns an entry for each desktop action of the application.
+// Action entries inherit terminal handling, categories, the desktop file ID
+// and D-Bus activation from their parent.
+func (e *Entry) ActionEntries() []*Entry {
+	if len(e.Actions) == 0 {
+		return nil
+	}
+
+	entries := make([]*Entry, 0, len(e.Actions))
+	for _, action := range e.Actions {
+		icon := action.Icon
+		if icon == "" {
+			icon = e.Icon
+		}
+
+		entries = append(entries, &Entry{
+			Name:            e.Name + ActionSeparator + action.Name,
+			Comment:         e.Comment,
+			Exec:            action.Exec,
+			Icon:            icon,
+			Terminal:        e.Terminal,
+			Categories:      e.Categories,
+			Path:            e.Path + "#" + action.ID,
+			ID:              e.ID,
+			Parent:          e,
+			DBusActivatable: e.DBusActivatable,
+		})
+	}
+	return entries
+}
+
+// IsAction reports whether the entry is a desktop action of another entry
+func (e *Entry) IsAction() bool {
+	return e.Parent != nil
 }
 
 // GetAppType determines the type/source of the application based on its path
@@ -26,6 +86,11 @@ func (e *Entry) GetAppType() AppType {
 		return AppTypeFlatpak
 	}
 
+	// Check if it runs in a Wine prefix
+	if e.isWineApp() {
+		return AppTypeWine
+	}
+
 	// Check if it's from Nix
 	if e.isNixApp() {
 		return e.getNixAppType()
@@ -53,6 +118,14 @@ func (e *Entry) isNixApp() bool {
 		strings.Contains(e.Exec, "/nix/store")
 }
 
+// isWineApp checks if the application is a Windows program, either added to
+// the menu by Wine itself or run through Bottles
+func (e *Entry) isWineApp() bool {
+	return strings.Contains(e.Path, "/applications/wine/") ||
+		strings.Contains(e.Exec, "WINEPREFIX=") ||
+		strings.Contains(e.Exec, "bottles-cli")
+}
+
 // getNixAppType distinguishes between system and home-manager Nix apps
 func (e *Entry) getNixAppType() AppType {
 	homeDir := os.Getenv("HOME")
@@ -107,16 +180,44 @@ func (e *Entry) Clone() *Entry {
 	categories := make([]string, len(e.Categories))
 	copy(categories, e.Categories)
 
+	var keywords []string
+	if e.Keywords != nil {
+		keywords = make([]string, len(e.Keywords))
+		copy(keywords, e.Keywords)
+	}
+
+	var actions []Action
+	if e.Actions != nil {
+		actions = make([]Action, len(e.Actions))
+		copy(actions, e.Actions)
+	}
+
+	var metadata map[string]string
+	if e.Metadata != nil {
+		metadata = make(map[string]string, len(e.Metadata))
+		for key, value := range e.Metadata {
+			metadata[key] = value
+		}
+	}
+
 	return &Entry{
 		Name:        e.Name,
 		GenericName: e.GenericName,
 		Comment:     e.Comment,
 		Exec:        e.Exec,
 		Icon:        e.Icon,
+		Image:       e.Image,
 		Terminal:    e.Terminal,
 		Categories:  categories,
+		Keywords:    keywords,
 		Path:        e.Path,
+		ID:          e.ID,
 		LastUsed:    e.LastUsed,
+		Actions:     actions,
+		Parent:      e.Parent,
+		Metadata:    metadata,
+
+		DBusActivatable: e.DBusActivatable,
 	}
 }
 
@@ -125,7 +226,7 @@ func (e *Entry) Validate() error {
 	if e.Name == "" {
 		return ErrMissingName
 	}
-	if e.Exec == "" {
+	if e.Exec == "" && !e.DBusActivatable {
 		return ErrMissingExec
 	}
 	return nil
@@ -1,8 +1,11 @@
 package entry
 
 import (
+	"errors"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestGetAppType(t *testing.T) {
@@ -52,6 +55,25 @@ func TestGetAppType(t *testing.T) {
 			},
 			expected: AppTypeGame,
 		},
+		{
+			name: "Wine program",
+			entry: &Entry{
+				Name: "Notepad++",
+				Path: "/home/user/.local/share/applications/wine/Programs/Notepad++/Notepad++.desktop",
+				Exec: `env WINEPREFIX="/home/user/.wine" wine C:\\\\windows\\\\command\\\\start.exe /Unix notepad++.lnk`,
+			},
+			expected: AppTypeWine,
+		},
+		{
+			name: "Bottles program",
+			entry: &Entry{
+				Name:       "Battle.net",
+				Path:       "bottles-Gaming-battlenet",
+				Exec:       "bottles-cli run -b Gaming -p Battle.net",
+				Categories: []string{"Game"},
+			},
+			expected: AppTypeWine,
+		},
 		{
 			name: "Other application",
 			entry: &Entry{
@@ -100,6 +122,14 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: ErrMissingExec,
 		},
+		{
+			name: "D-Bus activatable without exec",
+			entry: &Entry{
+				Name:            "Files",
+				DBusActivatable: true,
+			},
+			wantErr: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -122,6 +152,7 @@ func TestClone(t *testing.T) {
 		Terminal:    false,
 		Categories:  []string{"Network", "WebBrowser"},
 		Path:        "/usr/share/applications/firefox.desktop",
+		Metadata:    map[string]string{MetaStore: "steam"},
 	}
 
 	cloned := original.Clone()
@@ -136,95 +167,316 @@ func TestClone(t *testing.T) {
 	if original.Categories[0] == "Modified" {
 		t.Error("Clone() did not create a deep copy of Categories")
 	}
+
+	cloned.SetMeta(MetaStore, "gog")
+	if original.Meta(MetaStore) != "steam" {
+		t.Error("Clone() did not create a deep copy of Metadata")
+	}
+}
+
+func TestActionEntries(t *testing.T) {
+	app := &Entry{
+		Name:     "Firefox",
+		Exec:     "firefox %u",
+		Icon:     "firefox",
+		Terminal: true,
+		Path:     "/usr/share/applications/firefox.desktop",
+		Actions: []Action{
+			{ID: "new-window", Name: "New Window", Exec: "firefox --new-window"},
+			{ID: "private", Name: "Private", Exec: "firefox --private-window", Icon: "private"},
+		},
+	}
+
+	actions := app.ActionEntries()
+	if len(actions) != 2 {
+		t.Fatalf("ActionEntries() length = %d, want 2", len(actions))
+	}
+
+	first := actions[0]
+	if first.Name != "Firefox › New Window" {
+		t.Errorf("Name = %q, want %q", first.Name, "Firefox › New Window")
+	}
+	if first.Path != app.Path+"#new-window" {
+		t.Errorf("Path = %q, want %q", first.Path, app.Path+"#new-window")
+	}
+	if first.Icon != "firefox" || actions[1].Icon != "private" {
+		t.Errorf("Icons = %q, %q, want firefox, private", first.Icon, actions[1].Icon)
+	}
+	if !first.Terminal || !first.IsAction() || first.Parent != app {
+		t.Error("Action entry should inherit terminal handling and point to its parent")
+	}
+
+	// Field codes of actions refer to the application
+	ctx := first.execContext(nil, nil)
+	if ctx.name != "Firefox" || ctx.location != app.Path {
+		t.Errorf("execContext() = %+v, want application name and location", ctx)
+	}
+
+	// Actions of D-Bus activatable applications may have no Exec
+	files := &Entry{
+		Name:            "Files",
+		ID:              "org.gnome.Nautilus.desktop",
+		Path:            "/usr/share/applications/org.gnome.Nautilus.desktop",
+		DBusActivatable: true,
+		Actions:         []Action{{ID: "new-window", Name: "New Window"}},
+	}
+	action := files.ActionEntries()[0]
+	if action.ID != files.ID || !action.DBusActivatable {
+		t.Errorf("ActionEntries() = %+v, want the ID and D-Bus activation of Files", action)
+	}
+	if err := action.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
 }
 
-func TestParseExecString(t *testing.T) {
+func TestExpandExec(t *testing.T) {
+	ctx := execContext{
+		name:     "Firefox",
+		icon:     "firefox",
+		location: "/usr/share/applications/firefox.desktop",
+	}
+
 	tests := []struct {
 		name     string
 		exec     string
-		expected string
+		files    []string
+		uris     []string
+		expected [][]string
 	}{
 		{
 			name:     "Simple command",
 			exec:     "firefox",
-			expected: "firefox",
+			expected: [][]string{{"firefox"}},
 		},
 		{
-			name:     "Command with field codes",
+			name:     "Field codes without targets are removed",
 			exec:     "firefox %u",
-			expected: "firefox",
+			expected: [][]string{{"firefox"}},
 		},
 		{
-			name:     "Command with multiple field codes",
-			exec:     "gimp %f %F",
-			expected: "gimp",
+			name:     "Command with extra spaces",
+			exec:     "firefox  %U   --new-window",
+			expected: [][]string{{"firefox", "--new-window"}},
 		},
 		{
-			name:     "Command with extra spaces",
-			exec:     "firefox  %u   %U",
-			expected: "firefox",
+			name:     "Single file expands once per invocation",
+			exec:     "gimp %f",
+			files:    []string{"/tmp/a.png", "/tmp/b.png"},
+			expected: [][]string{{"gimp", "/tmp/a.png"}, {"gimp", "/tmp/b.png"}},
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := parseExecString(tt.exec)
-			if result != tt.expected {
-				t.Errorf("parseExecString() = %v, want %v", result, tt.expected)
-			}
-		})
-	}
-}
-
-func TestSplitExecString(t *testing.T) {
-	tests := []struct {
-		name     string
-		exec     string
-		expected []string
-	}{
 		{
-			name:     "Simple command",
-			exec:     "firefox",
-			expected: []string{"firefox"},
+			name:     "File list expands to separate arguments",
+			exec:     "gimp %F",
+			files:    []string{"/tmp/a.png", "/tmp/with space.png"},
+			expected: [][]string{{"gimp", "/tmp/a.png", "/tmp/with space.png"}},
 		},
 		{
-			name:     "Command with arguments",
-			exec:     "firefox --new-window",
-			expected: []string{"firefox", "--new-window"},
+			name:     "URL list accepts files",
+			exec:     "firefox %U",
+			files:    []string{"/tmp/page.html"},
+			uris:     []string{"https://example.com"},
+			expected: [][]string{{"firefox", "file:///tmp/page.html", "https://example.com"}},
 		},
 		{
-			name:     "Command with quoted argument",
+			name:     "File code converts file URIs and drops others",
+			exec:     "gimp %F",
+			uris:     []string{"file:///tmp/a.png", "https://example.com/b.png"},
+			expected: [][]string{{"gimp", "/tmp/a.png"}},
+		},
+		{
+			name:     "Embedded single file code",
+			exec:     "app --open=%f",
+			files:    []string{"/tmp/a.txt"},
+			expected: [][]string{{"app", "--open=/tmp/a.txt"}},
+		},
+		{
+			name:     "Icon, name and location",
+			exec:     "firefox %i --class %c --desktop %k",
+			expected: [][]string{{"firefox", "--icon", "firefox", "--class", "Firefox", "--desktop", "/usr/share/applications/firefox.desktop"}},
+		},
+		{
+			name:     "Deprecated field codes are removed",
+			exec:     "app %d %D %n %N %v %m",
+			expected: [][]string{{"app"}},
+		},
+		{
+			name:     "Literal percent",
+			exec:     `printf 100%% "50%%"`,
+			expected: [][]string{{"printf", "100%", "50%"}},
+		},
+		{
+			name:     "Double quoted argument",
 			exec:     `firefox "https://example.com"`,
-			expected: []string{"firefox", "https://example.com"},
+			expected: [][]string{{"firefox", "https://example.com"}},
 		},
 		{
-			name:     "Command with single quotes",
+			name:     "Escapes inside double quotes",
+			exec:     `sh -c "echo \"\$HOME\" \\"`,
+			expected: [][]string{{"sh", "-c", `echo "$HOME" \`}},
+		},
+		{
+			name:     "Empty quoted argument is kept",
+			exec:     `app ""`,
+			expected: [][]string{{"app", ""}},
+		},
+		{
+			name:     "Single quotes",
 			exec:     `echo 'hello world'`,
-			expected: []string{"echo", "hello world"},
+			expected: [][]string{{"echo", "hello world"}},
 		},
 		{
-			name:     "Command with escaped characters",
+			name:     "Escaped characters",
 			exec:     `echo hello\ world`,
-			expected: []string{"echo", "hello world"},
+			expected: [][]string{{"echo", "hello world"}},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := splitExecString(tt.exec)
-			if len(result) != len(tt.expected) {
-				t.Errorf("splitExecString() length = %v, want %v", len(result), len(tt.expected))
-				return
+			c := ctx
+			c.files = tt.files
+			c.uris = tt.uris
+
+			result, err := expandExec(tt.exec, c)
+			if err != nil {
+				t.Fatalf("expandExec(%q) error = %v", tt.exec, err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expandExec(%q) = %q, want %q", tt.exec, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandExecIconMissing(t *testing.T) {
+	result, err := expandExec("app %i", execContext{})
+	if err != nil {
+		t.Fatalf("expandExec() error = %v", err)
+	}
+	if !reflect.DeepEqual(result, [][]string{{"app"}}) {
+		t.Errorf("expandExec() = %q, want [[app]]", result)
+	}
+}
+
+func TestExpandExecInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		exec string
+	}{
+		{"Unterminated double quote", `app "file`},
+		{"Unterminated single quote", `app 'file`},
+		{"Dangling escape", `app \`},
+		{"Unknown field code", "app %x"},
+		{"Incomplete field code", "app 100%"},
+		{"File list not standalone", "app --files=%F"},
+		{"Field code inside quotes", `app "%f"`},
+		{"Multiple target codes", "app %f %U"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := expandExec(tt.exec, execContext{})
+			if !errors.Is(err, ErrInvalidExec) {
+				t.Fatalf("expandExec(%q) error = %v, want ErrInvalidExec", tt.exec, err)
 			}
-			for i := range result {
-				if result[i] != tt.expected[i] {
-					t.Errorf("splitExecString()[%d] = %v, want %v", i, result[i], tt.expected[i])
-				}
+
+			var execErr *ExecError
+			if !errors.As(err, &execErr) || execErr.Exec != tt.exec {
+				t.Errorf("expandExec(%q) error = %#v, want *ExecError", tt.exec, err)
 			}
 		})
 	}
 }
 
+func TestExpandExecEmpty(t *testing.T) {
+	if _, err := expandExec("   ", execContext{}); err != ErrEmptyExec {
+		t.Errorf("expandExec() error = %v, want %v", err, ErrEmptyExec)
+	}
+}
+
+func TestParseExecWithEnv(t *testing.T) {
+	envVars, cmdParts := parseExecWithEnv([]string{"GDK_BACKEND=x11", "app", "--opt", "KEY=value"})
+
+	if envVars["GDK_BACKEND"] != "x11" || len(envVars) != 1 {
+		t.Errorf("parseExecWithEnv() env = %v, want only GDK_BACKEND=x11", envVars)
+	}
+
+	expected := []string{"app", "--opt", "KEY=value"}
+	if !reflect.DeepEqual(cmdParts, expected) {
+		t.Errorf("parseExecWithEnv() cmd = %v, want %v", cmdParts, expected)
+	}
+}
+
+func TestDBusNames(t *testing.T) {
+	busName := dbusName(&Entry{Path: "/usr/share/applications/org.gnome.Nautilus.desktop"})
+	if busName != "org.gnome.Nautilus" {
+		t.Errorf("dbusName() = %q, want %q", busName, "org.gnome.Nautilus")
+	}
+
+	busName = dbusName(&Entry{Path: "/usr/share/applications/kde/org.kde.dolphin.desktop", ID: "kde-org.kde.dolphin.desktop"})
+	if busName != "kde-org.kde.dolphin" {
+		t.Errorf("dbusName() = %q, want %q", busName, "kde-org.kde.dolphin")
+	}
+
+	if path := dbusObjectPath("org.example.my-app"); path != "/org/example/my_app" {
+		t.Errorf("dbusObjectPath() = %q, want %q", path, "/org/example/my_app")
+	}
+
+	array := gvariantStringArray([]string{"file:///tmp/a", "it's"})
+	if array != `['file:///tmp/a', 'it\'s']` {
+		t.Errorf("gvariantStringArray() = %q", array)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"firefox", "firefox"},
+		{"", "''"},
+		{"/tmp/with space", "'/tmp/with space'"},
+		{"it's", `'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		if result := shellQuote(tt.input); result != tt.expected {
+			t.Errorf("shellQuote(%q) = %v, want %v", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestQuoteExecArg(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"firefox", "firefox"},
+		{"", `""`},
+		{"My Bottle", `"My Bottle"`},
+		{`say "hi" for $5 at 100%`, `"say \"hi\" for \$5 at 100%%"`},
+		{`C:\Games`, `"C:\\Games"`},
+	}
+
+	ctx := execContext{name: "Test", icon: "test"}
+	for _, tt := range tests {
+		result := QuoteExecArg(tt.input)
+		if result != tt.expected {
+			t.Errorf("QuoteExecArg(%q) = %v, want %v", tt.input, result, tt.expected)
+		}
+
+		// The quoted argument must survive splitting and expansion
+		invocations, err := expandExec("program "+result+" %f", ctx)
+		if err != nil {
+			t.Errorf("expandExec(%q) error = %v", result, err)
+			continue
+		}
+		if want := [][]string{{"program", tt.input}}; !reflect.DeepEqual(invocations, want) {
+			t.Errorf("expandExec(%q) = %q, want %q", result, invocations, want)
+		}
+	}
+}
+
 func TestIsValidEnvVarName(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -251,3 +503,58 @@ func TestIsValidEnvVarName(t *testing.T) {
 		})
 	}
 }
+
+func TestGameMetadata(t *testing.T) {
+	e := &Entry{Name: "Celeste"}
+	if !e.LastPlayed().IsZero() || e.Playtime() != 0 {
+		t.Fatalf("LastPlayed() = %v, Playtime() = %v, want none", e.LastPlayed(), e.Playtime())
+	}
+
+	// Games never played are recorded with a zero time or no playtime
+	e.SetLastPlayed(time.Unix(0, 0))
+	e.SetPlaytime(20 * time.Second)
+	if e.Metadata != nil {
+		t.Errorf("Metadata = %v, want nil", e.Metadata)
+	}
+
+	played := time.Date(2024, 3, 1, 20, 30, 0, 0, time.FixedZone("CET", 3600))
+	e.SetLastPlayed(played)
+	e.SetPlaytime(90*time.Minute + 40*time.Second)
+
+	if e.Meta(MetaLastPlayed) != "2024-03-01T19:30:00Z" {
+		t.Errorf("Meta(MetaLastPlayed) = %q", e.Meta(MetaLastPlayed))
+	}
+	if !e.LastPlayed().Equal(played) {
+		t.Errorf("LastPlayed() = %v, want %v", e.LastPlayed(), played)
+	}
+	if e.Meta(MetaPlaytime) != "91" || e.Playtime() != 91*time.Minute {
+		t.Errorf("Playtime() = %v (%q), want 1h31m", e.Playtime(), e.Meta(MetaPlaytime))
+	}
+}
+
+func TestSortByLastPlayed(t *testing.T) {
+	game := func(name string, daysAgo int) *Entry {
+		e := &Entry{Name: name}
+		if daysAgo >= 0 {
+			e.SetLastPlayed(time.Now().AddDate(0, 0, -daysAgo))
+		}
+		return e
+	}
+	entries := []*Entry{
+		game("Never A", -1),
+		game("Last Month", 30),
+		game("Never B", -1),
+		game("Yesterday", 1),
+	}
+
+	SortByLastPlayed(entries)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	want := []string{"Yesterday", "Last Month", "Never A", "Never B"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("SortByLastPlayed() = %v, want %v", names, want)
+	}
+}
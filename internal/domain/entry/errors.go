@@ -1,6 +1,9 @@
 package entry
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrMissingName indicates the entry has no name
@@ -17,4 +20,24 @@ var (
 
 	// ErrEmptyExec indicates the exec command is empty after parsing
 	ErrEmptyExec = errors.New("entry: empty exec command after parsing")
+
+	// ErrInvalidExec indicates the exec command violates the desktop entry spec
+	ErrInvalidExec = errors.New("entry: invalid exec command")
 )
+
+// ExecError describes why an Exec key could not be parsed.
+// It matches ErrInvalidExec with errors.Is.
+type ExecError struct {
+	Exec   string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *ExecError) Error() string {
+	return fmt.Sprintf("%v: %q: %s", ErrInvalidExec, e.Exec, e.Reason)
+}
+
+// Unwrap returns ErrInvalidExec
+func (e *ExecError) Unwrap() error {
+	return ErrInvalidExec
+}
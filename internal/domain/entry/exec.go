@@ -0,0 +1,324 @@
+package entry
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// execSegment is a run of characters of an Exec argument that was either
+// inside or outside of quotes
+type execSegment struct {
+	text   string
+	quoted bool
+}
+
+// execArg is a single Exec argument after quote removal
+type execArg []execSegment
+
+// isCode reports whether the argument is exactly the unquoted field code
+func (a execArg) isCode(code string) bool {
+	return len(a) == 1 && !a[0].quoted && a[0].text == code
+}
+
+// execContext holds the values field codes expand to
+type execContext struct {
+	name     string
+	icon     string
+	location string
+	files    []string
+	uris     []string
+}
+
+// expandExec expands the field codes of an Exec key and returns one argument
+// vector per invocation. %f and %u launch the program once per target, every
+// other form produces a single invocation.
+// See: https://specifications.freedesktop.org/desktop-entry-spec/latest/exec-variables.html
+func expandExec(exec string, ctx execContext) ([][]string, error) {
+	args, err := splitExec(exec)
+	if err != nil {
+		return nil, err
+	}
+
+	targetCode, err := validateFieldCodes(exec, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	switch targetCode {
+	case 'f', 'F':
+		targets = asFiles(ctx.files, ctx.uris)
+	case 'u', 'U':
+		targets = asURIs(ctx.files, ctx.uris)
+	}
+
+	// %f and %u take a single target, so run once per target
+	if (targetCode == 'f' || targetCode == 'u') && len(targets) > 1 {
+		invocations := make([][]string, 0, len(targets))
+		for _, target := range targets {
+			invocations = append(invocations, expandArgs(args, ctx, []string{target}))
+		}
+		return invocations, nil
+	}
+
+	return [][]string{expandArgs(args, ctx, targets)}, nil
+}
+
+// splitExec splits an Exec value into arguments, applying the quoting rules
+// of the specification. Single quotes and backslash escapes outside of quotes
+// are accepted as well, as many desktop files in the wild rely on them.
+func splitExec(exec string) ([]execArg, error) {
+	var args []execArg
+	var current execArg
+	var segment strings.Builder
+	inArg := false
+
+	flush := func(quoted bool) {
+		if segment.Len() > 0 || quoted {
+			current = append(current, execSegment{text: segment.String(), quoted: quoted})
+			segment.Reset()
+		}
+	}
+
+	runes := []rune(exec)
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+
+		switch {
+		case char == ' ' || char == '\t' || char == '\n':
+			// Whitespace outside quotes - separator
+			if inArg {
+				flush(false)
+				args = append(args, current)
+				current = nil
+				inArg = false
+			}
+
+		case char == '"':
+			flush(false)
+			inArg = true
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"`$\\", runes[i+1]) {
+					i++
+				}
+				segment.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, &ExecError{Exec: exec, Reason: "unterminated double quote"}
+			}
+			flush(true)
+
+		case char == '\'':
+			flush(false)
+			inArg = true
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					closed = true
+					break
+				}
+				segment.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, &ExecError{Exec: exec, Reason: "unterminated single quote"}
+			}
+			flush(true)
+
+		case char == '\\':
+			if i+1 >= len(runes) {
+				return nil, &ExecError{Exec: exec, Reason: "dangling escape character"}
+			}
+			// Escape sequence
+			i++
+			inArg = true
+			segment.WriteRune(runes[i])
+
+		default:
+			inArg = true
+			segment.WriteRune(char)
+		}
+	}
+
+	// Add the last argument
+	if inArg {
+		flush(false)
+		args = append(args, current)
+	}
+
+	if len(args) == 0 {
+		return nil, ErrEmptyExec
+	}
+
+	return args, nil
+}
+
+// QuoteExecArg quotes an argument for use in an Exec value so it is passed
+// verbatim, without splitting or field code expansion
+func QuoteExecArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'`$\\%<>~|&;*?#()") {
+		return arg
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range arg {
+		switch r {
+		case '"', '`', '$', '\\':
+			b.WriteByte('\\')
+		case '%':
+			b.WriteByte('%')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// validateFieldCodes checks every field code of the parsed arguments and
+// returns the file or URL code in use (f, F, u, U), or 0 if there is none
+func validateFieldCodes(exec string, args []execArg) (rune, error) {
+	var targetCode rune
+
+	for _, arg := range args {
+		for _, seg := range arg {
+			runes := []rune(seg.text)
+			for i := 0; i < len(runes); i++ {
+				if runes[i] != '%' {
+					continue
+				}
+				if i+1 >= len(runes) {
+					return 0, &ExecError{Exec: exec, Reason: "incomplete field code"}
+				}
+				i++
+				code := runes[i]
+				if code == '%' {
+					continue
+				}
+				if seg.quoted {
+					return 0, &ExecError{Exec: exec, Reason: "field code %" + string(code) + " inside quoted argument"}
+				}
+
+				switch code {
+				case 'f', 'u':
+				case 'F', 'U':
+					if !arg.isCode("%" + string(code)) {
+						return 0, &ExecError{Exec: exec, Reason: "%" + string(code) + " must be used as an argument on its own"}
+					}
+				case 'i', 'c', 'k':
+					continue
+				case 'd', 'D', 'n', 'N', 'v', 'm':
+					continue // deprecated, removed on expansion
+				default:
+					return 0, &ExecError{Exec: exec, Reason: "unknown field code %" + string(code)}
+				}
+
+				if targetCode != 0 && targetCode != code {
+					return 0, &ExecError{Exec: exec, Reason: "more than one of %f, %F, %u, %U"}
+				}
+				targetCode = code
+			}
+		}
+	}
+
+	return targetCode, nil
+}
+
+// expandArgs builds a single argument vector, replacing file and URL codes
+// with the given targets
+func expandArgs(args []execArg, ctx execContext, targets []string) []string {
+	result := make([]string, 0, len(args)+len(targets))
+
+	for _, arg := range args {
+		switch {
+		case arg.isCode("%F"), arg.isCode("%U"):
+			result = append(result, targets...)
+			continue
+		case arg.isCode("%i"):
+			if ctx.icon != "" {
+				result = append(result, "--icon", ctx.icon)
+			}
+			continue
+		}
+
+		var value strings.Builder
+		hasQuoted := false
+		for _, seg := range arg {
+			if seg.quoted {
+				hasQuoted = true
+				value.WriteString(strings.ReplaceAll(seg.text, "%%", "%"))
+				continue
+			}
+			value.WriteString(expandSegment(seg.text, ctx, targets))
+		}
+
+		// Arguments made only of field codes vanish when they expand to nothing
+		if value.Len() == 0 && !hasQuoted {
+			continue
+		}
+		result = append(result, value.String())
+	}
+
+	return result
+}
+
+// expandSegment replaces the field codes of an unquoted segment
+func expandSegment(text string, ctx execContext, targets []string) string {
+	var b strings.Builder
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' || i+1 >= len(runes) {
+			b.WriteRune(runes[i])
+			continue
+		}
+		i++
+		switch runes[i] {
+		case '%':
+			b.WriteRune('%')
+		case 'f', 'u':
+			if len(targets) > 0 {
+				b.WriteString(targets[0])
+			}
+		case 'i':
+			b.WriteString(ctx.icon)
+		case 'c':
+			b.WriteString(ctx.name)
+		case 'k':
+			b.WriteString(ctx.location)
+		}
+	}
+
+	return b.String()
+}
+
+// asFiles returns local file names for files and file:// URIs.
+// URIs with any other scheme cannot be passed as a file and are dropped.
+func asFiles(files, uris []string) []string {
+	result := make([]string, 0, len(files)+len(uris))
+	result = append(result, files...)
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		if err != nil || u.Scheme != "file" {
+			continue
+		}
+		result = append(result, u.Path)
+	}
+	return result
+}
+
+// asURIs returns URIs for URIs and local files
+func asURIs(files, uris []string) []string {
+	result := make([]string, 0, len(files)+len(uris))
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+		result = append(result, (&url.URL{Scheme: "file", Path: file}).String())
+	}
+	return append(result, uris...)
+}
@@ -9,12 +9,65 @@ import (
 
 // Launch executes the application
 func (e *Entry) Launch() error {
+	return e.LaunchWithArgs(nil, nil)
+}
+
+// LaunchWithArgs executes the application with the given files and URIs,
+// expanding the field codes of its Exec key. Programs taking a single file or
+// URL (%f, %u) are started once per target.
+func (e *Entry) LaunchWithArgs(files []string, uris []string) error {
 	if err := e.Validate(); err != nil {
 		return err
 	}
 
-	cmdString := parseExecString(e.Exec)
-	envVars, cmdParts := parseExecWithEnv(cmdString)
+	// Prefer D-Bus activation, falling back to Exec when the bus call fails
+	if e.usesDBus() {
+		err := e.launchDBus(files, uris)
+		if err == nil || e.Exec == "" {
+			return err
+		}
+	}
+
+	invocations, err := expandExec(e.Exec, e.execContext(files, uris))
+	if err != nil {
+		return err
+	}
+
+	for _, args := range invocations {
+		if err := e.start(args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// execContext returns the values field codes of this entry expand to
+func (e *Entry) execContext(files []string, uris []string) execContext {
+	// Actions expand %c and %k to the values of the application
+	app := e
+	if e.Parent != nil {
+		app = e.Parent
+	}
+
+	ctx := execContext{
+		name:  app.Name,
+		icon:  e.Icon,
+		files: files,
+		uris:  uris,
+	}
+
+	// Game launcher entries use an identifier instead of a file path
+	if strings.HasSuffix(app.Path, ".desktop") {
+		ctx.location = app.Path
+	}
+
+	return ctx
+}
+
+// start runs a single invocation of the application
+func (e *Entry) start(args []string) error {
+	envVars, cmdParts := parseExecWithEnv(args)
 
 	if len(cmdParts) == 0 {
 		return ErrEmptyExec
@@ -107,53 +160,26 @@ func findTerminal() string {
 	return ""
 }
 
-// parseExecString handles desktop entry field codes
-// See: https://specifications.freedesktop.org/desktop-entry-spec/latest/ar01s07.html
-func parseExecString(exec string) string {
-	// Remove field codes that we don't handle
-	replacements := map[string]string{
-		"%f": "", "%F": "",
-		"%u": "", "%U": "",
-		"%i": "", "%c": "", "%k": "",
-		"%d": "", "%D": "", // deprecated
-		"%n": "", "%N": "", // deprecated
-		"%v": "", "%m": "", // deprecated
-	}
-
-	result := exec
-	for old, new := range replacements {
-		result = strings.ReplaceAll(result, old, new)
-	}
-
-	// Clean up extra spaces
-	result = strings.TrimSpace(result)
-	fields := strings.Fields(result)
-	return strings.Join(fields, " ")
-}
-
-// parseExecWithEnv parses a command string into environment variables and command parts
-func parseExecWithEnv(exec string) (map[string]string, []string) {
+// parseExecWithEnv separates leading environment variable assignments
+// (KEY=VALUE) from the command and its arguments
+func parseExecWithEnv(args []string) (map[string]string, []string) {
 	envVars := make(map[string]string)
-	var cmdParts []string
 
-	parts := splitExecString(exec)
-
-	for _, part := range parts {
+	for i, arg := range args {
 		// Check if this part is an environment variable (KEY=VALUE)
-		if idx := strings.Index(part, "="); idx > 0 && !strings.Contains(part[:idx], " ") {
-			key := part[:idx]
-			value := part[idx+1:]
+		if idx := strings.Index(arg, "="); idx > 0 {
+			key := arg[:idx]
 			// Only treat as env var if key looks like a valid env var name
 			if isValidEnvVarName(key) {
-				envVars[key] = value
+				envVars[key] = arg[idx+1:]
 				continue
 			}
 		}
-		// Not an env var, it's part of the command
-		cmdParts = append(cmdParts, part)
+		// Not an env var, the rest is the command
+		return envVars, args[i:]
 	}
 
-	return envVars, cmdParts
+	return envVars, nil
 }
 
 // isValidEnvVarName checks if a string is a valid environment variable name
@@ -177,65 +203,30 @@ func isValidEnvVarName(name string) bool {
 	return true
 }
 
-// reconstructCommand rebuilds a command string from env vars and parts
+// reconstructCommand rebuilds a shell command string from env vars and parts
 func reconstructCommand(envVars map[string]string, cmdParts []string) string {
 	var parts []string
 
 	// Add env vars
 	for key, value := range envVars {
-		parts = append(parts, fmt.Sprintf("%s=%s", key, value))
+		parts = append(parts, key+"="+shellQuote(value))
 	}
 
 	// Add command parts
-	parts = append(parts, cmdParts...)
+	for _, part := range cmdParts {
+		parts = append(parts, shellQuote(part))
+	}
 
 	return strings.Join(parts, " ")
 }
 
-// splitExecString splits the exec string into command and arguments
-// This handles quoted arguments properly
-func splitExecString(exec string) []string {
-	var parts []string
-	var current strings.Builder
-	inQuote := false
-	quoteChar := rune(0)
-
-	runes := []rune(exec)
-	for i := 0; i < len(runes); i++ {
-		char := runes[i]
-
-		switch {
-		case (char == '"' || char == '\'') && !inQuote:
-			// Start of quote
-			inQuote = true
-			quoteChar = char
-
-		case char == quoteChar && inQuote:
-			// End of quote
-			inQuote = false
-			quoteChar = 0
-
-		case char == ' ' && !inQuote:
-			// Space outside quotes - separator
-			if current.Len() > 0 {
-				parts = append(parts, current.String())
-				current.Reset()
-			}
-
-		case char == '\\' && i+1 < len(runes):
-			// Escape sequence
-			i++
-			current.WriteRune(runes[i])
-
-		default:
-			current.WriteRune(char)
-		}
+// shellQuote quotes an argument for sh when it contains special characters
+func shellQuote(arg string) string {
+	if arg == "" {
+		return "''"
 	}
-
-	// Add the last part
-	if current.Len() > 0 {
-		parts = append(parts, current.String())
+	if !strings.ContainsAny(arg, " \t\n\"'`$\\|&;<>()*?[]#~!{}") {
+		return arg
 	}
-
-	return parts
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
 }
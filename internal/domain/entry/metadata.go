@@ -0,0 +1,82 @@
+package entry
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Metadata keys. Game launchers fill those their client records.
+const (
+	MetaLastPlayed = "last_played" // RFC 3339 time the game was last played
+	MetaPlaytime   = "playtime"    // Total time played, in whole minutes
+	MetaStore      = "store"       // Store the game comes from, such as steam or gog
+	MetaRunner     = "runner"      // What runs the game, such as wine or legendary
+)
+
+// Meta returns a metadata value, or an empty string if it is unset
+func (e *Entry) Meta(key string) string {
+	return e.Metadata[key]
+}
+
+// SetMeta sets a metadata value. Empty values are left unset.
+func (e *Entry) SetMeta(key, value string) {
+	if value == "" {
+		return
+	}
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]string)
+	}
+	e.Metadata[key] = value
+}
+
+// LastPlayed returns when the entry was last played, or the zero time if
+// it never was or isn't a game
+func (e *Entry) LastPlayed() time.Time {
+	t, err := time.Parse(time.RFC3339, e.Meta(MetaLastPlayed))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// SetLastPlayed records when the entry was last played. Zero times, which
+// clients use for games never played, are ignored.
+func (e *Entry) SetLastPlayed(t time.Time) {
+	if t.IsZero() || t.Unix() <= 0 {
+		return
+	}
+	e.SetMeta(MetaLastPlayed, t.UTC().Format(time.RFC3339))
+}
+
+// Playtime returns the total time the entry was played
+func (e *Entry) Playtime() time.Duration {
+	minutes, err := strconv.ParseInt(e.Meta(MetaPlaytime), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// SetPlaytime records the total time the entry was played, rounded to the
+// minute. No playtime is ignored.
+func (e *Entry) SetPlaytime(d time.Duration) {
+	minutes := d.Round(time.Minute) / time.Minute
+	if minutes <= 0 {
+		return
+	}
+	e.SetMeta(MetaPlaytime, strconv.FormatInt(int64(minutes), 10))
+}
+
+// SortByLastPlayed sorts entries by when they were last played, most
+// recent first. Entries never played keep their order after the others.
+func SortByLastPlayed(entries []*Entry) {
+	lastPlayed := make(map[*Entry]time.Time, len(entries))
+	for _, e := range entries {
+		lastPlayed[e] = e.LastPlayed()
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return lastPlayed[entries[i]].After(lastPlayed[entries[j]])
+	})
+}
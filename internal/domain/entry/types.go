@@ -10,6 +10,7 @@ const (
 	AppTypeNixHome   AppType = "Nix-Home"
 	AppTypeFlatpak   AppType = "Flatpak"
 	AppTypeGame      AppType = "Games"
+	AppTypeWine      AppType = "Wine"
 	AppTypeOther     AppType = "Other"
 )
 
@@ -22,7 +23,7 @@ func (a AppType) String() string {
 func (a AppType) IsValid() bool {
 	switch a {
 	case AppTypeAll, AppTypeSystem, AppTypeNixSystem,
-		AppTypeNixHome, AppTypeFlatpak, AppTypeGame, AppTypeOther:
+		AppTypeNixHome, AppTypeFlatpak, AppTypeGame, AppTypeWine, AppTypeOther:
 		return true
 	}
 	return false
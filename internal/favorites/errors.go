@@ -0,0 +1,8 @@
+package favorites
+
+import "errors"
+
+var (
+	// ErrUnknownScorer indicates a scorer name no strategy goes by
+	ErrUnknownScorer = errors.New("favorites: unknown scorer")
+)
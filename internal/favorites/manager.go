@@ -3,19 +3,77 @@ package favorites
 import (
 	"path/filepath"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
 )
 
 // Manager handles favorite app tracking and scoring
 type Manager struct {
-	store   *Store
-	scorer  *Scorer
-	enabled bool
+	store     *Store
+	queries   *QueryStore
+	scorer    Scorer
+	threshold float64 // Score favorites reach, unless topN is set
+	topN      int     // How many of the highest scoring apps are favorites
+	enabled   bool
+
+	mu  sync.Mutex
+	top map[string]bool // Desktop files of the top N favorites, cached
+}
+
+// Option is a functional option for Manager
+type Option func(*Manager)
+
+// WithScorer sets how usage is scored, by age decay by default
+func WithScorer(scorer Scorer) Option {
+	return func(m *Manager) {
+		m.scorer = scorer
+	}
+}
+
+// WithDecayRate sets how fast what was launched for a query loses
+// importance, DecayLambda by default. Set it to the scorer's decay so
+// both age alike.
+func WithDecayRate(lambda float64) Option {
+	return func(m *Manager) {
+		m.queries.decayLambda = lambda
+	}
+}
+
+// WithThreshold sets the score apps become favorites at, FavoriteThreshold
+// by default
+func WithThreshold(score float64) Option {
+	return func(m *Manager) {
+		m.threshold = score
+	}
+}
+
+// WithTopN makes the n highest scoring apps favorites instead of those
+// reaching the threshold, so apps become favorites from their first launch.
+// Zero keeps the threshold.
+func WithTopN(n int) Option {
+	return func(m *Manager) {
+		m.topN = n
+	}
+}
+
+// WithMaxEvents sets how many events are kept per app, MaxEvents by default
+func WithMaxEvents(n int) Option {
+	return func(m *Manager) {
+		m.store.maxEvents = n
+	}
+}
+
+// WithRetention sets how long events are kept, Retention by default
+func WithRetention(d time.Duration) Option {
+	return func(m *Manager) {
+		m.store.retention = d
+	}
 }
 
 // NewManager creates a new favorites manager
-func NewManager(enabled bool) (*Manager, error) {
+func NewManager(enabled bool, opts ...Option) (*Manager, error) {
 	if !enabled {
 		return &Manager{
 			enabled: false,
@@ -28,11 +86,25 @@ func NewManager(enabled bool) (*Manager, error) {
 		return nil, err
 	}
 
-	return &Manager{
-		store:   store,
-		scorer:  NewScorer(),
-		enabled: enabled,
-	}, nil
+	queries, err := NewQueryStore(filepath.Join(getCacheDir(), "queries.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manager{
+		store:     store,
+		queries:   queries,
+		scorer:    NewDecayScorer(),
+		threshold: FavoriteThreshold,
+		enabled:   enabled,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m, nil
 }
 
 // RecordLaunch records an app launch event
@@ -41,14 +113,30 @@ func (m *Manager) RecordLaunch(e *entry.Entry) {
 		return
 	}
 	m.store.RecordEvent(e.Path, EventTypeLaunch)
+	m.invalidateTop()
 }
 
-// RecordSearch records a search event
-func (m *Manager) RecordSearch(e *entry.Entry) {
+// RecordSelection learns that an entry was launched after typing query
+func (m *Manager) RecordSelection(query string, e *entry.Entry) {
 	if !m.enabled {
 		return
 	}
-	m.store.RecordEvent(e.Path, EventTypeSearch)
+	m.queries.Record(query, e.Path, time.Now())
+}
+
+// Adaptive returns how often each entry was launched after typing a query
+// starting with query, scaled to [0, 1) where a single recent launch scores
+// 0.5, for ranking it among search results
+func (m *Manager) Adaptive(query string) func(*entry.Entry) float64 {
+	if !m.enabled {
+		return func(*entry.Entry) float64 { return 0 }
+	}
+
+	scores := m.queries.Scores(query, time.Now())
+	return func(e *entry.Entry) float64 {
+		score := scores[e.Path]
+		return score / (score + 1)
+	}
 }
 
 // GetScore returns the score for an entry
@@ -65,13 +153,81 @@ func (m *Manager) GetScore(e *entry.Entry) float64 {
 	return m.scorer.CalculateScore(stats)
 }
 
+// Frecency returns the score of an entry scaled to [0, 1), where entries
+// reaching the threshold score 0.5, for ranking it among search results
+func (m *Manager) Frecency(e *entry.Entry) float64 {
+	score := m.GetScore(e)
+	if score <= 0 {
+		return 0
+	}
+	return score / (score + m.threshold)
+}
+
 // IsFavorite checks if an app is a favorite
 func (m *Manager) IsFavorite(e *entry.Entry) bool {
 	if !m.enabled {
 		return false
 	}
-	score := m.GetScore(e)
-	return m.scorer.IsFavorite(score)
+	return m.favoriteTest()(e.Path, m.GetScore(e))
+}
+
+// favoriteTest returns how apps are told to be favorites from their
+// desktop file and score: by reaching the threshold, or with top N by being
+// among the N highest scoring apps
+func (m *Manager) favoriteTest() func(desktopFile string, score float64) bool {
+	if m.topN <= 0 {
+		return func(_ string, score float64) bool {
+			return score >= m.threshold
+		}
+	}
+
+	top := m.topFavorites()
+	return func(desktopFile string, _ float64) bool {
+		return top[desktopFile]
+	}
+}
+
+// topFavorites returns the desktop files of the N highest scoring apps.
+// They are found again once events change, not as scores decay, so
+// favorites don't change while the launcher is open.
+func (m *Manager) topFavorites() map[string]bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.top != nil {
+		return m.top
+	}
+
+	type app struct {
+		desktopFile string
+		score       float64
+	}
+	apps := make([]app, 0)
+	for desktopFile, stats := range m.store.GetAllStats() {
+		if score := m.scorer.CalculateScore(stats); score > 0 {
+			apps = append(apps, app{desktopFile, score})
+		}
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].score != apps[j].score {
+			return apps[i].score > apps[j].score
+		}
+		return apps[i].desktopFile < apps[j].desktopFile
+	})
+
+	m.top = make(map[string]bool, m.topN)
+	for i := 0; i < len(apps) && i < m.topN; i++ {
+		m.top[apps[i].desktopFile] = true
+	}
+	return m.top
+}
+
+// invalidateTop makes the top N favorites be found again, after events
+// changed
+func (m *Manager) invalidateTop() {
+	m.mu.Lock()
+	m.top = nil
+	m.mu.Unlock()
 }
 
 // SortByFavorites sorts entries with favorites first, then alphabetically
@@ -89,14 +245,15 @@ func (m *Manager) SortByFavorites(entries []*entry.Entry) {
 	for _, e := range entries {
 		scores[e.Path] = m.GetScore(e)
 	}
+	isFavorite := m.favoriteTest()
 
 	// Sort: favorites (by score desc) first, then non-favorites (alphabetically)
 	sort.Slice(entries, func(i, j int) bool {
 		scoreI := scores[entries[i].Path]
 		scoreJ := scores[entries[j].Path]
 
-		isFavI := m.scorer.IsFavorite(scoreI)
-		isFavJ := m.scorer.IsFavorite(scoreJ)
+		isFavI := isFavorite(entries[i].Path, scoreI)
+		isFavJ := isFavorite(entries[j].Path, scoreJ)
 
 		// Both are favorites: sort by score (higher first)
 		if isFavI && isFavJ {
@@ -118,13 +275,19 @@ func (m *Manager) Save() error {
 	if !m.enabled {
 		return nil
 	}
+	if err := m.queries.Save(); err != nil {
+		return err
+	}
 	return m.store.Save()
 }
 
-// CleanupOldEvents removes events older than 90 days
+// CleanupOldEvents removes events older than the retention, and selections
+// that decayed away
 func (m *Manager) CleanupOldEvents() {
 	if !m.enabled {
 		return
 	}
 	m.store.CleanupOldEvents()
+	m.queries.Cleanup(time.Now())
+	m.invalidateTop()
 }
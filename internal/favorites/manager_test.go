@@ -1,9 +1,13 @@
 package favorites
 
 import (
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
 )
@@ -83,6 +87,127 @@ func TestIsFavorite(t *testing.T) {
 	}
 }
 
+func TestTopN(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	m, _ := NewManager(true, WithTopN(2))
+	firefox := &entry.Entry{Name: "Firefox", Path: "/test/firefox.desktop"}
+	kitty := &entry.Entry{Name: "kitty", Path: "/test/kitty.desktop"}
+	slack := &entry.Entry{Name: "Slack", Path: "/test/slack.desktop"}
+	zed := &entry.Entry{Name: "Zed", Path: "/test/zed.desktop"}
+
+	// A single launch is enough while fewer than N apps were launched
+	m.RecordLaunch(firefox)
+	if !m.IsFavorite(firefox) {
+		t.Error("Entry should be favorite after its first launch")
+	}
+	if m.IsFavorite(zed) {
+		t.Error("Entry never launched should not be favorite")
+	}
+
+	for i := 0; i < 3; i++ {
+		m.RecordLaunch(kitty)
+	}
+	m.RecordLaunch(slack)
+	m.RecordLaunch(slack)
+
+	// Only the two most launched apps remain favorites
+	for _, tt := range []struct {
+		e    *entry.Entry
+		want bool
+	}{
+		{kitty, true},
+		{slack, true},
+		{firefox, false},
+		{zed, false},
+	} {
+		if got := m.IsFavorite(tt.e); got != tt.want {
+			t.Errorf("IsFavorite(%s) = %v, want %v", tt.e.Name, got, tt.want)
+		}
+	}
+
+	entries := []*entry.Entry{zed, firefox, slack, kitty}
+	m.SortByFavorites(entries)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	if got, want := strings.Join(names, ","), "kitty,Slack,Firefox,Zed"; got != want {
+		t.Errorf("SortByFavorites() = %s, want %s", got, want)
+	}
+}
+
+func TestManagerLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	m, _ := NewManager(true, WithThreshold(1.5), WithMaxEvents(3), WithRetention(7*24*time.Hour))
+	e := &entry.Entry{Name: "Firefox", Path: "/test/firefox.desktop"}
+
+	m.RecordLaunch(e)
+	if m.IsFavorite(e) {
+		t.Error("Entry should not be favorite below the threshold")
+	}
+	m.RecordLaunch(e)
+	if !m.IsFavorite(e) {
+		t.Error("Entry should be favorite at the threshold")
+	}
+
+	for i := 0; i < 5; i++ {
+		m.RecordLaunch(e)
+	}
+	stats, _ := m.store.GetStats(e.Path)
+	if len(stats.Events) != 3 {
+		t.Fatalf("Events = %d, want 3", len(stats.Events))
+	}
+
+	// Events older than the retention are cleaned up
+	stats.Events[0].Timestamp = time.Now().AddDate(0, 0, -8)
+	stats.Events[1].Timestamp = time.Now().AddDate(0, 0, -6)
+	m.CleanupOldEvents()
+	if len(stats.Events) != 2 {
+		t.Errorf("Events after cleanup = %d, want 2", len(stats.Events))
+	}
+}
+
+func TestFrecency(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	m, _ := NewManager(true)
+	e := &entry.Entry{
+		Name: "Firefox",
+		Path: "/test/firefox.desktop",
+	}
+
+	if frecency := m.Frecency(e); frecency != 0 {
+		t.Errorf("Frecency() before launches = %v, want 0", frecency)
+	}
+
+	// Launches raise frecency towards 1, passing 0.5 as the entry becomes a favorite
+	previous := 0.0
+	for i := 0; i < 20; i++ {
+		m.RecordLaunch(e)
+		frecency := m.Frecency(e)
+		if frecency <= previous || frecency >= 1 {
+			t.Fatalf("Frecency() after %d launches = %v, want in (%v, 1)", i+1, frecency, previous)
+		}
+		if m.IsFavorite(e) != (frecency >= 0.5) {
+			t.Errorf("Frecency() = %v, IsFavorite() = %v", frecency, m.IsFavorite(e))
+		}
+		previous = frecency
+	}
+
+	disabled, _ := NewManager(false)
+	if frecency := disabled.Frecency(e); frecency != 0 {
+		t.Errorf("Frecency() when disabled = %v, want 0", frecency)
+	}
+}
+
 func TestSortByFavorites(t *testing.T) {
 	tmpDir := t.TempDir()
 	os.Setenv("XDG_CACHE_HOME", tmpDir)
@@ -135,3 +260,211 @@ func TestSaveAndLoad(t *testing.T) {
 		t.Error("Events not loaded")
 	}
 }
+
+func TestRecordSelection(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	m, _ := NewManager(true)
+	kitty := &entry.Entry{Name: "kitty", Path: "/test/kitty.desktop"}
+	editor := &entry.Entry{Name: "Text Editor", Path: "/test/editor.desktop"}
+
+	m.RecordSelection("ter", kitty)
+	m.RecordSelection("Te", kitty)
+	m.RecordSelection("text", editor)
+
+	tests := []struct {
+		query  string
+		kitty  bool // Whether kitty scores
+		editor bool
+	}{
+		{"t", true, true},
+		{"te", true, true},
+		{"TE ", true, true},
+		{"ter", true, false},
+		{"term", false, false},
+		{"tex", false, true},
+		{"", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			adaptive := m.Adaptive(tt.query)
+			if got := adaptive(kitty) > 0; got != tt.kitty {
+				t.Errorf("Adaptive(%q)(kitty) = %v", tt.query, adaptive(kitty))
+			}
+			if got := adaptive(editor) > 0; got != tt.editor {
+				t.Errorf("Adaptive(%q)(editor) = %v", tt.query, adaptive(editor))
+			}
+		})
+	}
+
+	// Picked twice for "te", kitty ranks above the editor picked once
+	adaptive := m.Adaptive("te")
+	if adaptive(kitty) <= adaptive(editor) || adaptive(kitty) >= 1 {
+		t.Errorf("Adaptive(te) = %v for kitty, %v for editor", adaptive(kitty), adaptive(editor))
+	}
+
+	// Selections are saved and loaded
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	m2, _ := NewManager(true)
+	if m2.Adaptive("ter")(kitty) == 0 {
+		t.Error("Selections not loaded")
+	}
+
+	disabled, _ := NewManager(false)
+	disabled.RecordSelection("te", kitty)
+	if disabled.Adaptive("te")(kitty) != 0 {
+		t.Error("Adaptive() should score nothing when disabled")
+	}
+}
+
+func TestQueryStoreDecay(t *testing.T) {
+	store, _ := NewQueryStore(filepath.Join(t.TempDir(), "queries.json"))
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	store.Record("fire", "/test/firefox.desktop", now.AddDate(0, 0, -10))
+	store.Record("fire", "/test/firewall.desktop", now)
+
+	scores := store.Scores("fi", now)
+	if scores["/test/firefox.desktop"] >= scores["/test/firewall.desktop"] {
+		t.Errorf("Scores() = %v, want the older selection decayed", scores)
+	}
+
+	// Selections decayed away are forgotten
+	store.Cleanup(now.AddDate(0, 0, 25))
+	if scores := store.Scores("fi", now); len(scores) != 1 {
+		t.Errorf("Scores() after cleanup = %v, want only firewall", scores)
+	}
+	store.Cleanup(now.AddDate(0, 0, 100))
+	if len(store.queries) != 0 {
+		t.Errorf("Cleanup() kept %d queries", len(store.queries))
+	}
+}
+
+func TestQueryDecayRate(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		opts []Option
+		want float64 // Score of a selection ten days old
+	}{
+		{"default", nil, math.Exp(-DecayLambda * 10)},
+		{"faster", []Option{WithDecayRate(0.5)}, math.Exp(-0.5 * 10)},
+		{"none", []Option{WithDecayRate(0)}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := NewManager(true, tt.opts...)
+			m.queries.Record(tt.name, "/test/firefox.desktop", now.AddDate(0, 0, -10))
+			got := m.queries.Scores(tt.name, now)["/test/firefox.desktop"]
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Scores() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryStoreBounds(t *testing.T) {
+	store, _ := NewQueryStore(filepath.Join(t.TempDir(), "queries.json"))
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	// The query picked from most is kept when the store is full
+	store.Record("query 0", "/test/a.desktop", now)
+	store.Record("query 0", "/test/a.desktop", now)
+	for i := 1; i <= MaxQueries; i++ {
+		store.Record(fmt.Sprintf("query %d", i), "/test/a.desktop", now.Add(time.Duration(i)*time.Second))
+	}
+	if len(store.queries) != MaxQueries {
+		t.Errorf("Store has %d queries, want %d", len(store.queries), MaxQueries)
+	}
+	if _, ok := store.queries["query 0"]; !ok {
+		t.Error("Store evicted the strongest query")
+	}
+
+	// The strongest selections of a query are kept
+	for i := 0; i <= MaxSelections; i++ {
+		store.Record("many", fmt.Sprintf("/test/%d.desktop", i), now)
+	}
+	store.Record("many", "/test/0.desktop", now)
+	selections := store.queries["many"].Selections
+	if len(selections) != MaxSelections || selections[0].DesktopFile != "/test/0.desktop" {
+		t.Errorf("Selections = %v, want %d with 0 first", selections, MaxSelections)
+	}
+
+	// Long queries are cut
+	store.Record(strings.Repeat("a", 100), "/test/a.desktop", now)
+	if _, ok := store.queries[strings.Repeat("a", MaxQueryLength)]; !ok {
+		t.Errorf("Long query not cut to %d characters", MaxQueryLength)
+	}
+}
+
+func TestPinStore(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "pins.json")
+	p, err := NewPinStore(cachePath)
+	if err != nil {
+		t.Fatalf("NewPinStore() error = %v", err)
+	}
+
+	entries := []*entry.Entry{
+		{Name: "Alacritty", Path: "/test/alacritty.desktop"},
+		{Name: "Firefox", Path: "/test/firefox.desktop"},
+		{Name: "Slack", Path: "/test/slack.desktop"},
+		{Name: "Zed", Path: "/test/zed.desktop"},
+	}
+	names := func(entries []*entry.Entry) string {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name   string
+		change func()
+		want   string
+	}{
+		{"Pin", func() { p.Pin("/test/zed.desktop") }, "Zed,Alacritty,Firefox,Slack"},
+		{"Pin below", func() { p.Pin("/test/slack.desktop") }, "Zed,Slack,Alacritty,Firefox"},
+		{"Pin twice", func() { p.Pin("/test/zed.desktop") }, "Zed,Slack,Alacritty,Firefox"},
+		{"Move up", func() { p.Move("/test/slack.desktop", -1) }, "Slack,Zed,Alacritty,Firefox"},
+		{"Move past the top", func() { p.Move("/test/slack.desktop", -5) }, "Slack,Zed,Alacritty,Firefox"},
+		{"Move unpinned", func() { p.Move("/test/firefox.desktop", -1) }, "Slack,Zed,Alacritty,Firefox"},
+		{"Hide", func() { p.Hide("/test/firefox.desktop") }, "Slack,Zed,Alacritty"},
+		{"Hide pinned", func() { p.Hide("/test/zed.desktop") }, "Slack,Alacritty"},
+		{"Pin hidden", func() { p.Pin("/test/zed.desktop") }, "Slack,Zed,Alacritty"},
+		{"Unpin", func() { p.Unpin("/test/slack.desktop") }, "Zed,Alacritty,Slack"},
+	}
+
+	for _, tt := range tests {
+		tt.change()
+		if got := names(p.Apply(entries)); got != tt.want {
+			t.Errorf("%s: Apply() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// Entries passed in keep their order
+	if got := names(entries); got != "Alacritty,Firefox,Slack,Zed" {
+		t.Errorf("Apply() reordered its argument to %s", got)
+	}
+
+	if err := p.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, _ := NewPinStore(cachePath)
+	if got := names(loaded.Apply(entries)); got != "Zed,Alacritty,Slack" {
+		t.Errorf("Apply() after loading = %s, want Zed,Alacritty,Slack", got)
+	}
+	if !loaded.IsPinned("/test/zed.desktop") || !loaded.IsHidden("/test/firefox.desktop") {
+		t.Error("Pins not loaded")
+	}
+}
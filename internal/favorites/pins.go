@@ -0,0 +1,265 @@
+package favorites
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+)
+
+// pinsFile is the saved form of the pins
+type pinsFile struct {
+	Pinned []string `json:"pinned"` // In the order they are listed
+	Hidden []string `json:"hidden"`
+}
+
+// PinStore handles entries pinned to the top of the list in a chosen
+// order, and entries hidden from it. Unlike favorites they are set by hand.
+type PinStore struct {
+	cachePath string
+	pinned    []string
+	hidden    map[string]bool
+	mu        sync.RWMutex
+	dirty     bool // Tracks if data needs to be saved
+}
+
+// OpenPinStore opens the pins kept next to favorites.json. Pins that can't
+// be read are left out rather than stopping the launcher, and the file is
+// replaced once pins change.
+func OpenPinStore() *PinStore {
+	cachePath := filepath.Join(getCacheDir(), "pins.json")
+	store, err := NewPinStore(cachePath)
+	if err != nil {
+		if os.Getenv("DEBUG") == "1" {
+			fmt.Printf("Ignoring pins: %v\n", err)
+		}
+		return &PinStore{
+			cachePath: cachePath,
+			hidden:    make(map[string]bool),
+		}
+	}
+	return store
+}
+
+// NewPinStore creates a new pin store
+func NewPinStore(cachePath string) (*PinStore, error) {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return nil, err
+	}
+
+	store := &PinStore{
+		cachePath: cachePath,
+		hidden:    make(map[string]bool),
+	}
+
+	// Load existing data
+	if err := store.Load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Load reads pins from cache
+func (p *PinStore) Load() error {
+	data, err := os.ReadFile(p.cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Nothing pinned yet
+		}
+		return err
+	}
+
+	var file pinsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pinned = file.Pinned
+	for _, desktopFile := range file.Hidden {
+		p.hidden[desktopFile] = true
+	}
+
+	return nil
+}
+
+// Save writes pins to cache
+func (p *PinStore) Save() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Only save if data has changed
+	if !p.dirty {
+		return nil
+	}
+
+	file := pinsFile{
+		Pinned: p.pinned,
+		Hidden: make([]string, 0, len(p.hidden)),
+	}
+	for desktopFile := range p.hidden {
+		file.Hidden = append(file.Hidden, desktopFile)
+	}
+	sort.Strings(file.Hidden)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(p.cachePath, data, 0644); err != nil {
+		return err
+	}
+
+	p.dirty = false
+	return nil
+}
+
+// Pin pins an entry below those already pinned, showing it again if it
+// was hidden
+func (p *PinStore) Pin(desktopFile string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.index(desktopFile) >= 0 {
+		return
+	}
+	p.pinned = append(p.pinned, desktopFile)
+	delete(p.hidden, desktopFile)
+	p.dirty = true
+}
+
+// Unpin returns a pinned entry to its place in the list
+func (p *PinStore) Unpin(desktopFile string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.unpin(desktopFile)
+}
+
+// unpin removes an entry from the pins, the caller must hold the lock
+func (p *PinStore) unpin(desktopFile string) {
+	if i := p.index(desktopFile); i >= 0 {
+		p.pinned = append(p.pinned[:i], p.pinned[i+1:]...)
+		p.dirty = true
+	}
+}
+
+// Move moves a pinned entry up, for a negative offset, or down among the
+// pinned entries, stopping at the first and last place
+func (p *PinStore) Move(desktopFile string, offset int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	from := p.index(desktopFile)
+	if from < 0 {
+		return
+	}
+	to := max(0, min(len(p.pinned)-1, from+offset))
+	if to == from {
+		return
+	}
+
+	p.pinned = append(p.pinned[:from], p.pinned[from+1:]...)
+	p.pinned = append(p.pinned[:to], append([]string{desktopFile}, p.pinned[to:]...)...)
+	p.dirty = true
+}
+
+// Hide hides an entry from the list and search results, unpinning it
+func (p *PinStore) Hide(desktopFile string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.unpin(desktopFile)
+	if !p.hidden[desktopFile] {
+		p.hidden[desktopFile] = true
+		p.dirty = true
+	}
+}
+
+// Unhide shows a hidden entry again
+func (p *PinStore) Unhide(desktopFile string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.hidden[desktopFile] {
+		delete(p.hidden, desktopFile)
+		p.dirty = true
+	}
+}
+
+// IsPinned checks if an entry is pinned
+func (p *PinStore) IsPinned(desktopFile string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.index(desktopFile) >= 0
+}
+
+// IsHidden checks if an entry is hidden
+func (p *PinStore) IsHidden(desktopFile string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.hidden[desktopFile]
+}
+
+// index returns the place of a pinned entry, or -1 if it isn't pinned. The
+// caller must hold the lock.
+func (p *PinStore) index(desktopFile string) int {
+	for i, pinned := range p.pinned {
+		if pinned == desktopFile {
+			return i
+		}
+	}
+	return -1
+}
+
+// Visible returns the entries that aren't hidden, in the same order
+func (p *PinStore) Visible(entries []*entry.Entry) []*entry.Entry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.hidden) == 0 {
+		return entries
+	}
+
+	visible := make([]*entry.Entry, 0, len(entries))
+	for _, e := range entries {
+		if !p.hidden[e.Path] {
+			visible = append(visible, e)
+		}
+	}
+	return visible
+}
+
+// Apply returns the entries that aren't hidden with the pinned ones first,
+// in their chosen order. The other entries keep their order.
+func (p *PinStore) Apply(entries []*entry.Entry) []*entry.Entry {
+	entries = p.Visible(entries)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	rank := make(map[string]int, len(p.pinned))
+	for i, desktopFile := range p.pinned {
+		rank[desktopFile] = i
+	}
+
+	result := make([]*entry.Entry, len(entries))
+	copy(result, entries)
+	sort.SliceStable(result, func(i, j int) bool {
+		rankI, pinnedI := rank[result[i].Path]
+		rankJ, pinnedJ := rank[result[j].Path]
+		if pinnedI && pinnedJ {
+			return rankI < rankJ
+		}
+		return pinnedI && !pinnedJ
+	})
+	return result
+}
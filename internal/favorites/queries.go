@@ -0,0 +1,234 @@
+package favorites
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/search/fuzzy"
+)
+
+// QueryStore learns which entries are launched for which typed queries, so
+// typing the start of a query ranks what was picked for it first
+type QueryStore struct {
+	cachePath   string
+	queries     map[string]*QueryStats
+	decayLambda float64 // How fast selections lose importance
+	mu          sync.RWMutex
+	dirty       bool
+}
+
+// NewQueryStore creates a new query store
+func NewQueryStore(cachePath string) (*QueryStore, error) {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return nil, err
+	}
+
+	store := &QueryStore{
+		cachePath:   cachePath,
+		queries:     make(map[string]*QueryStats),
+		decayLambda: DecayLambda,
+	}
+
+	// Load existing data
+	if err := store.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Load reads learned queries from cache
+func (s *QueryStore) Load() error {
+	data, err := os.ReadFile(s.cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // No cache yet
+		}
+		return err
+	}
+
+	var statsList []*QueryStats
+	if err := json.Unmarshal(data, &statsList); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stats := range statsList {
+		s.queries[stats.Query] = stats
+	}
+
+	return nil
+}
+
+// Save writes learned queries to cache
+func (s *QueryStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Only save if data has changed
+	if !s.dirty {
+		return nil
+	}
+
+	statsList := make([]*QueryStats, 0, len(s.queries))
+	for _, stats := range s.queries {
+		statsList = append(statsList, stats)
+	}
+	sort.Slice(statsList, func(i, j int) bool {
+		return statsList[i].Query < statsList[j].Query
+	})
+
+	data, err := json.MarshalIndent(statsList, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(s.cachePath, data, 0644); err != nil {
+		return err
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// Record learns that an entry was launched after typing query
+func (s *QueryStore) Record(query, desktopFile string, now time.Time) {
+	query = normalizeQuery(query)
+	if query == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, exists := s.queries[query]
+	if !exists {
+		if len(s.queries) >= MaxQueries {
+			s.evictWeakest(now)
+		}
+		stats = &QueryStats{Query: query}
+		s.queries[query] = stats
+	}
+
+	found := false
+	for i := range stats.Selections {
+		sel := &stats.Selections[i]
+		if sel.DesktopFile == desktopFile {
+			sel.Score = s.decay(sel.Score, sel.Updated, now) + 1
+			sel.Updated = now
+			found = true
+			break
+		}
+	}
+	if !found {
+		stats.Selections = append(stats.Selections, Selection{
+			DesktopFile: desktopFile,
+			Score:       1,
+			Updated:     now,
+		})
+	}
+
+	// Keep the strongest selections
+	if len(stats.Selections) > MaxSelections {
+		sort.SliceStable(stats.Selections, func(i, j int) bool {
+			a, b := stats.Selections[i], stats.Selections[j]
+			return s.decay(a.Score, a.Updated, now) > s.decay(b.Score, b.Updated, now)
+		})
+		stats.Selections = stats.Selections[:MaxSelections]
+	}
+
+	s.dirty = true
+}
+
+// Scores returns the decayed score of each entry launched for a query
+// starting with the one typed, keyed by desktop file
+func (s *QueryStore) Scores(query string, now time.Time) map[string]float64 {
+	query = normalizeQuery(query)
+	if query == "" {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var scores map[string]float64
+	for learned, stats := range s.queries {
+		if !strings.HasPrefix(learned, query) {
+			continue
+		}
+		for _, sel := range stats.Selections {
+			if scores == nil {
+				scores = make(map[string]float64)
+			}
+			scores[sel.DesktopFile] += s.decay(sel.Score, sel.Updated, now)
+		}
+	}
+	return scores
+}
+
+// Cleanup forgets selections that decayed away
+func (s *QueryStore) Cleanup(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for query, stats := range s.queries {
+		kept := stats.Selections[:0]
+		for _, sel := range stats.Selections {
+			if s.decay(sel.Score, sel.Updated, now) >= MinSelectionScore {
+				kept = append(kept, sel)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(s.queries, query)
+			s.dirty = true
+		} else if len(kept) != len(stats.Selections) {
+			stats.Selections = kept
+			s.dirty = true
+		}
+	}
+}
+
+// evictWeakest forgets the query whose selections decayed the most, the
+// caller must hold the lock
+func (s *QueryStore) evictWeakest(now time.Time) {
+	weakest := ""
+	weakestScore := math.Inf(1)
+	for query, stats := range s.queries {
+		score := 0.0
+		for _, sel := range stats.Selections {
+			score += s.decay(sel.Score, sel.Updated, now)
+		}
+		if score < weakestScore || score == weakestScore && query < weakest {
+			weakest, weakestScore = query, score
+		}
+	}
+	delete(s.queries, weakest)
+}
+
+// decay applies the exponential time decay of events to a score
+func (s *QueryStore) decay(score float64, updated, now time.Time) float64 {
+	age := now.Sub(updated).Hours() / 24.0
+	if age <= 0 {
+		return score
+	}
+	return score * math.Exp(-s.decayLambda*age)
+}
+
+// normalizeQuery returns a query folded, with single spaces and at most
+// MaxQueryLength characters, as learned and looked up
+func normalizeQuery(query string) string {
+	query = strings.Join(strings.Fields(fuzzy.Fold(query)), " ")
+	if runes := []rune(query); len(runes) > MaxQueryLength {
+		query = strings.TrimSpace(string(runes[:MaxQueryLength]))
+	}
+	return query
+}
@@ -1,54 +1,189 @@
 package favorites
 
 import (
+	"fmt"
 	"math"
 	"time"
 )
 
-// Scorer handles score calculations for favorites
-type Scorer struct{}
+// Scorer computes how much an app is used from its events. Scorers compare
+// events against the time of their clock, set with WithClock.
+type Scorer interface {
+	CalculateScore(stats *AppStats) float64
+}
+
+// Names of the scorers, as configured
+const (
+	ScorerDecay   = "decay"
+	ScorerContext = "context"
+)
+
+// ScorerOption is a functional option for scorers
+type ScorerOption func(*scoring)
+
+// scoring holds the settings every scorer shares
+type scoring struct {
+	now          func() time.Time
+	decayLambda  float64
+	launchWeight float64
+	searchWeight float64
+}
+
+// WithClock sets the clock events are compared against, time.Now by default
+func WithClock(now func() time.Time) ScorerOption {
+	return func(s *scoring) {
+		s.now = now
+	}
+}
 
-// NewScorer creates a new scorer
-func NewScorer() *Scorer {
-	return &Scorer{}
+// WithDecay sets how fast old events lose importance, DecayLambda by default
+func WithDecay(lambda float64) ScorerOption {
+	return func(s *scoring) {
+		s.decayLambda = lambda
+	}
+}
+
+// WithEventWeights sets the importance of launch and search events,
+// EventWeightLaunch and EventWeightSearch by default
+func WithEventWeights(launch, search float64) ScorerOption {
+	return func(s *scoring) {
+		s.launchWeight = launch
+		s.searchWeight = search
+	}
+}
+
+// newScoring applies scorer options to the default settings
+func newScoring(opts []ScorerOption) scoring {
+	s := scoring{
+		now:          time.Now,
+		decayLambda:  DecayLambda,
+		launchWeight: EventWeightLaunch,
+		searchWeight: EventWeightSearch,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// NewScorer creates a scorer by name, the decay scorer if name is empty
+func NewScorer(name string, opts ...ScorerOption) (Scorer, error) {
+	switch name {
+	case "", ScorerDecay:
+		return NewDecayScorer(opts...), nil
+	case ScorerContext:
+		return NewContextScorer(opts...), nil
+	}
+	return nil, fmt.Errorf("%w: %q, want %s or %s", ErrUnknownScorer, name, ScorerDecay, ScorerContext)
+}
+
+// DecayScorer weights events by their age alone
+type DecayScorer struct {
+	scoring
+}
+
+// NewDecayScorer creates a new decay scorer
+func NewDecayScorer(opts ...ScorerOption) *DecayScorer {
+	return &DecayScorer{scoring: newScoring(opts)}
 }
 
 // CalculateScore computes the time-decay weighted score
-func (s *Scorer) CalculateScore(stats *AppStats) float64 {
+func (s *DecayScorer) CalculateScore(stats *AppStats) float64 {
 	if stats == nil || len(stats.Events) == 0 {
 		return 0
 	}
 
-	now := time.Now()
+	now := s.now()
 	score := 0.0
 
 	for _, event := range stats.Events {
-		// Calculate age in days
-		age := now.Sub(event.Timestamp).Hours() / 24.0
+		score += s.eventWeight(event.Type) * s.ageDecay(event.Timestamp, now)
+	}
 
-		// Get event weight
-		weight := s.getEventWeight(event.Type)
+	return score
+}
 
-		// Apply exponential time decay: weight * e^(-λ * age)
-		score += weight * math.Exp(-DecayLambda*age)
+// ContextScorer weights events by their age and by how close their hour of
+// day and weekday are to now, so apps used in the morning score higher in
+// the morning
+type ContextScorer struct {
+	scoring
+}
+
+// NewContextScorer creates a new contextual scorer
+func NewContextScorer(opts ...ScorerOption) *ContextScorer {
+	return &ContextScorer{scoring: newScoring(opts)}
+}
+
+// CalculateScore computes the time-decay weighted score, scaled down for
+// events far from the current time of day and weekday
+func (s *ContextScorer) CalculateScore(stats *AppStats) float64 {
+	if stats == nil || len(stats.Events) == 0 {
+		return 0
+	}
+
+	now := s.now()
+	score := 0.0
+
+	for _, event := range stats.Events {
+		similarity := hourSimilarity(event.Timestamp, now) * weekdaySimilarity(event.Timestamp, now)
+		context := ContextFloor + (1-ContextFloor)*similarity
+		score += s.eventWeight(event.Type) * s.ageDecay(event.Timestamp, now) * context
 	}
 
 	return score
 }
 
-// getEventWeight returns the weight for an event type
-func (s *Scorer) getEventWeight(eventType EventType) float64 {
+// hourSimilarity compares the times of day of t and now, in now's time
+// zone: 1 at the same time, fading with the hours apart
+func hourSimilarity(t, now time.Time) float64 {
+	t = t.In(now.Location())
+	hours := math.Abs(hourOfDay(t) - hourOfDay(now))
+	if hours > 12 {
+		hours = 24 - hours // 23:00 is an hour from midnight
+	}
+	return math.Exp(-hours * hours / (2 * ContextHourSpread * ContextHourSpread))
+}
+
+// hourOfDay returns the time of day of t in hours
+func hourOfDay(t time.Time) float64 {
+	return float64(t.Hour()) + float64(t.Minute())/60
+}
+
+// weekdaySimilarity compares the weekdays of t and now, in now's time zone:
+// 1 on the same day, less on another working day or weekend day, and
+// least between working days and weekends
+func weekdaySimilarity(t, now time.Time) float64 {
+	t = t.In(now.Location())
+	switch {
+	case t.Weekday() == now.Weekday():
+		return 1
+	case isWeekend(t) == isWeekend(now):
+		return ContextSameKindOfDay
+	default:
+		return ContextOtherKindOfDay
+	}
+}
+
+// isWeekend reports whether t falls on a Saturday or Sunday
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// ageDecay applies exponential time decay: e^(-λ * age in days)
+func (s *scoring) ageDecay(t, now time.Time) float64 {
+	age := now.Sub(t).Hours() / 24.0
+	return math.Exp(-s.decayLambda * age)
+}
+
+// eventWeight returns the weight for an event type
+func (s *scoring) eventWeight(eventType EventType) float64 {
 	switch eventType {
 	case EventTypeLaunch:
-		return EventWeightLaunch
+		return s.launchWeight
 	case EventTypeSearch:
-		return EventWeightSearch
+		return s.searchWeight
 	default:
 		return 0
 	}
 }
-
-// IsFavorite checks if a score qualifies as a favorite
-func (s *Scorer) IsFavorite(score float64) bool {
-	return score >= FavoriteThreshold
-}
@@ -0,0 +1,193 @@
+package favorites
+
+import (
+	"errors"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+)
+
+// launches returns launch events at the given hour on each of the days
+// before now
+func launches(now time.Time, hour, days int) *AppStats {
+	stats := &AppStats{}
+	for day := 1; day <= days; day++ {
+		t := time.Date(now.Year(), now.Month(), now.Day()-day, hour, 0, 0, 0, now.Location())
+		stats.Events = append(stats.Events, Event{Timestamp: t, Type: EventTypeLaunch})
+	}
+	return stats
+}
+
+func TestDecayScorer(t *testing.T) {
+	now := time.Date(2024, 6, 17, 9, 0, 0, 0, time.UTC)
+	s := NewDecayScorer(WithClock(func() time.Time { return now }))
+
+	stats := &AppStats{Events: []Event{
+		{Timestamp: now, Type: EventTypeLaunch},
+		{Timestamp: now.AddDate(0, 0, -10), Type: EventTypeLaunch},
+		{Timestamp: now, Type: EventTypeSearch},
+	}}
+	want := 1 + math.Exp(-1) + EventWeightSearch
+	if got := s.CalculateScore(stats); math.Abs(got-want) > 1e-9 {
+		t.Errorf("CalculateScore() = %v, want %v", got, want)
+	}
+
+	if got := s.CalculateScore(nil); got != 0 {
+		t.Errorf("CalculateScore(nil) = %v, want 0", got)
+	}
+}
+
+func TestScorerSettings(t *testing.T) {
+	now := time.Date(2024, 6, 17, 9, 0, 0, 0, time.UTC)
+	stats := &AppStats{Events: []Event{
+		{Timestamp: now.AddDate(0, 0, -10), Type: EventTypeLaunch},
+		{Timestamp: now, Type: EventTypeSearch},
+	}}
+
+	tests := []struct {
+		name string
+		opts []ScorerOption
+		want float64
+	}{
+		{"Defaults", nil, math.Exp(-1) + EventWeightSearch},
+		{"No decay", []ScorerOption{WithDecay(0)}, 1 + EventWeightSearch},
+		{"Weights", []ScorerOption{WithEventWeights(2, 0)}, 2 * math.Exp(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ScorerOption{WithClock(func() time.Time { return now })}, tt.opts...)
+			s := NewDecayScorer(opts...)
+			if got := s.CalculateScore(stats); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CalculateScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextScorer(t *testing.T) {
+	// Monday, with Slack opened mornings and Steam evenings for two weeks
+	monday := time.Date(2024, 6, 17, 0, 0, 0, 0, time.UTC)
+	slack := launches(monday, 9, 14)
+	steam := launches(monday, 21, 14)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string // Higher scoring app
+	}{
+		{"Morning", monday.Add(9 * time.Hour), "slack"},
+		{"Late morning", monday.Add(11*time.Hour + 30*time.Minute), "slack"},
+		{"Evening", monday.Add(21 * time.Hour), "steam"},
+		{"Around midnight", monday.Add(-time.Hour), "steam"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewContextScorer(WithClock(func() time.Time { return tt.now }))
+			slackScore, steamScore := s.CalculateScore(slack), s.CalculateScore(steam)
+			if got := map[bool]string{true: "slack", false: "steam"}[slackScore > steamScore]; got != tt.want {
+				t.Errorf("CalculateScore() = %v for slack, %v for steam, want %s higher", slackScore, steamScore, tt.want)
+			}
+
+			// Context only scales the decay score down
+			decay := NewDecayScorer(WithClock(func() time.Time { return tt.now }))
+			if slackScore > decay.CalculateScore(slack) || slackScore < ContextFloor*decay.CalculateScore(slack) {
+				t.Errorf("CalculateScore() = %v, want between %v and %v times the decay score", slackScore, ContextFloor, 1.0)
+			}
+		})
+	}
+}
+
+func TestContextSimilarity(t *testing.T) {
+	monday := time.Date(2024, 6, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		t    time.Time
+		hour float64
+		day  float64
+	}{
+		{"Same time", monday.AddDate(0, 0, -7), 1, 1},
+		{"Two hours apart", monday.AddDate(0, 0, -7).Add(2 * time.Hour), math.Exp(-0.5), 1},
+		{"Other working day", monday.AddDate(0, 0, 1), 1, ContextSameKindOfDay},
+		{"Weekend", monday.AddDate(0, 0, -1), 1, ContextOtherKindOfDay},
+		{"Other time zone", monday.In(time.FixedZone("UTC+2", 2*3600)), 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hourSimilarity(tt.t, monday); math.Abs(got-tt.hour) > 1e-9 {
+				t.Errorf("hourSimilarity() = %v, want %v", got, tt.hour)
+			}
+			if got := weekdaySimilarity(tt.t, monday); got != tt.day {
+				t.Errorf("weekdaySimilarity() = %v, want %v", got, tt.day)
+			}
+		})
+	}
+
+	// Hours wrap around midnight
+	late := time.Date(2024, 6, 16, 23, 0, 0, 0, time.UTC)
+	midnight := time.Date(2024, 6, 17, 0, 0, 0, 0, time.UTC)
+	if got, want := hourSimilarity(late, midnight), math.Exp(-1.0/8); math.Abs(got-want) > 1e-9 {
+		t.Errorf("hourSimilarity(23:00, 00:00) = %v, want %v", got, want)
+	}
+}
+
+func TestNewScorer(t *testing.T) {
+	tests := []struct {
+		name string
+		want Scorer
+		err  error
+	}{
+		{"", &DecayScorer{}, nil},
+		{ScorerDecay, &DecayScorer{}, nil},
+		{ScorerContext, &ContextScorer{}, nil},
+		{"random", nil, ErrUnknownScorer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer, err := NewScorer(tt.name)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NewScorer(%q) error = %v, want %v", tt.name, err, tt.err)
+			}
+			switch tt.want.(type) {
+			case *DecayScorer:
+				if _, ok := scorer.(*DecayScorer); !ok {
+					t.Errorf("NewScorer(%q) = %T, want *DecayScorer", tt.name, scorer)
+				}
+			case *ContextScorer:
+				if _, ok := scorer.(*ContextScorer); !ok {
+					t.Errorf("NewScorer(%q) = %T, want *ContextScorer", tt.name, scorer)
+				}
+			}
+		})
+	}
+}
+
+// fixedScorer scores every app the same
+type fixedScorer float64
+
+func (s fixedScorer) CalculateScore(stats *AppStats) float64 {
+	return float64(s)
+}
+
+func TestManagerWithScorer(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	m, _ := NewManager(true, WithScorer(fixedScorer(FavoriteThreshold)))
+	e := &entry.Entry{Name: "Slack", Path: "/test/slack.desktop"}
+	m.RecordLaunch(e)
+
+	if score := m.GetScore(e); score != FavoriteThreshold {
+		t.Errorf("GetScore() = %v, want %v", score, FavoriteThreshold)
+	}
+	if !m.IsFavorite(e) {
+		t.Error("Entry should be favorite with the injected scorer")
+	}
+}
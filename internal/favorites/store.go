@@ -13,7 +13,9 @@ type Store struct {
 	cachePath string
 	stats     map[string]*AppStats
 	mu        sync.RWMutex
-	dirty     bool // Tracks if data needs to be saved
+	dirty     bool          // Tracks if data needs to be saved
+	maxEvents int           // Events kept per app
+	retention time.Duration // How long events are kept
 }
 
 // NewStore creates a new favorites store
@@ -26,6 +28,8 @@ func NewStore(cachePath string) (*Store, error) {
 		cachePath: cachePath,
 		stats:     make(map[string]*AppStats),
 		dirty:     false,
+		maxEvents: MaxEvents,
+		retention: Retention,
 	}
 
 	// Load existing data
@@ -123,20 +127,21 @@ func (s *Store) RecordEvent(desktopFile string, eventType EventType) {
 	})
 
 	// Prune old events if we exceed max
-	if len(stats.Events) > MaxEvents {
-		stats.Events = stats.Events[len(stats.Events)-MaxEvents:]
+	if len(stats.Events) > s.maxEvents {
+		stats.Events = stats.Events[len(stats.Events)-s.maxEvents:]
 	}
 
 	// Mark as dirty
 	s.dirty = true
 }
 
-// CleanupOldEvents removes events older than 90 days
+// CleanupOldEvents removes events older than the retention, 90 days by
+// default
 func (s *Store) CleanupOldEvents() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	cutoff := time.Now().AddDate(0, 0, -90)
+	cutoff := time.Now().Add(-s.retention)
 	needsSave := false
 
 	for desktopFile, stats := range s.stats {
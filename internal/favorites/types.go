@@ -2,11 +2,14 @@ package favorites
 
 import "time"
 
+// Defaults of the scoring settings, which the application module reads
+// from its favorites table
 const (
 	// EventWeightLaunch determines importance of launch events
 	EventWeightLaunch = 1.0
 
-	// EventWeightSearch determines importance of search events
+	// EventWeightSearch determines importance of search events, which
+	// earlier versions recorded for the first result of every query
 	EventWeightSearch = 0.3
 
 	// DecayLambda controls how fast old events lose importance
@@ -18,6 +21,27 @@ const (
 
 	// MaxEvents limits memory usage
 	MaxEvents = 1000
+
+	// Retention is how long events are kept
+	Retention = 90 * 24 * time.Hour
+)
+
+const (
+	// ContextFloor is how much the contextual scorer counts events from
+	// the least similar time, so apps still count out of their context
+	ContextFloor = 0.2
+
+	// ContextHourSpread is how many hours apart times of day still count
+	// as similar, the standard deviation of their similarity
+	ContextHourSpread = 2.0
+
+	// ContextSameKindOfDay is the similarity of different working days, or
+	// of Saturday and Sunday
+	ContextSameKindOfDay = 0.7
+
+	// ContextOtherKindOfDay is the similarity of a working day and a
+	// weekend day
+	ContextOtherKindOfDay = 0.4
 )
 
 // EventType represents the type of user interaction
@@ -40,3 +64,31 @@ type AppStats struct {
 	Events      []Event `json:"events"`
 	Score       float64 `json:"-"` // Computed at runtime
 }
+
+const (
+	// MaxQueries bounds how many typed queries selections are learned for
+	MaxQueries = 500
+
+	// MaxSelections bounds how many entries are remembered per query
+	MaxSelections = 8
+
+	// MaxQueryLength is how many characters of a query are learned from
+	MaxQueryLength = 32
+
+	// MinSelectionScore is the score below which a selection is forgotten
+	MinSelectionScore = 0.05
+)
+
+// Selection records how often an entry was launched for a query, decayed
+// like events as time passes
+type Selection struct {
+	DesktopFile string    `json:"desktop_file"`
+	Score       float64   `json:"score"`
+	Updated     time.Time `json:"updated"`
+}
+
+// QueryStats tracks the entries launched after typing a query
+type QueryStats struct {
+	Query      string      `json:"query"`
+	Selections []Selection `json:"selections"`
+}
@@ -1,9 +1,17 @@
 package application
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/antoniosarro/gofi/internal/config"
+	"github.com/antoniosarro/gofi/internal/favorites"
 	"github.com/antoniosarro/gofi/internal/modules"
 	"github.com/antoniosarro/gofi/internal/scanner"
+	"github.com/antoniosarro/gofi/internal/scanner/watcher"
+	"github.com/antoniosarro/gofi/internal/search"
 	"github.com/antoniosarro/gofi/internal/ui"
 	"github.com/diamondburned/gotk4/pkg/gtk/v4"
 )
@@ -16,6 +24,7 @@ func init() {
 // Module implements the modules.Module interface for the application launcher
 type Module struct {
 	scanner *scanner.Scanner
+	watcher *watcher.Watcher
 	window  *ui.Window
 	config  *config.ModuleConfig
 }
@@ -34,31 +43,84 @@ func (m *Module) Description() string {
 func (m *Module) Initialize(cfg *config.ModuleConfig) error {
 	m.config = cfg
 
+	// Score favorites with the configured strategy and settings
+	fc := cfg.Favorites
+	scorer, err := favorites.NewScorer(fc.Scorer,
+		favorites.WithDecay(fc.DecayLambda),
+		favorites.WithEventWeights(fc.LaunchWeight, fc.SearchWeight))
+	if err != nil {
+		return fmt.Errorf("favorites.scorer: %w", err)
+	}
+
 	// Create scanner with configuration
-	s, err := scanner.NewScanner(cfg.EnableFavorites, cfg.ScanGameLaunchers)
+	s, err := scanner.NewScanner(cfg.EnableFavorites, cfg.ScanGameLaunchers,
+		scanner.WithGamesByLastPlayed(cfg.SortGamesByLastPlayed),
+		scanner.WithTypoThreshold(cfg.TypoThreshold),
+		scanner.WithRankWeights(search.Weights{
+			Match:    cfg.RankMatchWeight,
+			Frecency: cfg.RankFrecencyWeight,
+			Adaptive: cfg.RankAdaptiveWeight,
+		}),
+		scanner.WithFavoritesOptions(
+			favorites.WithScorer(scorer),
+			favorites.WithDecayRate(fc.DecayLambda),
+			favorites.WithThreshold(fc.Threshold),
+			favorites.WithTopN(fc.TopN),
+			favorites.WithMaxEvents(fc.MaxEvents),
+			favorites.WithRetention(time.Duration(fc.RetentionDays)*24*time.Hour)))
 	if err != nil {
 		return err
 	}
 
-	// Scan for applications and games
+	// Scan for applications, games are added once their launchers finish
 	// This initializes the search engine internally
-	if err := s.Scan(); err != nil {
+	if err := s.ScanApplications(); err != nil {
 		return err
 	}
 
 	m.scanner = s
+
+	// Pick up applications installed or removed while running.
+	// Without file system notifications the initial scan is kept as is,
+	// and games show up from the next search typed.
+	w, err := watcher.New(s)
+	if err != nil {
+		if os.Getenv("DEBUG") == "1" {
+			fmt.Printf("Live rescanning disabled: %v\n", err)
+		}
+		go s.LoadGameLaunchers(context.Background(), nil)
+		return nil
+	}
+	w.Start()
+	w.LoadGameLaunchers()
+	m.watcher = w
+
 	return nil
 }
 
 // CreateWindow creates the application launcher window
 func (m *Module) CreateWindow(app *gtk.Application) (modules.Window, error) {
+	// Subscribe first, so games loaded while the window is built aren't missed
+	var events <-chan watcher.Event
+	if m.watcher != nil {
+		events = m.watcher.Subscribe()
+	}
 	window := ui.New(app, m.scanner, m.config)
+	if events != nil {
+		window.Watch(events)
+	}
 	m.window = window
 	return window, nil
 }
 
 // Cleanup performs cleanup before shutdown
 func (m *Module) Cleanup() error {
+	if m.watcher != nil {
+		if err := m.watcher.Close(); err != nil {
+			return err
+		}
+		m.watcher = nil
+	}
 	if m.scanner != nil {
 		// Scanner handles favorites saving
 		return nil
@@ -2,24 +2,50 @@ package emoji
 
 import (
 	"strings"
+
+	"github.com/antoniosarro/gofi/internal/search/query"
 )
 
-// Search searches emojis by query matching name and keywords
-func Search(emojis []Emoji, query string) []Emoji {
-	if query == "" {
-		return emojis
+// fieldCategory filters emojis by category, as in cat:flags
+const fieldCategory = "cat"
+
+// Search searches emojis by query matching name, keywords and category.
+// Queries use the syntax of package query, as in "heart -broken cat:symbols".
+// A query that doesn't parse is searched as text, and its error returned
+// along with the results.
+func Search(emojis []Emoji, q string) ([]Emoji, error) {
+	node, err := query.Parse(q, fieldCategory)
+	if err != nil {
+		node = &query.Text{Value: strings.TrimSpace(q)}
+	}
+	if node == nil {
+		return emojis, nil
 	}
 
-	query = strings.ToLower(strings.TrimSpace(query))
 	results := make([]Emoji, 0)
 
 	for _, emoji := range emojis {
-		if matchesQuery(emoji, query) {
+		if query.Match(node, func(n query.Node) bool { return matchesTerm(emoji, n) }) {
 			results = append(results, emoji)
 		}
 	}
 
-	return results
+	return results, err
+}
+
+// matchesTerm checks an emoji against text, a phrase or a category filter
+func matchesTerm(emoji Emoji, n query.Node) bool {
+	switch n := n.(type) {
+	case *query.Text:
+		return matchesQuery(emoji, strings.ToLower(n.Value))
+	case *query.Phrase:
+		return matchesQuery(emoji, strings.ToLower(n.Value))
+	case *query.Filter:
+		category := strings.ToLower(n.Value)
+		return strings.Contains(strings.ToLower(emoji.Category), category) ||
+			strings.Contains(strings.ToLower(emoji.Subcategory), category)
+	}
+	return false
 }
 
 func matchesQuery(emoji Emoji, query string) bool {
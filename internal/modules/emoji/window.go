@@ -64,7 +64,7 @@ func (w *Window) buildUI() {
 
 	// Search entry
 	w.searchEntry = gtk.NewSearchEntry()
-	w.searchEntry.SetPlaceholderText("Search emojis by name or keyword...")
+	w.searchEntry.SetPlaceholderText("Search emojis by name, keyword or cat:flags...")
 	w.searchEntry.SetHExpand(true)
 	w.searchEntry.ConnectSearchChanged(w.onSearchChangedDebounced)
 	mainBox.Append(w.searchEntry)
@@ -190,8 +190,16 @@ func (w *Window) onSearchChangedDebounced() {
 func (w *Window) updateEmojis() {
 	query := w.searchEntry.Text()
 
-	// Search
-	w.filtered = Search(w.emojis, query)
+	// Search, pointing out mistakes in the query such as an unterminated quote
+	filtered, err := Search(w.emojis, query)
+	w.filtered = filtered
+	if err != nil {
+		w.searchEntry.AddCSSClass("error")
+		w.searchEntry.SetTooltipText(err.Error())
+	} else {
+		w.searchEntry.RemoveCSSClass("error")
+		w.searchEntry.SetTooltipText("")
+	}
 
 	// Update UI
 	w.populateEmojis()
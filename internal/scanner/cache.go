@@ -0,0 +1,297 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// cacheVersion is bumped whenever the cache layout or the meaning of
+	// parsed entries changes, discarding caches written by older versions
+	cacheVersion = 1
+
+	// cacheFileName is the name of the entry cache in the gofi cache dir
+	cacheFileName = "desktop-entries.json"
+)
+
+// cacheFile is the on-disk envelope of the entry cache. The checksum covers
+// the raw data so truncated or edited files are detected.
+type cacheFile struct {
+	Version  int             `json:"version"`
+	Checksum string          `json:"checksum"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// cacheData holds the cached directory listings and parsed desktop files
+type cacheData struct {
+	// Environment the entries were parsed in, see cacheEnvironment
+	Environment string                 `json:"environment"`
+	Dirs        map[string]*cachedDir  `json:"dirs"`
+	Files       map[string]*cachedFile `json:"files"`
+}
+
+// cachedDir is a directory listing, valid while the mtime is unchanged
+type cachedDir struct {
+	ModTime int64      `json:"mtime"`
+	Entries []dirEntry `json:"entries"`
+}
+
+// dirEntry is a .desktop file or subdirectory of a listed directory
+type dirEntry struct {
+	Name  string `json:"name"`
+	IsDir bool   `json:"dir,omitempty"`
+}
+
+// cachedFile is a parsed desktop file, valid while mtime and size match
+type cachedFile struct {
+	ModTime int64 `json:"mtime"`
+	Size    int64 `json:"size"`
+	desktopFile
+}
+
+// entryCache avoids reading directories and parsing desktop files that
+// haven't changed since the last scan. It is safe for concurrent use.
+type entryCache struct {
+	mu    sync.Mutex
+	path  string
+	data  cacheData
+	dirty bool
+
+	// Paths used during the current scan, everything else is pruned
+	usedDirs  map[string]bool
+	usedFiles map[string]bool
+}
+
+// entryCachePath returns the location of the entry cache
+func entryCachePath() string {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		cacheHome = filepath.Join(os.Getenv("HOME"), ".cache")
+	}
+	return filepath.Join(cacheHome, "gofi", cacheFileName)
+}
+
+// cacheEnvironment describes the settings parsed entries depend on. A cache
+// written under different settings is stale as a whole.
+func cacheEnvironment() string {
+	var parts []string
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG", "XDG_CURRENT_DESKTOP"} {
+		parts = append(parts, name+"="+os.Getenv(name))
+	}
+	return strings.Join(parts, ";")
+}
+
+// newEntryCache creates an empty cache stored at path
+func newEntryCache(path string) *entryCache {
+	return &entryCache{
+		path: path,
+		data: cacheData{
+			Environment: cacheEnvironment(),
+			Dirs:        make(map[string]*cachedDir),
+			Files:       make(map[string]*cachedFile),
+		},
+		usedDirs:  make(map[string]bool),
+		usedFiles: make(map[string]bool),
+	}
+}
+
+// loadEntryCache reads the cache at path. It always returns a usable cache,
+// which is empty when the file is missing, stale or unreadable; the error
+// tells why the cached data was discarded.
+func loadEntryCache(path string) (*entryCache, error) {
+	c := newEntryCache(path)
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil // No cache yet
+		}
+		return c, err
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return c, fmt.Errorf("%w: %v", ErrCacheCorrupt, err)
+	}
+	if file.Version != cacheVersion {
+		return c, fmt.Errorf("%w: got %d, want %d", ErrCacheVersion, file.Version, cacheVersion)
+	}
+	if file.Checksum != checksum(file.Data) {
+		return c, fmt.Errorf("%w: checksum mismatch", ErrCacheCorrupt)
+	}
+
+	var data cacheData
+	if err := json.Unmarshal(file.Data, &data); err != nil {
+		return c, fmt.Errorf("%w: %v", ErrCacheCorrupt, err)
+	}
+
+	// Entries parsed for another locale or desktop are of no use
+	if data.Environment != c.data.Environment {
+		return c, nil
+	}
+
+	if data.Dirs != nil {
+		c.data.Dirs = data.Dirs
+	}
+	if data.Files != nil {
+		c.data.Files = data.Files
+	}
+	return c, nil
+}
+
+// listDir returns the .desktop files and subdirectories of dir, sorted by
+// name, reading the directory only if it changed since it was cached
+func (c *entryCache) listDir(dir string) ([]dirEntry, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.usedDirs[dir] = true
+	modTime := info.ModTime().UnixNano()
+	if cached, ok := c.data.Dirs[dir]; ok && cached.ModTime == modTime {
+		return cached.Entries, nil
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []dirEntry
+	for _, d := range dirEntries {
+		switch {
+		case d.IsDir():
+			entries = append(entries, dirEntry{Name: d.Name(), IsDir: true})
+		case strings.HasSuffix(d.Name(), ".desktop"):
+			entries = append(entries, dirEntry{Name: d.Name()})
+		}
+	}
+
+	c.data.Dirs[dir] = &cachedDir{ModTime: modTime, Entries: entries}
+	c.dirty = true
+	return entries, nil
+}
+
+// parse returns the parsed desktop file at path, reading it only if its
+// mtime or size changed since it was cached
+func (c *entryCache) parse(path string) (*desktopFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		c.forget(path)
+		return nil, err
+	}
+
+	modTime := info.ModTime().UnixNano()
+	c.mu.Lock()
+	c.usedFiles[path] = true
+	cached, ok := c.data.Files[path]
+	c.mu.Unlock()
+	if ok && cached.ModTime == modTime && cached.Size == info.Size() {
+		return &cached.desktopFile, nil
+	}
+
+	// Parse without holding the lock so other files are parsed meanwhile
+	parsed, err := parseDesktopFile(path)
+	if err != nil {
+		c.forget(path)
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.data.Files[path] = &cachedFile{ModTime: modTime, Size: info.Size(), desktopFile: *parsed}
+	c.dirty = true
+	c.mu.Unlock()
+	return parsed, nil
+}
+
+// forget drops a file that can no longer be read
+func (c *entryCache) forget(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data.Files, path)
+}
+
+// prune drops directories and files that were not used since the last prune
+func (c *entryCache) prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for dir := range c.data.Dirs {
+		if !c.usedDirs[dir] {
+			delete(c.data.Dirs, dir)
+			c.dirty = true
+		}
+	}
+	for path := range c.data.Files {
+		if !c.usedFiles[path] {
+			delete(c.data.Files, path)
+			c.dirty = true
+		}
+	}
+
+	c.usedDirs = make(map[string]bool)
+	c.usedFiles = make(map[string]bool)
+}
+
+// save writes the cache if it changed. The file is replaced atomically so
+// a crash never leaves a half-written cache behind.
+func (c *entryCache) save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(c.data)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(cacheFile{
+		Version:  cacheVersion,
+		Checksum: checksum(data),
+		Data:     data,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), cacheFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return err
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// checksum returns the hex encoded SHA-256 of data
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
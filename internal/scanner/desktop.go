@@ -3,13 +3,71 @@ package scanner
 import (
 	"bufio"
 	"os"
+	"os/exec"
 	"strings"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
 )
 
-// ParseDesktopFile parses a .desktop file according to freedesktop.org specification
+// DropReason explains why a desktop file did not produce an entry
+type DropReason string
+
+const (
+	DropNotApplication DropReason = "not an application"
+	DropNoDisplay      DropReason = "NoDisplay=true"
+	DropHidden         DropReason = "Hidden=true"
+	DropInvalid        DropReason = "missing Name or Exec"
+	DropTryExec        DropReason = "TryExec binary not found"
+	DropOnlyShowIn     DropReason = "current desktop not in OnlyShowIn"
+	DropNotShowIn      DropReason = "current desktop in NotShowIn"
+	DropShadowed       DropReason = "desktop file ID already provided by a higher-priority directory"
+)
+
+// ParseDesktopFile parses a .desktop file according to freedesktop.org specification.
+// It returns a nil entry for files that should not be shown.
 func ParseDesktopFile(path string) (*entry.Entry, error) {
+	e, _, err := ParseDesktopFileWithReason(path)
+	return e, err
+}
+
+// ParseDesktopFileWithReason parses a .desktop file like ParseDesktopFile and
+// also reports why a file was dropped
+func ParseDesktopFileWithReason(path string) (*entry.Entry, DropReason, error) {
+	parsed, err := parseDesktopFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	e, reason := parsed.result()
+	return e, reason, nil
+}
+
+// desktopFile is a parsed .desktop file before the TryExec check, which
+// depends on what is installed rather than on the file itself
+type desktopFile struct {
+	Entry   *entry.Entry `json:"entry,omitempty"`
+	Reason  DropReason   `json:"reason,omitempty"`
+	TryExec string       `json:"try_exec,omitempty"`
+}
+
+// result returns the entry to show, or why the file is dropped
+func (d *desktopFile) result() (*entry.Entry, DropReason) {
+	if d.Reason != "" {
+		return nil, d.Reason
+	}
+
+	// Skip entries whose program isn't installed
+	if d.TryExec != "" {
+		if _, err := exec.LookPath(d.TryExec); err != nil {
+			return nil, DropTryExec
+		}
+	}
+
+	return d.Entry.Clone(), ""
+}
+
+// parseDesktopFile reads a .desktop file
+func parseDesktopFile(path string) (*desktopFile, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -22,24 +80,35 @@ func ParseDesktopFile(path string) (*entry.Entry, error) {
 	noDisplay := false
 	hidden := false
 	isApplication := false
+	tryExec := ""
+	var onlyShowIn, notShowIn []string
+
+	// Desktop actions, keyed by action ID
+	var actionIDs []string
+	actions := make(map[string]*entry.Action)
+	var currentAction *entry.Action
+
+	// Keep the value best matching the current locale for each key
+	locales := newLocaleMatcher()
+	ranks := make(localizedKeys)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 
-		// Track when we're in the [Desktop Entry] section
-		if line == "[Desktop Entry]" {
-			inDesktopEntry = true
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 
-		// Exit [Desktop Entry] section when we hit another section
+		// Track which section we're in
 		if strings.HasPrefix(line, "[") {
-			inDesktopEntry = false
-			continue
-		}
-
-		// Skip lines outside [Desktop Entry] section, empty lines, and comments
-		if !inDesktopEntry || line == "" || strings.HasPrefix(line, "#") {
+			inDesktopEntry = line == "[Desktop Entry]"
+			currentAction = nil
+			ranks = make(localizedKeys)
+			if id, ok := parseActionGroup(line); ok {
+				currentAction = &entry.Action{ID: id}
+				actions[id] = currentAction
+			}
 			continue
 		}
 
@@ -49,28 +118,57 @@ func ParseDesktopFile(path string) (*entry.Entry, error) {
 			continue
 		}
 
+		// Skip other locales and values less specific than one already seen
+		key, locale := splitLocale(key)
+		rank, ok := locales.rank(locale)
+		if !ok || !ranks.accept(key, rank) {
+			continue
+		}
+
+		if currentAction != nil {
+			parseActionKey(currentAction, key, value)
+			continue
+		}
+
+		// Skip lines outside [Desktop Entry] section
+		if !inDesktopEntry {
+			continue
+		}
+
 		// Extract relevant fields
 		switch key {
 		case "Type":
 			isApplication = (value == "Application")
 		case "Name":
-			e.Name = value
+			e.Name = unescapeValue(value)
 		case "GenericName":
-			e.GenericName = value
+			e.GenericName = unescapeValue(value)
 		case "Comment":
-			e.Comment = value
+			e.Comment = unescapeValue(value)
 		case "Exec":
-			e.Exec = value
+			e.Exec = unescapeValue(value)
 		case "Icon":
-			e.Icon = value
+			e.Icon = unescapeValue(value)
 		case "Terminal":
 			e.Terminal = (value == "true")
 		case "Categories":
 			e.Categories = parseCategories(value)
+		case "Keywords":
+			e.Keywords = parseStringList(value)
+		case "Actions":
+			actionIDs = parseCategories(value)
 		case "NoDisplay":
 			noDisplay = (value == "true")
 		case "Hidden":
 			hidden = (value == "true")
+		case "TryExec":
+			tryExec = unescapeValue(value)
+		case "OnlyShowIn":
+			onlyShowIn = parseCategories(value)
+		case "NotShowIn":
+			notShowIn = parseCategories(value)
+		case "DBusActivatable":
+			e.DBusActivatable = (value == "true")
 		}
 	}
 
@@ -80,20 +178,100 @@ func ParseDesktopFile(path string) (*entry.Entry, error) {
 
 	// Only process Application type entries
 	if !isApplication {
-		return nil, nil
+		return &desktopFile{Reason: DropNotApplication}, nil
 	}
 
 	// Filter out hidden or no-display entries
-	if noDisplay || hidden {
-		return nil, nil
+	if hidden {
+		return &desktopFile{Reason: DropHidden}, nil
+	}
+	if noDisplay {
+		return &desktopFile{Reason: DropNoDisplay}, nil
 	}
 
 	// Validate the entry
 	if err := e.Validate(); err != nil {
-		return nil, nil // Skip invalid entries
+		return &desktopFile{Reason: DropInvalid}, nil // Skip invalid entries
+	}
+
+	// Skip entries meant for other desktop environments
+	if reason := checkShowIn(currentDesktops(), onlyShowIn, notShowIn); reason != "" {
+		return &desktopFile{Reason: reason}, nil
+	}
+
+	// Keep the actions listed in the Actions key, in that order. Actions of
+	// D-Bus activatable applications may leave out Exec.
+	for _, id := range actionIDs {
+		action, ok := actions[id]
+		if !ok || action.Name == "" || action.Exec == "" && !e.DBusActivatable {
+			continue
+		}
+		e.Actions = append(e.Actions, *action)
 	}
 
-	return e, nil
+	return &desktopFile{Entry: e, TryExec: tryExec}, nil
+}
+
+// currentDesktops returns the desktop environments listed in XDG_CURRENT_DESKTOP
+func currentDesktops() []string {
+	var desktops []string
+	for _, desktop := range strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if desktop != "" {
+			desktops = append(desktops, desktop)
+		}
+	}
+	return desktops
+}
+
+// checkShowIn applies the OnlyShowIn and NotShowIn keys to the current desktops
+func checkShowIn(desktops, onlyShowIn, notShowIn []string) DropReason {
+	for _, desktop := range desktops {
+		if containsString(notShowIn, desktop) {
+			return DropNotShowIn
+		}
+	}
+
+	if len(onlyShowIn) == 0 {
+		return ""
+	}
+	for _, desktop := range desktops {
+		if containsString(onlyShowIn, desktop) {
+			return ""
+		}
+	}
+	return DropOnlyShowIn
+}
+
+// containsString checks if a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseActionGroup returns the action ID of a [Desktop Action <ID>] header
+func parseActionGroup(line string) (string, bool) {
+	const prefix = "[Desktop Action "
+	if !strings.HasPrefix(line, prefix) || !strings.HasSuffix(line, "]") {
+		return "", false
+	}
+	id := strings.TrimSpace(line[len(prefix) : len(line)-1])
+	return id, id != ""
+}
+
+// parseActionKey applies a key of a [Desktop Action] group
+func parseActionKey(action *entry.Action, key, value string) {
+	switch key {
+	case "Name":
+		action.Name = unescapeValue(value)
+	case "Exec":
+		action.Exec = unescapeValue(value)
+	case "Icon":
+		action.Icon = unescapeValue(value)
+	}
 }
 
 // parseKeyValue splits a line into key and value
@@ -105,6 +283,74 @@ func parseKeyValue(line string) (key, value string, ok bool) {
 	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
 }
 
+// unescapeValue applies the escape sequences of string values (\s, \n, \t,
+// \r and \\). Unknown sequences are kept as they are, since the Exec key
+// has its own escaping rules on top of these.
+func unescapeValue(value string) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 >= len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		switch value[i+1] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+	}
+	return b.String()
+}
+
+// parseStringList parses a list of localized strings such as Keywords.
+// Elements are separated by unescaped semicolons, \; being a semicolon
+// within an element, and each is unescaped on its own.
+func parseStringList(value string) []string {
+	var list []string
+	var b strings.Builder
+	add := func() {
+		if item := strings.TrimSpace(unescapeValue(b.String())); item != "" {
+			list = append(list, item)
+		}
+		b.Reset()
+	}
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			if value[i+1] == ';' {
+				b.WriteByte(';')
+			} else {
+				// Left for unescapeValue, so \\; ends the element
+				b.WriteString(value[i : i+2])
+			}
+			i++
+		case value[i] == ';':
+			add()
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	add()
+	return list
+}
+
 // parseCategories parses the semicolon-separated categories
 func parseCategories(value string) []string {
 	var categories []string
@@ -0,0 +1,15 @@
+package scanner
+
+import "errors"
+
+var (
+	// ErrCacheVersion indicates the cache was written by another version
+	ErrCacheVersion = errors.New("scanner: cache version mismatch")
+
+	// ErrCacheCorrupt indicates the cache could not be decoded or failed
+	// its checksum
+	ErrCacheCorrupt = errors.New("scanner: cache corrupt")
+
+	// ErrLauncherPanic indicates a game launcher panicked while scanning
+	ErrLauncherPanic = errors.New("scanner: launcher panicked")
+)
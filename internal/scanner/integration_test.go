@@ -1,11 +1,17 @@
 package scanner
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/antoniosarro/gofi/internal/domain/entry"
 	"github.com/antoniosarro/gofi/internal/scanner/launchers"
 	_ "github.com/antoniosarro/gofi/internal/scanner/launchers/heroic"
 )
@@ -278,3 +284,224 @@ Exec=test-app-` + string(rune('a'+i)) + `
 		t.Errorf("Scan() found %v entries, want at least 2", len(entries))
 	}
 }
+
+// stubLauncher returns fixed entries and error after an optional delay
+type stubLauncher struct {
+	name    string
+	delay   atomic.Int64 // nanoseconds
+	entries []*entry.Entry
+	err     error
+}
+
+func (l *stubLauncher) Name() string {
+	return l.name
+}
+
+func (l *stubLauncher) Scan(ctx context.Context) ([]*entry.Entry, error) {
+	select {
+	case <-time.After(time.Duration(l.delay.Load())):
+		return l.entries, l.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestScanLauncherTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+	}()
+
+	fast := &stubLauncher{
+		name:    "stub-fast",
+		entries: []*entry.Entry{{Name: "Fast Game", Exec: "fast", Path: "stub-fast-game"}},
+	}
+	slow := &stubLauncher{
+		name:    "stub-slow",
+		entries: []*entry.Entry{{Name: "Slow Game", Exec: "slow", Path: "stub-slow-game"}},
+	}
+	slow.delay.Store(int64(time.Minute))
+	launchers.Register(fast)
+	launchers.Register(slow)
+	defer func() {
+		launchers.Unregister(fast.Name())
+		launchers.Unregister(slow.Name())
+	}()
+
+	s, err := NewScanner(false, true, WithLauncherTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+
+	start := time.Now()
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Scan() took %v, slow launcher should time out", elapsed)
+	}
+
+	names := make(map[string]bool)
+	for _, e := range s.GetEntries() {
+		names[e.Name] = true
+	}
+	if !names["Fast Game"] {
+		t.Error("Fast Game missing")
+	}
+	if names["Slow Game"] {
+		t.Error("Slow Game should be left out after the timeout")
+	}
+
+	// A launcher timing out during a rescan keeps its entries
+	slow.delay.Store(0)
+	if changes := s.RescanGameLaunchers(); len(changes) != 1 || changes[0].Entry.Name != "Slow Game" {
+		t.Fatalf("RescanGameLaunchers() = %+v, want Slow Game added", changes)
+	}
+	slow.delay.Store(int64(time.Minute))
+	if changes := s.RescanGameLaunchers(); len(changes) != 0 {
+		t.Errorf("RescanGameLaunchers() = %+v, want no changes after a timeout", changes)
+	}
+}
+
+func TestScanLauncherPartialFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+	}()
+
+	// A launcher reading several sources, one of which failed
+	partial := &stubLauncher{
+		name:    "stub-partial",
+		entries: []*entry.Entry{{Name: "Found Game", Exec: "found", Path: "stub-found-game"}},
+		err:     errors.New("second source failed"),
+	}
+	launchers.Register(partial)
+	defer launchers.Unregister(partial.Name())
+
+	s, err := NewScanner(false, true)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	found := false
+	for _, e := range s.GetEntries() {
+		found = found || e.Name == "Found Game"
+	}
+	if !found {
+		t.Error("Found Game missing, entries returned with an error should be kept")
+	}
+
+	// Entries the failing source reported before stay until it recovers
+	partial.entries = nil
+	if changes := s.RescanGameLaunchers(); len(changes) != 0 {
+		t.Errorf("RescanGameLaunchers() = %+v, want no changes while failing", changes)
+	}
+}
+
+// panicLauncher panics while scanning, as a launcher with a parser bug
+type panicLauncher struct{}
+
+func (l *panicLauncher) Name() string {
+	return "stub-panic"
+}
+
+func (l *panicLauncher) Scan(ctx context.Context) ([]*entry.Entry, error) {
+	var entries []*entry.Entry
+	return []*entry.Entry{entries[1]}, nil
+}
+
+func TestScanLauncherPanic(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+	}()
+
+	s, err := NewScanner(false, true)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+
+	entries, ok := s.scanLauncher(&panicLauncher{})
+	if ok || entries != nil {
+		t.Errorf("scanLauncher() = %v, %v, want no entries and failure", entries, ok)
+	}
+}
+
+func TestLoadGameLaunchers(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+	}()
+
+	fast := &stubLauncher{
+		name:    "stub-fast",
+		entries: []*entry.Entry{{Name: "Fast Game", Exec: "fast", Path: "stub-fast-game"}},
+	}
+	slow := &stubLauncher{
+		name:    "stub-slow",
+		entries: []*entry.Entry{{Name: "Slow Game", Exec: "slow", Path: "stub-slow-game"}},
+	}
+	slow.delay.Store(int64(300 * time.Millisecond))
+	launchers.Register(fast)
+	launchers.Register(slow)
+	defer func() {
+		launchers.Unregister(fast.Name())
+		launchers.Unregister(slow.Name())
+	}()
+
+	s, err := NewScanner(false, true, WithLauncherTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+
+	// Applications are listed without waiting on the launchers
+	start := time.Now()
+	if err := s.ScanApplications(); err != nil {
+		t.Fatalf("ScanApplications() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("ScanApplications() took %v, should not wait on launchers", elapsed)
+	}
+	for _, e := range s.GetEntries() {
+		if e.Name == "Fast Game" || e.Name == "Slow Game" {
+			t.Fatalf("ScanApplications() listed %s", e.Name)
+		}
+	}
+
+	// Each launcher's games are added as it finishes, even past the timeout
+	var added []string
+	s.LoadGameLaunchers(context.Background(), func(changes []Change) {
+		for _, change := range changes {
+			if change.Type != ChangeAdded {
+				t.Errorf("LoadGameLaunchers() change = %v, want added", change.Type)
+			}
+			added = append(added, change.Entry.Name)
+		}
+	})
+	if want := []string{"Fast Game", "Slow Game"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("LoadGameLaunchers() added %v, want %v", added, want)
+	}
+	if results := s.Filter("slow game", entry.AppTypeAll); len(results) == 0 || results[0].Name != "Slow Game" {
+		t.Errorf("Filter(slow game) = %v, want Slow Game indexed", results)
+	}
+
+	// Games known already aren't added twice
+	s.LoadGameLaunchers(context.Background(), func(changes []Change) {
+		t.Errorf("LoadGameLaunchers() again = %+v, want no changes", changes)
+	})
+}
@@ -0,0 +1,231 @@
+package bottles
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers/yaml"
+)
+
+func init() {
+	// Auto-register the Bottles launcher
+	launchers.Register(&Launcher{})
+}
+
+// Launcher implements the GameLauncher interface for Bottles. Bottles runs
+// any Windows program, not only games; its entries are classified as Wine
+// programs rather than games.
+type Launcher struct{}
+
+// Name returns the launcher identifier
+func (l *Launcher) Name() string {
+	return "bottles"
+}
+
+// WatchPaths returns the bottles directories and each bottle, whose
+// bottle.yml lists the programs added by hand
+func (l *Launcher) WatchPaths() []string {
+	var paths []string
+	for _, inst := range installations() {
+		paths = append(paths, inst.dir)
+		dirs, _ := os.ReadDir(inst.dir)
+		for _, dir := range dirs {
+			if dir.IsDir() {
+				paths = append(paths, filepath.Join(inst.dir, dir.Name()))
+			}
+		}
+	}
+	return paths
+}
+
+// Scan discovers the programs of every bottle from native and Flatpak
+// Bottles
+func (l *Launcher) Scan(ctx context.Context) ([]*entry.Entry, error) {
+	if os.Getenv("HOME") == "" {
+		return nil, fmt.Errorf("HOME environment variable not set")
+	}
+
+	entries := make([]*entry.Entry, 0)
+
+	for _, inst := range installations() {
+		dirs, err := os.ReadDir(inst.dir)
+		if err != nil {
+			return nil, fmt.Errorf("reading bottles: %w", err)
+		}
+
+		for _, dir := range dirs {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if !dir.IsDir() {
+				continue
+			}
+
+			bottle, err := readBottle(filepath.Join(inst.dir, dir.Name()))
+			if os.IsNotExist(err) {
+				continue // Not a bottle
+			}
+			if err != nil {
+				// A broken bottle shouldn't hide the others
+				if os.Getenv("DEBUG") == "1" {
+					fmt.Printf("Error reading bottle %s: %v\n", dir.Name(), err)
+				}
+				continue
+			}
+
+			for _, program := range bottle.Programs {
+				entries = append(entries, programToEntry(bottle, program, inst))
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// installations returns the Bottles data directories that exist, native
+// first
+func installations() []installation {
+	homeDir := os.Getenv("HOME")
+	candidates := []installation{
+		{dir: filepath.Join(homeDir, ".local", "share", "bottles", "bottles")},
+		{dir: filepath.Join(homeDir, ".var", "app", flatpakID, "data", "bottles", "bottles"), flatpak: true},
+	}
+
+	var result []installation
+	seen := make(map[string]bool)
+	for _, inst := range candidates {
+		resolved, err := filepath.EvalSymlinks(inst.dir)
+		if err != nil || seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		result = append(result, inst)
+	}
+	return result
+}
+
+// readBottle reads a bottle's config and lists its programs: those added
+// by hand, then the start menu shortcuts of its prefix
+func readBottle(dir string) (*Bottle, error) {
+	config, err := yaml.ParseFile(filepath.Join(dir, "bottle.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	bottle := &Bottle{
+		Name: config["Name"],
+		Dir:  filepath.Base(dir),
+	}
+	if bottle.Name == "" {
+		bottle.Name = bottle.Dir
+	}
+
+	seen := make(map[string]bool)
+	for _, program := range externalProgramsOf(config) {
+		seen[strings.ToLower(program.Name)] = true
+		bottle.Programs = append(bottle.Programs, program)
+	}
+	for _, program := range shortcuts(filepath.Join(dir, "drive_c")) {
+		if !seen[strings.ToLower(program.Name)] {
+			seen[strings.ToLower(program.Name)] = true
+			bottle.Programs = append(bottle.Programs, program)
+		}
+	}
+
+	return bottle, nil
+}
+
+// externalProgramsOf returns the programs added by hand, sorted by name.
+// Programs removed in Bottles stay in the config, flagged as removed.
+func externalProgramsOf(config map[string]string) []Program {
+	byID := make(map[string]map[string]string)
+	for key, value := range config {
+		rest, ok := strings.CutPrefix(key, externalPrograms+".")
+		if !ok {
+			continue
+		}
+		id, field, ok := strings.Cut(rest, ".")
+		if !ok {
+			continue
+		}
+		if byID[id] == nil {
+			byID[id] = make(map[string]string)
+		}
+		byID[id][field] = value
+	}
+
+	var programs []Program
+	for id, fields := range byID {
+		if fields["name"] == "" || fields["removed"] == "true" {
+			continue
+		}
+		programs = append(programs, Program{ID: id, Name: fields["name"], Path: fields["path"]})
+	}
+
+	// Map order is random, keep entries stable between scans
+	sort.Slice(programs, func(i, j int) bool {
+		if programs[i].Name != programs[j].Name {
+			return programs[i].Name < programs[j].Name
+		}
+		return programs[i].ID < programs[j].ID
+	})
+	return programs
+}
+
+// shortcuts returns the start menu shortcuts of a prefix as programs named
+// after the shortcut, which is how Bottles names them. Uninstallers are
+// left out.
+func shortcuts(driveC string) []Program {
+	var programs []Program
+	for _, pattern := range startMenuDirs {
+		dirs, _ := filepath.Glob(filepath.Join(driveC, pattern))
+		for _, dir := range dirs {
+			filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".lnk") {
+					return nil
+				}
+				name := strings.TrimSuffix(d.Name(), filepath.Ext(path))
+				if strings.Contains(strings.ToLower(name), "uninstall") {
+					return nil
+				}
+				programs = append(programs, Program{ID: name, Name: name, Path: path})
+				return nil
+			})
+		}
+	}
+	return programs
+}
+
+// programToEntry converts a program of a bottle to an Entry
+func programToEntry(bottle *Bottle, program Program, inst installation) *entry.Entry {
+	args := fmt.Sprintf("run -b %s -p %s", entry.QuoteExecArg(bottle.Name), entry.QuoteExecArg(program.Name))
+	exec := "bottles-cli " + args
+	path := fmt.Sprintf("bottles-%s-%s", bottle.Dir, program.ID) // Unique identifier
+	if inst.flatpak {
+		exec = fmt.Sprintf("flatpak run --command=bottles-cli %s %s", flatpakID, args)
+		// Bottles are per install. The path must not mention "flatpak", which
+		// would classify the program as a Flatpak app.
+		path = fmt.Sprintf("bottles-sandbox-%s-%s", bottle.Dir, program.ID)
+	}
+
+	comment := fmt.Sprintf("Bottles Program: %s", bottle.Name)
+	if program.Path != "" {
+		comment = fmt.Sprintf("Bottles Program: %s (%s)", bottle.Name, filepath.Base(program.Path))
+	}
+
+	return &entry.Entry{
+		Name:     program.Name,
+		Comment:  comment,
+		Exec:     exec,
+		Icon:     flatpakID, // Both installs ship the icon under the app ID
+		Terminal: false,
+		Path:     path,
+	}
+}
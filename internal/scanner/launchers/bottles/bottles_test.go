@@ -0,0 +1,137 @@
+package bottles
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+const gamingBottle = `Arch: win64
+External_Programs:
+  9b3c6f4e-0d5a-4c0e-8a53-1b2f3c4d5e6f:
+    arguments: ''
+    executable: Battle.net Launcher.exe
+    folder: /home/user/Games/Battle.net
+    id: 9b3c6f4e-0d5a-4c0e-8a53-1b2f3c4d5e6f
+    name: Battle.net
+    path: /home/user/Games/Battle.net/Battle.net Launcher.exe
+  1f0e2d3c-4b5a-6978-8a9b-0c1d2e3f4a5b:
+    executable: old.exe
+    id: 1f0e2d3c-4b5a-6978-8a9b-0c1d2e3f4a5b
+    name: Old Tool
+    path: /home/user/old.exe
+    removed: true
+Name: My Games
+Runner: soda-7.0-9
+`
+
+func TestLauncherName(t *testing.T) {
+	l := &Launcher{}
+	if l.Name() != "bottles" {
+		t.Errorf("Name() = %v, want %v", l.Name(), "bottles")
+	}
+}
+
+func TestScanNoBottles(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Errorf("Scan() error = %v, want nil", err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("Scan() returned entries when Bottles isn't installed")
+	}
+}
+
+func TestScan(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	bottlesDir := filepath.Join(tmpDir, ".local", "share", "bottles", "bottles")
+	writeFile(t, filepath.Join(bottlesDir, "My-Games", "bottle.yml"), gamingBottle)
+
+	// Start menu shortcuts, one duplicating an external program
+	startMenu := filepath.Join(bottlesDir, "My-Games", "drive_c", "users", "user", "AppData", "Roaming", "Microsoft", "Windows", "Start Menu", "Programs")
+	writeFile(t, filepath.Join(startMenu, "Notepad++", "Notepad++.lnk"), "")
+	writeFile(t, filepath.Join(startMenu, "Notepad++", "Uninstall Notepad++.lnk"), "")
+	writeFile(t, filepath.Join(startMenu, "Battle.net.lnk"), "")
+
+	// Directories without a bottle.yml are ignored
+	os.MkdirAll(filepath.Join(bottlesDir, "templates"), 0755)
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Scan() returned %d entries, want 2", len(entries))
+	}
+
+	battlenet, notepad := entries[0], entries[1]
+
+	if battlenet.Name != "Battle.net" {
+		t.Errorf("Name = %q, want Battle.net", battlenet.Name)
+	}
+	if want := `bottles-cli run -b "My Games" -p Battle.net`; battlenet.Exec != want {
+		t.Errorf("Exec = %q, want %q", battlenet.Exec, want)
+	}
+	if want := "bottles-My-Games-9b3c6f4e-0d5a-4c0e-8a53-1b2f3c4d5e6f"; battlenet.Path != want {
+		t.Errorf("Path = %q, want %q", battlenet.Path, want)
+	}
+	if want := "Bottles Program: My Games (Battle.net Launcher.exe)"; battlenet.Comment != want {
+		t.Errorf("Comment = %q, want %q", battlenet.Comment, want)
+	}
+
+	if want := `bottles-cli run -b "My Games" -p Notepad++`; notepad.Exec != want {
+		t.Errorf("Exec = %q, want %q", notepad.Exec, want)
+	}
+
+	for _, e := range entries {
+		if e.GetAppType() != entry.AppTypeWine {
+			t.Errorf("%s: GetAppType() = %v, want %v", e.Name, e.GetAppType(), entry.AppTypeWine)
+		}
+	}
+}
+
+func TestScanFlatpak(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	bottlesDir := filepath.Join(tmpDir, ".var", "app", flatpakID, "data", "bottles", "bottles")
+	writeFile(t, filepath.Join(bottlesDir, "My-Games", "bottle.yml"), gamingBottle)
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Scan() returned %d entries, want 1", len(entries))
+	}
+
+	e := entries[0]
+	if want := `flatpak run --command=bottles-cli com.usebottles.bottles run -b "My Games" -p Battle.net`; e.Exec != want {
+		t.Errorf("Exec = %q, want %q", e.Exec, want)
+	}
+	if e.GetAppType() != entry.AppTypeWine {
+		t.Errorf("GetAppType() = %v, want %v", e.GetAppType(), entry.AppTypeWine)
+	}
+}
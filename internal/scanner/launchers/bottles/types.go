@@ -0,0 +1,36 @@
+package bottles
+
+// flatpakID is the Flatpak application ID of Bottles
+const flatpakID = "com.usebottles.bottles"
+
+// externalPrograms is the bottle.yml mapping of programs added by hand,
+// keyed by program ID
+const externalPrograms = "External_Programs"
+
+// startMenuDirs are the directories of a prefix, relative to drive_c,
+// holding the shortcuts Bottles lists as programs. The user directory is
+// matched as a glob since it's named after the user that created the prefix.
+var startMenuDirs = []string{
+	"ProgramData/Microsoft/Windows/Start Menu/Programs",
+	"users/*/AppData/Roaming/Microsoft/Windows/Start Menu/Programs",
+}
+
+// Bottle is the part of a bottle.yml we use
+type Bottle struct {
+	Name     string
+	Dir      string
+	Programs []Program
+}
+
+// Program is a program Bottles can run in a bottle
+type Program struct {
+	ID   string
+	Name string
+	Path string // Windows executable or shortcut
+}
+
+// installation is a Bottles data directory and how to run its client
+type installation struct {
+	dir     string // Holds one directory per bottle
+	flatpak bool
+}
@@ -1,10 +1,18 @@
 package heroic
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
 	"github.com/antoniosarro/gofi/internal/scanner/launchers"
@@ -16,50 +24,233 @@ func init() {
 }
 
 // Launcher implements the GameLauncher interface for Heroic Games Launcher
-type Launcher struct {
-	homeDir string
-}
+type Launcher struct{}
 
 // Name returns the launcher identifier
 func (l *Launcher) Name() string {
 	return "heroic"
 }
 
-// Scan discovers games from Heroic Games Launcher
-func (l *Launcher) Scan() ([]*entry.Entry, error) {
-	l.homeDir = os.Getenv("HOME")
-	if l.homeDir == "" {
+// Runners whose games Heroic can launch
+const (
+	runnerSideload  = "sideload"
+	runnerLegendary = "legendary"
+	runnerGOG       = "gog"
+)
+
+// stores names the store the games of each runner come from
+var stores = map[string]string{
+	runnerLegendary: "epic",
+	runnerGOG:       "gog",
+}
+
+// WatchPaths returns the library files and the per-game configuration
+// directory the entries are read from
+func (l *Launcher) WatchPaths() []string {
+	heroicConfigDir := filepath.Join(os.Getenv("HOME"), ".config", "heroic")
+	return []string{
+		filepath.Join(heroicConfigDir, "sideload_apps", "library.json"),
+		filepath.Join(heroicConfigDir, "legendaryConfig", "legendary", "installed.json"),
+		filepath.Join(heroicConfigDir, "gog_store"),
+		filepath.Join(heroicConfigDir, "store_cache"),
+		filepath.Join(heroicConfigDir, "GamesConfig"),
+	}
+}
+
+// Scan discovers sideloaded, Epic (Legendary) and GOG games from Heroic
+// Games Launcher. Libraries that can't be read are skipped, an error is only
+// returned if none could.
+func (l *Launcher) Scan(ctx context.Context) ([]*entry.Entry, error) {
+	homeDir := os.Getenv("HOME")
+	if homeDir == "" {
 		return nil, fmt.Errorf("HOME environment variable not set")
 	}
 
-	heroicConfigDir := filepath.Join(l.homeDir, ".config", "heroic")
+	heroicConfigDir := filepath.Join(homeDir, ".config", "heroic")
+
+	// Check if Heroic is installed
+	if _, err := os.Stat(heroicConfigDir); os.IsNotExist(err) {
+		// Not an error - Heroic may not be installed
+		return nil, nil
+	}
+
+	sources := []struct {
+		runner string
+		scan   func(string) ([]Game, error)
+	}{
+		{runnerSideload, l.sideloadGames},
+		{runnerLegendary, l.legendaryGames},
+		{runnerGOG, l.gogGames},
+	}
+
+	stats := l.loadPlayStats(heroicConfigDir)
+
+	// Convert games to entries. A broken library only loses its own games.
+	entries := make([]*entry.Entry, 0)
+	var errs []error
+	for _, source := range sources {
+		games, err := source.scan(heroicConfigDir)
+		if err != nil {
+			err = fmt.Errorf("parsing heroic %s library: %w", source.runner, err)
+			if os.Getenv("DEBUG") == "1" {
+				fmt.Printf("Error scanning heroic: %v\n", err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, game := range games {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			e := l.gameToEntry(game, heroicConfigDir)
+			addPlayStats(e, stats[game.AppName])
+			entries = append(entries, e)
+		}
+	}
+
+	// Failures only count when no library could be read
+	if len(entries) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return entries, nil
+}
+
+// sideloadGames returns the installed sideloaded games
+func (l *Launcher) sideloadGames(heroicConfigDir string) ([]Game, error) {
 	libraryPath := filepath.Join(heroicConfigDir, "sideload_apps", "library.json")
 
 	// Check if library exists
 	if _, err := os.Stat(libraryPath); os.IsNotExist(err) {
-		// Not an error - Heroic may not be installed
 		return nil, nil
 	}
 
 	// Parse library
 	library, err := l.parseLibrary(libraryPath)
 	if err != nil {
-		return nil, fmt.Errorf("parsing heroic library: %w", err)
+		return nil, err
 	}
 
-	// Convert games to entries
-	entries := make([]*entry.Entry, 0)
+	var games []Game
 	for _, game := range library.Games {
 		// Only process installed sideloaded games
-		if !game.IsInstalled || game.Runner != "sideload" {
+		if !game.IsInstalled || game.Runner != runnerSideload {
 			continue
 		}
+		games = append(games, game)
+	}
+
+	return games, nil
+}
 
-		e := l.gameToEntry(game, heroicConfigDir)
-		entries = append(entries, e)
+// legendaryGames returns the installed Epic games from Legendary's
+// installed.json, completed with the library cache
+func (l *Launcher) legendaryGames(heroicConfigDir string) ([]Game, error) {
+	var installed map[string]LegendaryGame
+	path := filepath.Join(heroicConfigDir, "legendaryConfig", "legendary", "installed.json")
+	if found, err := readJSON(path, &installed); !found || err != nil {
+		return nil, err
 	}
 
-	return entries, nil
+	library := l.loadStoreCache(heroicConfigDir, runnerLegendary)
+
+	games := make([]Game, 0, len(installed))
+	for appName, installedGame := range installed {
+		if installedGame.IsDLC {
+			continue
+		}
+		game := library[appName]
+		game.Runner = runnerLegendary
+		game.AppName = appName
+		game.IsInstalled = true
+		if installedGame.Title != "" {
+			game.Title = installedGame.Title
+		}
+		game.FolderName = filepath.Base(installedGame.InstallPath)
+		games = append(games, game)
+	}
+
+	// Map order is random, keep entries stable between scans
+	sortGames(games)
+	return games, nil
+}
+
+// gogGames returns the installed GOG games from gog_store/installed.json,
+// with titles from the GOG library
+func (l *Launcher) gogGames(heroicConfigDir string) ([]Game, error) {
+	var installed GOGInstalled
+	path := filepath.Join(heroicConfigDir, "gog_store", "installed.json")
+	if found, err := readJSON(path, &installed); !found || err != nil {
+		return nil, err
+	}
+
+	library := l.loadStoreCache(heroicConfigDir, runnerGOG)
+
+	var games []Game
+	for _, installedGame := range installed.Installed {
+		if installedGame.IsDLC {
+			continue
+		}
+		game := library[installedGame.AppName]
+		game.Runner = runnerGOG
+		game.AppName = installedGame.AppName
+		game.IsInstalled = true
+		game.FolderName = filepath.Base(installedGame.InstallPath)
+		if game.Title == "" {
+			game.Title = game.FolderName
+		}
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
+// loadStoreCache returns the games Heroic knows about for a store, keyed by
+// app name. Newer versions keep them in store_cache, older ones in the
+// store's own directory. A missing or broken cache only costs titles and art.
+func (l *Launcher) loadStoreCache(heroicConfigDir, runner string) map[string]Game {
+	paths := []string{filepath.Join(heroicConfigDir, "store_cache", runner+"_library.json")}
+	if runner == runnerGOG {
+		paths = append(paths, filepath.Join(heroicConfigDir, "gog_store", "library.json"))
+	}
+
+	games := make(map[string]Game)
+	for _, path := range paths {
+		var cache StoreCache
+		if found, err := readJSON(path, &cache); !found || err != nil {
+			continue
+		}
+		for _, game := range append(cache.Library, cache.Games...) {
+			if _, ok := games[game.AppName]; !ok && game.AppName != "" {
+				games[game.AppName] = game
+			}
+		}
+	}
+
+	return games
+}
+
+// readJSON decodes a JSON file, reporting false if it doesn't exist
+func readJSON(path string, v any) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+// sortGames sorts games by title, then app name
+func sortGames(games []Game) {
+	sort.Slice(games, func(i, j int) bool {
+		if games[i].Title != games[j].Title {
+			return games[i].Title < games[j].Title
+		}
+		return games[i].AppName < games[j].AppName
+	})
 }
 
 // parseLibrary reads and parses the Heroic library.json
@@ -88,17 +279,82 @@ func (l *Launcher) gameToEntry(game Game, heroicConfigDir string) *entry.Entry {
 	}
 
 	// Build the heroic:// URI to launch the game
-	heroicURI := fmt.Sprintf("heroic://launch/sideload/%s", game.AppName)
+	heroicURI := fmt.Sprintf("heroic://launch/%s/%s", game.Runner, game.AppName)
 
-	return &entry.Entry{
+	e := &entry.Entry{
 		Name:       game.Title,
 		Comment:    fmt.Sprintf("Heroic Game: %s", game.FolderName),
 		Exec:       fmt.Sprintf("xdg-open %s", heroicURI),
 		Icon:       "applications-games", // Generic game icon
+		Image:      l.findArtwork(game, heroicConfigDir),
 		Terminal:   false,
 		Categories: categories,
-		Path:       fmt.Sprintf("heroic-sideload-%s", game.AppName), // Unique identifier
+		Path:       fmt.Sprintf("heroic-%s-%s", game.Runner, game.AppName), // Unique identifier
+	}
+	e.SetMeta(entry.MetaStore, stores[game.Runner])
+	e.SetMeta(entry.MetaRunner, game.Runner)
+
+	return e
+}
+
+// loadPlayStats reads the play statistics Heroic keeps for every game,
+// keyed by app name. Games that were never played have none.
+func (l *Launcher) loadPlayStats(heroicConfigDir string) map[string]PlayStats {
+	var stats map[string]PlayStats
+	path := filepath.Join(heroicConfigDir, "store", "timestamp.json")
+	if _, err := readJSON(path, &stats); err != nil && os.Getenv("DEBUG") == "1" {
+		fmt.Printf("Error reading heroic play statistics: %v\n", err)
+	}
+	return stats
+}
+
+// addPlayStats records the play statistics of a game in its entry
+func addPlayStats(e *entry.Entry, stats PlayStats) {
+	if lastPlayed, err := time.Parse(time.RFC3339, stats.LastPlayed); err == nil {
+		e.SetLastPlayed(lastPlayed)
+	}
+	e.SetPlaytime(time.Duration(stats.TotalPlayed * float64(time.Minute)))
+}
+
+// findArtwork returns a local copy of the square art of a game, then of
+// its cover. Without one it returns the art URL, which is never fetched.
+func (l *Launcher) findArtwork(game Game, heroicConfigDir string) string {
+	for _, art := range []string{game.ArtSquare, game.ArtCover} {
+		if path := cachedImage(art, heroicConfigDir); path != "" {
+			return path
+		}
+	}
+	if game.ArtSquare != "" {
+		return game.ArtSquare
+	}
+	return game.ArtCover
+}
+
+// cachedImage returns the local file of an art URL. Heroic keeps the images
+// it downloaded in images-cache, named after the SHA-256 of their URL;
+// sideloaded games may point to local files directly.
+func cachedImage(art, heroicConfigDir string) string {
+	var path string
+	switch {
+	case art == "":
+		return ""
+	case filepath.IsAbs(art):
+		path = art
+	case strings.HasPrefix(art, "file://"):
+		u, err := url.Parse(art)
+		if err != nil {
+			return ""
+		}
+		path = u.Path
+	default:
+		sum := sha256.Sum256([]byte(art))
+		path = filepath.Join(heroicConfigDir, "images-cache", hex.EncodeToString(sum[:]))
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return ""
 	}
+	return path
 }
 
 // loadGameCategories loads categories from the GamesConfig file
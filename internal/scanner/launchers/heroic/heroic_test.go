@@ -1,10 +1,16 @@
 package heroic
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
 )
 
 func TestLauncherName(t *testing.T) {
@@ -21,7 +27,7 @@ func TestScanNoLibrary(t *testing.T) {
 	defer os.Unsetenv("HOME")
 
 	l := &Launcher{}
-	entries, err := l.Scan()
+	entries, err := l.Scan(context.Background())
 
 	if err != nil {
 		t.Errorf("Scan() error = %v, want nil", err)
@@ -65,7 +71,7 @@ func TestScanWithLibrary(t *testing.T) {
 	os.WriteFile(libraryPath, libraryData, 0644)
 
 	l := &Launcher{}
-	entries, err := l.Scan()
+	entries, err := l.Scan(context.Background())
 
 	if err != nil {
 		t.Fatalf("Scan() error = %v", err)
@@ -201,3 +207,216 @@ func TestGameToEntry(t *testing.T) {
 		t.Errorf("Entry.Categories = %v, want [Game]", entry.Categories)
 	}
 }
+
+func TestScanLegendaryAndGOG(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	heroicDir := filepath.Join(tmpDir, ".config", "heroic")
+	write := func(path string, v interface{}) {
+		data, _ := json.MarshalIndent(v, "", "  ")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, data, 0644)
+	}
+
+	// Legendary: installed.json plus library cache
+	write(filepath.Join(heroicDir, "legendaryConfig", "legendary", "installed.json"), map[string]interface{}{
+		"Fortnite": map[string]interface{}{
+			"app_name":     "Fortnite",
+			"title":        "Fortnite",
+			"install_path": "/games/Fortnite",
+		},
+		"Fortnite-DLC": map[string]interface{}{
+			"app_name": "Fortnite-DLC",
+			"title":    "Some DLC",
+			"is_dlc":   true,
+		},
+	})
+	write(filepath.Join(heroicDir, "store_cache", "legendary_library.json"), map[string]interface{}{
+		"library": []map[string]interface{}{
+			{"app_name": "Fortnite", "title": "Fortnite (cached)", "art_cover": "https://example.com/cover.jpg"},
+		},
+	})
+
+	// GOG: installed.json with titles from library.json
+	write(filepath.Join(heroicDir, "gog_store", "installed.json"), map[string]interface{}{
+		"installed": []map[string]interface{}{
+			{"appName": "1207658924", "install_path": "/games/Witcher"},
+			{"appName": "1111111111", "install_path": "/games/Unknown Game"},
+		},
+	})
+	write(filepath.Join(heroicDir, "gog_store", "library.json"), map[string]interface{}{
+		"games": []map[string]interface{}{
+			{"app_name": "1207658924", "title": "The Witcher: Enhanced Edition"},
+		},
+	})
+
+	// Categories from GamesConfig apply to every store
+	write(filepath.Join(heroicDir, "GamesConfig", "1207658924.json"), map[string]interface{}{
+		"1207658924": map[string]interface{}{"categories": []string{"RPG"}},
+	})
+
+	// Play statistics are kept for every store
+	write(filepath.Join(heroicDir, "store", "timestamp.json"), map[string]interface{}{
+		"1207658924": map[string]interface{}{
+			"firstPlayed": "2023-11-01T18:00:00.000Z",
+			"lastPlayed":  "2023-11-14T22:13:20.000Z",
+			"totalPlayed": 754,
+		},
+	})
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	tests := []struct {
+		path       string
+		name       string
+		exec       string
+		categories []string
+		store      string
+		lastPlayed time.Time
+		playtime   time.Duration
+	}{
+		{"heroic-legendary-Fortnite", "Fortnite", "xdg-open heroic://launch/legendary/Fortnite", []string{"Game"}, "epic", time.Time{}, 0},
+		{"heroic-gog-1207658924", "The Witcher: Enhanced Edition", "xdg-open heroic://launch/gog/1207658924", []string{"RPG"}, "gog", time.Unix(1700000000, 0), 754 * time.Minute},
+		{"heroic-gog-1111111111", "Unknown Game", "xdg-open heroic://launch/gog/1111111111", []string{"Game"}, "gog", time.Time{}, 0},
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("Scan() returned %d entries, want %d", len(entries), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := entries[i]
+			if e.Path != tt.path {
+				t.Errorf("Path = %v, want %v", e.Path, tt.path)
+			}
+			if e.Name != tt.name {
+				t.Errorf("Name = %v, want %v", e.Name, tt.name)
+			}
+			if e.Exec != tt.exec {
+				t.Errorf("Exec = %v, want %v", e.Exec, tt.exec)
+			}
+			if len(e.Categories) != len(tt.categories) || e.Categories[0] != tt.categories[0] {
+				t.Errorf("Categories = %v, want %v", e.Categories, tt.categories)
+			}
+			if e.Meta(entry.MetaStore) != tt.store {
+				t.Errorf("Meta(MetaStore) = %q, want %q", e.Meta(entry.MetaStore), tt.store)
+			}
+			if !e.LastPlayed().Equal(tt.lastPlayed) {
+				t.Errorf("LastPlayed() = %v, want %v", e.LastPlayed(), tt.lastPlayed)
+			}
+			if e.Playtime() != tt.playtime {
+				t.Errorf("Playtime() = %v, want %v", e.Playtime(), tt.playtime)
+			}
+		})
+	}
+}
+
+func TestFindArtwork(t *testing.T) {
+	heroicDir := t.TempDir()
+
+	// Images Heroic downloaded are named after the SHA-256 of their URL
+	cache := func(url string) string {
+		sum := sha256.Sum256([]byte(url))
+		path := filepath.Join(heroicDir, "images-cache", hex.EncodeToString(sum[:]))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("png"), 0644)
+		return path
+	}
+	squarePath := cache("https://example.com/square.png")
+	coverPath := cache("https://example.com/cover.jpg")
+
+	localPath := filepath.Join(heroicDir, "sideload.png")
+	os.WriteFile(localPath, []byte("png"), 0644)
+
+	tests := []struct {
+		name string
+		game Game
+		want string
+	}{
+		{
+			name: "Cached square art",
+			game: Game{ArtSquare: "https://example.com/square.png", ArtCover: "https://example.com/cover.jpg"},
+			want: squarePath,
+		},
+		{
+			name: "Cached cover when square art is missing",
+			game: Game{ArtSquare: "https://example.com/other.png", ArtCover: "https://example.com/cover.jpg"},
+			want: coverPath,
+		},
+		{
+			name: "URL when nothing is cached",
+			game: Game{ArtSquare: "https://example.com/other.png"},
+			want: "https://example.com/other.png",
+		},
+		{
+			name: "Local file",
+			game: Game{ArtCover: localPath},
+			want: localPath,
+		},
+		{
+			name: "File URL",
+			game: Game{ArtCover: "file://" + localPath},
+			want: localPath,
+		},
+		{
+			name: "No art",
+			game: Game{},
+			want: "",
+		},
+	}
+
+	l := &Launcher{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l.findArtwork(tt.game, heroicDir); got != tt.want {
+				t.Errorf("findArtwork() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanBrokenLibrary(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	path := filepath.Join(tmpDir, ".config", "heroic", "gog_store", "installed.json")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("{"), 0644)
+
+	l := &Launcher{}
+	if _, err := l.Scan(context.Background()); err == nil {
+		t.Error("Scan() error = nil, want error for a broken installed.json")
+	}
+}
+
+func TestScanPartlyBrokenLibrary(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	heroicDir := filepath.Join(tmpDir, ".config", "heroic")
+	write := func(path, content string) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	write(filepath.Join(heroicDir, "gog_store", "installed.json"), "{")
+	write(filepath.Join(heroicDir, "legendaryConfig", "legendary", "installed.json"),
+		`{"Fortnite": {"app_name": "Fortnite", "title": "Fortnite", "install_path": "/games/Fortnite"}}`)
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Errorf("Scan() error = %v, want nil while a library could be read", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "Fortnite" {
+		t.Errorf("Scan() = %v, want the Epic game despite the broken GOG library", entries)
+	}
+}
@@ -20,3 +20,37 @@ type Game struct {
 type GameConfig struct {
 	Categories []string `json:"categories"`
 }
+
+// StoreCache represents Heroic's store_cache/<store>_library.json files
+type StoreCache struct {
+	Library []Game `json:"library"`
+	Games   []Game `json:"games"`
+}
+
+// LegendaryGame represents a game in Legendary's installed.json, which maps
+// app names to installed games
+type LegendaryGame struct {
+	AppName     string `json:"app_name"`
+	Title       string `json:"title"`
+	InstallPath string `json:"install_path"`
+	IsDLC       bool   `json:"is_dlc"`
+}
+
+// GOGInstalled represents Heroic's gog_store/installed.json
+type GOGInstalled struct {
+	Installed []GOGGame `json:"installed"`
+}
+
+// GOGGame represents a game in gog_store/installed.json
+type GOGGame struct {
+	AppName     string `json:"appName"`
+	InstallPath string `json:"install_path"`
+	IsDLC       bool   `json:"is_dlc"`
+}
+
+// PlayStats represents a game in Heroic's store/timestamp.json, which maps
+// app names to the time spent playing them
+type PlayStats struct {
+	LastPlayed  string  `json:"lastPlayed"`  // ISO 8601 time
+	TotalPlayed float64 `json:"totalPlayed"` // Minutes
+}
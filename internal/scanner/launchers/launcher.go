@@ -1,12 +1,27 @@
 package launchers
 
-import "github.com/antoniosarro/gofi/internal/domain/entry"
+import (
+	"context"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+)
 
 // GameLauncher represents a game launcher integration
 type GameLauncher interface {
 	// Name returns the launcher's identifier
 	Name() string
 
-	// Scan discovers and returns game entries from this launcher
-	Scan() ([]*entry.Entry, error)
+	// Scan discovers and returns game entries from this launcher.
+	// Implementations should stop early once ctx is done, and must allow
+	// concurrent calls as a timed out scan may still be running. Entries
+	// returned along with an error are kept, so launchers reading several
+	// sources can report the ones that failed without losing the others.
+	Scan(ctx context.Context) ([]*entry.Entry, error)
+}
+
+// Watchable is implemented by launchers that can tell which files and
+// directories their entries come from, so they can be rescanned on change
+type Watchable interface {
+	// WatchPaths returns the paths to watch, which may not exist yet
+	WatchPaths() []string
 }
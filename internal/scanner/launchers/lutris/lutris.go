@@ -0,0 +1,338 @@
+package lutris
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers/lutris/sqlite"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers/yaml"
+)
+
+func init() {
+	// Auto-register the Lutris launcher
+	launchers.Register(&Launcher{})
+}
+
+// Launcher implements the GameLauncher interface for Lutris
+type Launcher struct{}
+
+// Name returns the launcher identifier
+func (l *Launcher) Name() string {
+	return "lutris"
+}
+
+// WatchPaths returns the game databases and the game config directories
+func (l *Launcher) WatchPaths() []string {
+	var paths []string
+	for _, inst := range installations() {
+		paths = append(paths,
+			inst.database(),
+			filepath.Join(inst.dataDir, "games"),
+			filepath.Join(inst.configDir, "games"),
+		)
+	}
+	return paths
+}
+
+// Scan discovers installed games from native and Flatpak Lutris. A broken
+// database only loses the games of its own install, and is reported when
+// no database could be read.
+func (l *Launcher) Scan(ctx context.Context) ([]*entry.Entry, error) {
+	if os.Getenv("HOME") == "" {
+		return nil, fmt.Errorf("HOME environment variable not set")
+	}
+
+	entries := make([]*entry.Entry, 0)
+	var errs []error
+	read := false
+
+	for _, inst := range installations() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		games, err := readGames(inst.database())
+		if err != nil {
+			err = fmt.Errorf("reading lutris database %s: %w", inst.database(), err)
+			if os.Getenv("DEBUG") == "1" {
+				fmt.Printf("Error scanning lutris: %v\n", err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		read = true
+
+		for _, game := range games {
+			if !game.Installed || game.Hidden {
+				continue
+			}
+			entries = append(entries, gameToEntry(game, inst))
+		}
+	}
+
+	if !read && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return entries, nil
+}
+
+// database returns the path of the game database
+func (inst installation) database() string {
+	return filepath.Join(inst.dataDir, "pga.db")
+}
+
+// installations returns the Lutris installs that have a game database,
+// native first
+func installations() []installation {
+	homeDir := os.Getenv("HOME")
+	flatpakHome := filepath.Join(homeDir, ".var", "app", flatpakID)
+	candidates := []installation{
+		{
+			dataDir:   filepath.Join(homeDir, ".local", "share", "lutris"),
+			configDir: filepath.Join(homeDir, ".config", "lutris"),
+			cacheDir:  filepath.Join(homeDir, ".cache", "lutris"),
+		},
+		{
+			dataDir:   filepath.Join(flatpakHome, "data", "lutris"),
+			configDir: filepath.Join(flatpakHome, "config", "lutris"),
+			cacheDir:  filepath.Join(flatpakHome, "cache", "lutris"),
+			flatpak:   true,
+		},
+	}
+
+	var result []installation
+	seen := make(map[string]bool)
+	for _, inst := range candidates {
+		if _, err := os.Stat(inst.database()); err != nil {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(inst.dataDir)
+		if err != nil || seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		result = append(result, inst)
+	}
+	return result
+}
+
+// readGames reads the games of a Lutris database with their categories
+func readGames(path string) ([]Game, error) {
+	db, err := sqlite.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Rows("games")
+	if err != nil {
+		return nil, err
+	}
+
+	categories := gameCategories(db)
+
+	games := make([]Game, 0, len(rows))
+	for _, row := range rows {
+		game := Game{
+			ID:         rowInt(row, "id"),
+			Name:       rowString(row, "name"),
+			Slug:       rowString(row, "slug"),
+			Runner:     rowString(row, "runner"),
+			ConfigPath: rowString(row, "configpath"),
+			Service:    rowString(row, "service"),
+			LastPlayed: rowInt(row, "lastplayed"),
+			Playtime:   rowFloat(row, "playtime"),
+			Installed:  rowInt(row, "installed") != 0,
+			Hidden:     rowInt(row, "hidden") != 0, // Older versions only
+		}
+		if game.Name == "" {
+			continue
+		}
+
+		for _, category := range categories[game.ID] {
+			switch category {
+			case categoryHidden:
+				game.Hidden = true
+			case categoryFavorite:
+			default:
+				game.Categories = append(game.Categories, category)
+			}
+		}
+
+		games = append(games, game)
+	}
+
+	return games, nil
+}
+
+// gameCategories returns the category names of each game, keyed by game
+// ID. Versions before categories were added have no such tables, and a
+// broken table only costs categories.
+func gameCategories(db *sqlite.DB) map[int64][]string {
+	categoryRows, err := db.Rows("categories")
+	if err != nil {
+		if !errors.Is(err, sqlite.ErrNoTable) && os.Getenv("DEBUG") == "1" {
+			fmt.Printf("Error reading lutris categories: %v\n", err)
+		}
+		return nil
+	}
+
+	names := make(map[int64]string, len(categoryRows))
+	for _, row := range categoryRows {
+		names[rowInt(row, "id")] = rowString(row, "name")
+	}
+
+	links, err := db.Rows("games_categories")
+	if err != nil {
+		return nil
+	}
+
+	categories := make(map[int64][]string)
+	for _, row := range links {
+		if name := names[rowInt(row, "category_id")]; name != "" {
+			gameID := rowInt(row, "game_id")
+			categories[gameID] = append(categories[gameID], name)
+		}
+	}
+	return categories
+}
+
+// rowString returns a text column, or "" if it is NULL or not text
+func rowString(row sqlite.Row, column string) string {
+	s, _ := row[column].(string)
+	return s
+}
+
+// rowInt returns an integer column, or 0 if it is NULL or not an integer
+func rowInt(row sqlite.Row, column string) int64 {
+	n, _ := row[column].(int64)
+	return n
+}
+
+// rowFloat returns a numeric column, or 0 if it is NULL or not a number
+func rowFloat(row sqlite.Row, column string) float64 {
+	switch n := row[column].(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n) // Whole REALs are stored as integers
+	}
+	return 0
+}
+
+// gameToEntry converts a Lutris game to an Entry
+func gameToEntry(game Game, inst installation) *entry.Entry {
+	uri := fmt.Sprintf("lutris:rungameid/%d", game.ID)
+	exec := "lutris " + uri
+	path := fmt.Sprintf("lutris-%d", game.ID) // Unique identifier
+	if inst.flatpak {
+		exec = fmt.Sprintf("flatpak run %s %s", flatpakID, uri)
+		// IDs are per install. The path must not mention "flatpak", which
+		// would classify the game as a Flatpak app.
+		path = fmt.Sprintf("lutris-sandbox-%d", game.ID)
+	}
+
+	icon := findIcon(inst, game.Slug)
+	if icon == "" {
+		icon = "applications-games" // Generic game icon
+	}
+
+	e := &entry.Entry{
+		Name:       game.Name,
+		Comment:    fmt.Sprintf("Lutris Game: %s", describe(game, inst)),
+		Exec:       exec,
+		Icon:       icon,
+		Image:      findCover(inst, game.Slug),
+		Terminal:   false,
+		Categories: append([]string{"Game"}, game.Categories...),
+		Path:       path,
+	}
+
+	// Games imported from a store name it, the others were installed
+	// through Lutris
+	store := game.Service
+	if store == "" {
+		store = "lutris"
+	}
+	e.SetMeta(entry.MetaStore, store)
+	e.SetMeta(entry.MetaRunner, game.Runner)
+	e.SetLastPlayed(time.Unix(game.LastPlayed, 0))
+	e.SetPlaytime(time.Duration(game.Playtime * float64(time.Hour)))
+
+	return e
+}
+
+// gameFileKeys are the config keys runners store the game's main file in
+var gameFileKeys = []string{"game.exe", "game.main_file", "game.iso", "game.rom"}
+
+// describe returns the file the game runs from its config, or the runner
+// if the config is missing or names no file
+func describe(game Game, inst installation) string {
+	config := loadGameConfig(inst, game.ConfigPath)
+	for _, key := range gameFileKeys {
+		if file := config[key]; file != "" {
+			return filepath.Base(file)
+		}
+	}
+	return game.Runner
+}
+
+// loadGameConfig reads the YAML config of a game. Newer versions keep
+// configs in the data directory, older ones in the config directory.
+func loadGameConfig(inst installation, configPath string) map[string]string {
+	if configPath == "" || strings.ContainsRune(configPath, filepath.Separator) {
+		return nil
+	}
+
+	for _, dir := range []string{inst.dataDir, inst.configDir} {
+		config, err := yaml.ParseFile(filepath.Join(dir, "games", configPath+".yml"))
+		if err == nil {
+			return config
+		}
+		if !os.IsNotExist(err) && os.Getenv("DEBUG") == "1" {
+			fmt.Printf("Error reading lutris config %s: %v\n", configPath, err)
+		}
+	}
+	return nil
+}
+
+// findIcon returns the icon Lutris installed for a game into the icon
+// theme next to its data directory
+func findIcon(inst installation, slug string) string {
+	if slug == "" {
+		return ""
+	}
+	userDataDir := filepath.Dir(inst.dataDir)
+	return existingFile(filepath.Join(userDataDir, "icons", "hicolor", "128x128", "apps", "lutris_"+slug+".png"))
+}
+
+// findCover returns the cached cover art of a game, then its banner. Newer
+// Lutris versions cache them in the cache directory, older ones in the
+// data directory.
+func findCover(inst installation, slug string) string {
+	if slug == "" {
+		return ""
+	}
+	return existingFile(
+		filepath.Join(inst.cacheDir, "coverart", slug+".jpg"),
+		filepath.Join(inst.dataDir, "coverart", slug+".jpg"),
+		filepath.Join(inst.cacheDir, "banners", slug+".jpg"),
+		filepath.Join(inst.dataDir, "banners", slug+".jpg"),
+	)
+}
+
+// existingFile returns the first of paths that exists
+func existingFile(paths ...string) string {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+package lutris
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// installDatabase copies testdata/pga.db into a data directory. It holds
+// Celeste (1), Hades (2), an uninstalled game (3), a game hidden by the
+// old hidden column (4) and one hidden by the .hidden category (5).
+func installDatabase(t *testing.T, dataDir string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "pga.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dataDir, "pga.db"), string(data))
+}
+
+func TestLauncherName(t *testing.T) {
+	l := &Launcher{}
+	if l.Name() != "lutris" {
+		t.Errorf("Name() = %v, want %v", l.Name(), "lutris")
+	}
+}
+
+func TestScanNoLutris(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Errorf("Scan() error = %v, want nil", err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("Scan() returned entries when Lutris isn't installed")
+	}
+}
+
+func TestScan(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	dataDir := filepath.Join(tmpDir, ".local", "share", "lutris")
+	installDatabase(t, dataDir)
+
+	// Hades uses a config in the old location and a cached banner
+	writeFile(t, filepath.Join(tmpDir, ".config", "lutris", "games", "hades-1700000001.yml"), `game:
+  exe: /home/user/Games/hades/drive_c/Hades/x64/Hades.exe # main binary
+  prefix: /home/user/Games/hades
+system: {}
+wine:
+  version: lutris-7.2
+`)
+	writeFile(t, filepath.Join(tmpDir, ".cache", "lutris", "banners", "hades.jpg"), "")
+
+	// Celeste has an installed icon
+	icon := filepath.Join(tmpDir, ".local", "share", "icons", "hicolor", "128x128", "apps", "lutris_celeste.png")
+	writeFile(t, icon, "")
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Scan() returned %d entries, want 2", len(entries))
+	}
+
+	celeste, hades := entries[0], entries[1]
+
+	if celeste.Name != "Celeste" || celeste.Exec != "lutris lutris:rungameid/1" || celeste.Path != "lutris-1" {
+		t.Errorf("Celeste entry = %+v", celeste)
+	}
+	if celeste.Icon != icon {
+		t.Errorf("Celeste icon = %q, want %q", celeste.Icon, icon)
+	}
+	if celeste.Image != "" {
+		t.Errorf("Celeste image = %q, want none", celeste.Image)
+	}
+	if celeste.Comment != "Lutris Game: linux" {
+		t.Errorf("Celeste comment = %q, want the runner", celeste.Comment)
+	}
+	if want := []string{"Game", "Platformer"}; !reflect.DeepEqual(celeste.Categories, want) {
+		t.Errorf("Celeste categories = %v, want %v", celeste.Categories, want)
+	}
+
+	if hades.Comment != "Lutris Game: Hades.exe" {
+		t.Errorf("Hades comment = %q, want the executable", hades.Comment)
+	}
+	if hades.Icon != "applications-games" {
+		t.Errorf("Hades icon = %q, want applications-games", hades.Icon)
+	}
+	if want := filepath.Join(tmpDir, ".cache", "lutris", "banners", "hades.jpg"); hades.Image != want {
+		t.Errorf("Hades image = %q, want %q", hades.Image, want)
+	}
+	if want := []string{"Game", "Roguelike"}; !reflect.DeepEqual(hades.Categories, want) {
+		t.Errorf("Hades categories = %v, want %v", hades.Categories, want)
+	}
+	if !hades.LastPlayed().Equal(time.Unix(1700000000, 0)) || hades.Playtime() != 90*time.Minute {
+		t.Errorf("Hades LastPlayed() = %v, Playtime() = %v", hades.LastPlayed(), hades.Playtime())
+	}
+	if hades.Meta(entry.MetaStore) != "lutris" || hades.Meta(entry.MetaRunner) != "wine" {
+		t.Errorf("Hades Metadata = %v, want the lutris store and wine runner", hades.Metadata)
+	}
+}
+
+func TestScanFlatpak(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	installDatabase(t, filepath.Join(tmpDir, ".var", "app", flatpakID, "data", "lutris"))
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Scan() returned %d entries, want 2", len(entries))
+	}
+	if want := "flatpak run net.lutris.Lutris lutris:rungameid/1"; entries[0].Exec != want {
+		t.Errorf("Exec = %q, want %q", entries[0].Exec, want)
+	}
+	if entries[0].Path != "lutris-sandbox-1" {
+		t.Errorf("Path = %q, want lutris-sandbox-1", entries[0].Path)
+	}
+	if entries[0].GetAppType() != entry.AppTypeGame {
+		t.Errorf("GetAppType() = %v, want %v", entries[0].GetAppType(), entry.AppTypeGame)
+	}
+}
+
+func TestScanBrokenDatabase(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	writeFile(t, filepath.Join(tmpDir, ".local", "share", "lutris", "pga.db"), "not a database")
+
+	l := &Launcher{}
+	if _, err := l.Scan(context.Background()); err == nil {
+		t.Error("Scan() error = nil, want an error for a broken database")
+	}
+}
+
+func TestScanPartlyBrokenDatabase(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	installDatabase(t, filepath.Join(tmpDir, ".local", "share", "lutris"))
+	writeFile(t, filepath.Join(tmpDir, ".var", "app", flatpakID, "data", "lutris", "pga.db"), "not a database")
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Errorf("Scan() error = %v, want nil while a database could be read", err)
+	}
+	if len(entries) != 2 || entries[0].Path != "lutris-1" {
+		t.Errorf("Scan() = %v, want the native games despite the broken Flatpak database", entries)
+	}
+}
@@ -0,0 +1,14 @@
+package sqlite
+
+import "errors"
+
+var (
+	// ErrNotDatabase indicates the file is not an SQLite 3 database
+	ErrNotDatabase = errors.New("sqlite: not a database file")
+
+	// ErrCorrupt indicates the database structure is inconsistent
+	ErrCorrupt = errors.New("sqlite: database corrupt")
+
+	// ErrNoTable indicates the requested table does not exist
+	ErrNoTable = errors.New("sqlite: no such table")
+)
@@ -0,0 +1,456 @@
+// Package sqlite reads tables from SQLite 3 database files. It supports
+// just enough of the file format to list the rows of a table, which is
+// all the Lutris launcher needs, without linking against libsqlite3.
+// Changes still in a write-ahead log (-wal file) are not visible.
+// See: https://www.sqlite.org/fileformat.html
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// headerMagic starts every SQLite 3 database file
+const headerMagic = "SQLite format 3\x00"
+
+// B-tree page types
+const (
+	pageInteriorTable = 0x05
+	pageLeafTable     = 0x0d
+)
+
+// DB is an SQLite database loaded into memory
+type DB struct {
+	data       []byte
+	pageSize   int
+	usableSize int
+}
+
+// Row is a table row, keyed by column name. Values are int64, float64,
+// string, []byte or nil as stored in the file; SQLite stores whole numbers
+// in REAL columns as integers.
+type Row map[string]any
+
+// Open reads a database file
+func Open(path string) (*DB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse reads a database from its file contents
+func Parse(data []byte) (*DB, error) {
+	if len(data) < 100 || string(data[:16]) != headerMagic {
+		return nil, ErrNotDatabase
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("%w: invalid page size %d", ErrCorrupt, pageSize)
+	}
+
+	if encoding := binary.BigEndian.Uint32(data[56:60]); encoding > 1 {
+		return nil, fmt.Errorf("sqlite: unsupported text encoding %d", encoding)
+	}
+
+	return &DB{
+		data:       data,
+		pageSize:   pageSize,
+		usableSize: pageSize - int(data[20]),
+	}, nil
+}
+
+// Tables returns the names of all tables
+func (db *DB) Tables() ([]string, error) {
+	schema, err := db.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, obj := range schema {
+		if obj.kind == "table" {
+			names = append(names, obj.name)
+		}
+	}
+	return names, nil
+}
+
+// Rows returns every row of a table in rowid order
+func (db *DB) Rows(table string) ([]Row, error) {
+	schema, err := db.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range schema {
+		if obj.kind != "table" || !strings.EqualFold(obj.name, table) {
+			continue
+		}
+
+		columns, rowidColumn := parseColumns(obj.sql)
+		var rows []Row
+		err := db.walkTable(obj.rootPage, func(rowid int64, values []any) {
+			row := make(Row, len(columns))
+			for i, column := range columns {
+				if i < len(values) {
+					row[column] = values[i]
+				} else {
+					row[column] = nil // Column added after the row was written
+				}
+			}
+			if rowidColumn != "" {
+				row[rowidColumn] = rowid
+			}
+			rows = append(rows, row)
+		})
+		return rows, err
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNoTable, table)
+}
+
+// schemaObject is a row of the sqlite_schema table
+type schemaObject struct {
+	kind     string
+	name     string
+	rootPage int
+	sql      string
+}
+
+// schema reads the sqlite_schema table, which is rooted at page 1
+func (db *DB) schema() ([]schemaObject, error) {
+	var objects []schemaObject
+	err := db.walkTable(1, func(_ int64, values []any) {
+		if len(values) < 5 {
+			return
+		}
+		kind, _ := values[0].(string)
+		name, _ := values[1].(string)
+		rootPage, _ := values[3].(int64)
+		sql, _ := values[4].(string)
+		objects = append(objects, schemaObject{kind: kind, name: name, rootPage: int(rootPage), sql: sql})
+	})
+	return objects, err
+}
+
+// page returns the contents of a page, numbered from 1
+func (db *DB) page(number int) ([]byte, error) {
+	start := (number - 1) * db.pageSize
+	if number < 1 || start+db.pageSize > len(db.data) {
+		return nil, fmt.Errorf("%w: page %d out of range", ErrCorrupt, number)
+	}
+	return db.data[start : start+db.pageSize], nil
+}
+
+// walkTable calls fn for every row of the table b-tree rooted at a page
+func (db *DB) walkTable(root int, fn func(rowid int64, values []any)) error {
+	return db.walkPage(root, fn, 0)
+}
+
+// walkPage visits a table b-tree page and its children in order
+func (db *DB) walkPage(number int, fn func(rowid int64, values []any), depth int) error {
+	// Guard against cycles in corrupt files
+	if depth > 64 {
+		return fmt.Errorf("%w: b-tree too deep", ErrCorrupt)
+	}
+
+	page, err := db.page(number)
+	if err != nil {
+		return err
+	}
+
+	// Page 1 starts with the database header
+	offset := 0
+	if number == 1 {
+		offset = 100
+	}
+	if offset+8 > len(page) {
+		return fmt.Errorf("%w: page %d too small", ErrCorrupt, number)
+	}
+
+	kind := page[offset]
+	cellCount := int(binary.BigEndian.Uint16(page[offset+3:]))
+	headerSize := 8
+	if kind == pageInteriorTable {
+		headerSize = 12
+	}
+	if offset+headerSize+2*cellCount > len(page) {
+		return fmt.Errorf("%w: page %d cell pointers out of range", ErrCorrupt, number)
+	}
+
+	for i := 0; i < cellCount; i++ {
+		cellOffset := int(binary.BigEndian.Uint16(page[offset+headerSize+2*i:]))
+		if cellOffset >= len(page) {
+			return fmt.Errorf("%w: page %d cell out of range", ErrCorrupt, number)
+		}
+		cell := page[cellOffset:]
+
+		switch kind {
+		case pageInteriorTable:
+			if len(cell) < 4 {
+				return fmt.Errorf("%w: page %d truncated cell", ErrCorrupt, number)
+			}
+			child := int(binary.BigEndian.Uint32(cell))
+			if err := db.walkPage(child, fn, depth+1); err != nil {
+				return err
+			}
+
+		case pageLeafTable:
+			rowid, values, err := db.readLeafCell(cell)
+			if err != nil {
+				return fmt.Errorf("page %d: %w", number, err)
+			}
+			fn(rowid, values)
+
+		default:
+			return fmt.Errorf("%w: page %d is not a table page", ErrCorrupt, number)
+		}
+	}
+
+	if kind == pageInteriorTable {
+		right := int(binary.BigEndian.Uint32(page[offset+8:]))
+		return db.walkPage(right, fn, depth+1)
+	}
+	return nil
+}
+
+// readLeafCell decodes a table leaf cell, following overflow pages
+func (db *DB) readLeafCell(cell []byte) (int64, []any, error) {
+	payloadSize, n := readVarint(cell)
+	if n == 0 {
+		return 0, nil, fmt.Errorf("%w: truncated cell", ErrCorrupt)
+	}
+	// No payload is larger than the database holding it
+	if payloadSize > uint64(len(db.data)) {
+		return 0, nil, fmt.Errorf("%w: payload size %d past the end of the file", ErrCorrupt, payloadSize)
+	}
+	cell = cell[n:]
+
+	rowid, n := readVarint(cell)
+	if n == 0 {
+		return 0, nil, fmt.Errorf("%w: truncated cell", ErrCorrupt)
+	}
+	cell = cell[n:]
+
+	payload, err := db.readPayload(cell, int(payloadSize))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	values, err := decodeRecord(payload)
+	return int64(rowid), values, err
+}
+
+// readPayload collects a payload stored partly in the cell and partly in
+// a chain of overflow pages
+func (db *DB) readPayload(cell []byte, size int) ([]byte, error) {
+	local := db.localPayloadSize(size)
+	if local > len(cell) {
+		return nil, fmt.Errorf("%w: truncated payload", ErrCorrupt)
+	}
+	if local == size {
+		return cell[:size], nil
+	}
+	if local+4 > len(cell) {
+		return nil, fmt.Errorf("%w: truncated payload", ErrCorrupt)
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, cell[:local]...)
+	next := int(binary.BigEndian.Uint32(cell[local:]))
+
+	for len(payload) < size {
+		if next == 0 {
+			return nil, fmt.Errorf("%w: overflow chain too short", ErrCorrupt)
+		}
+		page, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		next = int(binary.BigEndian.Uint32(page))
+		chunk := page[4:db.usableSize]
+		if remaining := size - len(payload); len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		payload = append(payload, chunk...)
+	}
+
+	return payload, nil
+}
+
+// localPayloadSize returns how much of a table leaf payload is stored in
+// the cell itself
+func (db *DB) localPayloadSize(size int) int {
+	maxLocal := db.usableSize - 35
+	if size <= maxLocal {
+		return size
+	}
+	minLocal := (db.usableSize-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(db.usableSize-4)
+	if local > maxLocal {
+		return minLocal
+	}
+	return local
+}
+
+// decodeRecord decodes the values of a record
+func decodeRecord(payload []byte) ([]any, error) {
+	headerSize, n := readVarint(payload)
+	if n == 0 || headerSize < uint64(n) || headerSize > uint64(len(payload)) {
+		return nil, fmt.Errorf("%w: invalid record header", ErrCorrupt)
+	}
+
+	header := payload[n:headerSize]
+	body := payload[headerSize:]
+	var values []any
+
+	for len(header) > 0 {
+		serialType, n := readVarint(header)
+		if n == 0 {
+			return nil, fmt.Errorf("%w: invalid record header", ErrCorrupt)
+		}
+		header = header[n:]
+
+		size := serialSize(serialType)
+		if size < 0 || size > len(body) {
+			return nil, fmt.Errorf("%w: record body too short", ErrCorrupt)
+		}
+		values = append(values, decodeValue(serialType, body[:size]))
+		body = body[size:]
+	}
+
+	return values, nil
+}
+
+// serialSize returns the number of body bytes of a serial type, negative
+// for serial types too large to be valid
+func serialSize(serialType uint64) int {
+	switch {
+	case serialType <= 4:
+		return int(serialType)
+	case serialType == 5:
+		return 6
+	case serialType == 6, serialType == 7:
+		return 8
+	case serialType < 12:
+		return 0
+	case (serialType-12)/2 > math.MaxInt:
+		return -1
+	default:
+		return int((serialType - 12) / 2)
+	}
+}
+
+// decodeValue converts the body bytes of a value
+func decodeValue(serialType uint64, data []byte) any {
+	switch {
+	case serialType == 0:
+		return nil
+	case serialType <= 6:
+		// Big-endian two's complement integer
+		var v int64
+		if len(data) > 0 && data[0]&0x80 != 0 {
+			v = -1
+		}
+		for _, b := range data {
+			v = v<<8 | int64(b)
+		}
+		return v
+	case serialType == 7:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	case serialType == 8:
+		return int64(0)
+	case serialType == 9:
+		return int64(1)
+	case serialType < 12:
+		return nil // Reserved
+	case serialType%2 == 0:
+		return bytes.Clone(data)
+	default:
+		return string(data)
+	}
+}
+
+// readVarint decodes a variable-length integer, returning the number of
+// bytes read or 0 if the input is truncated
+func readVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(data) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(data[i]), 9
+		}
+		v = v<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
+
+// parseColumns returns the column names of a CREATE TABLE statement and
+// the column aliasing the rowid (declared INTEGER PRIMARY KEY), if any
+func parseColumns(sql string) ([]string, string) {
+	start := strings.Index(sql, "(")
+	end := strings.LastIndex(sql, ")")
+	if start < 0 || end <= start {
+		return nil, ""
+	}
+
+	var columns []string
+	rowidColumn := ""
+	for _, def := range splitTopLevel(sql[start+1 : end]) {
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			continue
+		}
+
+		keyword, _, _ := strings.Cut(fields[0], "(")
+		switch strings.ToUpper(keyword) {
+		case "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "CONSTRAINT":
+			continue // Table constraint, not a column
+		}
+
+		name := strings.Trim(fields[0], "\"`[]'")
+		columns = append(columns, name)
+
+		upper := strings.ToUpper(strings.Join(fields[1:], " "))
+		if strings.HasPrefix(upper, "INTEGER") && strings.Contains(upper, "PRIMARY KEY") {
+			rowidColumn = name
+		}
+	}
+
+	return columns, rowidColumn
+}
+
+// splitTopLevel splits column definitions on commas outside parentheses
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testdata/test.db uses 512 byte pages so the items table spans interior
+// pages and the long row needs overflow pages. The note column was added
+// with ALTER TABLE after the first rows were written.
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	db, err := Open(filepath.Join("testdata", "test.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	return db
+}
+
+func TestTables(t *testing.T) {
+	db := openTestDB(t)
+
+	tables, err := db.Tables()
+	if err != nil {
+		t.Fatalf("Tables() error = %v", err)
+	}
+	if want := []string{"items", "empty"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("Tables() = %v, want %v", tables, want)
+	}
+}
+
+func TestRows(t *testing.T) {
+	db := openTestDB(t)
+
+	rows, err := db.Rows("items")
+	if err != nil {
+		t.Fatalf("Rows() error = %v", err)
+	}
+	if len(rows) != 302 {
+		t.Fatalf("Rows() returned %d rows, want 302", len(rows))
+	}
+
+	tests := []struct {
+		name  string
+		index int
+		want  Row
+	}{
+		{
+			name:  "first row",
+			index: 0,
+			want:  Row{"id": int64(1), "name": "item 1", "count": int64(1000), "ratio": 0.25, "data": []byte{1}, "note": nil},
+		},
+		{
+			name:  "negative count",
+			index: 1,
+			want:  Row{"id": int64(2), "name": "item 2", "count": int64(-2), "ratio": 0.5, "data": []byte{2}, "note": nil},
+		},
+		{
+			name:  "whole real stored as integer",
+			index: 299,
+			want:  Row{"id": int64(300), "name": "item 300", "count": int64(-300), "ratio": int64(75), "data": []byte{44}, "note": nil},
+		},
+		{
+			name:  "overflow",
+			index: 300,
+			want:  Row{"id": int64(1000), "name": "long " + strings.Repeat("x", 3000), "count": nil, "ratio": nil, "data": nil, "note": nil},
+		},
+		{
+			name:  "added column",
+			index: 301,
+			want:  Row{"id": int64(2000), "name": "Ärger", "count": nil, "ratio": nil, "data": nil, "note": "with note"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rows[tt.index]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("row %d = %v, want %v", tt.index, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRowsEmptyTable(t *testing.T) {
+	db := openTestDB(t)
+
+	rows, err := db.Rows("EMPTY")
+	if err != nil {
+		t.Fatalf("Rows() error = %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("Rows() = %v, want no rows", rows)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := db.Rows("missing"); !errors.Is(err, ErrNoTable) {
+		t.Errorf("Rows(missing) error = %v, want ErrNoTable", err)
+	}
+
+	if _, err := Parse([]byte("not a database")); !errors.Is(err, ErrNotDatabase) {
+		t.Errorf("Parse() error = %v, want ErrNotDatabase", err)
+	}
+
+	// Cut the file short so pages referenced by the schema are missing
+	data, err := os.ReadFile(filepath.Join("testdata", "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated, err := Parse(data[:1024])
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if _, err := truncated.Rows("items"); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Rows() on truncated file error = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestCorruptRecords(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The record of the first row: header size, serial types, then values
+	record := []byte("\x06\x00\x19\x02\x07\x0eitem 1")
+	at := bytes.Index(data, record)
+	if at < 0 {
+		t.Fatal("first row not found in testdata/test.db")
+	}
+
+	tests := []struct {
+		name   string
+		offset int  // Of the byte damaged, in the record
+		value  byte // Written there
+	}{
+		{"header size shorter than its varint", 0, 0x00},
+		{"header size past the payload", 0, 0x7f},
+		{"serial type too large", 2, 0xff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			damaged := bytes.Clone(data)
+			damaged[at+tt.offset] = tt.value
+			db, err := Parse(damaged)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if _, err := db.Rows("items"); !errors.Is(err, ErrCorrupt) {
+				t.Errorf("Rows() error = %v, want ErrCorrupt", err)
+			}
+		})
+	}
+
+	// Payload sizes larger than the file, the largest one negative as an int
+	db, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for _, size := range [][]byte{
+		{0x81, 0x80, 0x80, 0x00},
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	} {
+		cell := append(bytes.Clone(size), 0x01, 0x02, 0x00, 0x00)
+		if _, _, err := db.readLeafCell(cell); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("readLeafCell(% x) error = %v, want ErrCorrupt", size, err)
+		}
+	}
+
+	// The largest serial type, whose size overflows an int on 32-bit systems
+	if _, err := decodeRecord([]byte{0x0a, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00}); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("decodeRecord() error = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		sql         string
+		wantColumns []string
+		wantRowid   string
+	}{
+		{
+			sql:         `CREATE TABLE games (id INTEGER PRIMARY KEY, name TEXT, "slug" TEXT, installed INTEGER DEFAULT 0)`,
+			wantColumns: []string{"id", "name", "slug", "installed"},
+			wantRowid:   "id",
+		},
+		{
+			sql:         "CREATE TABLE t (a NUMERIC(10, 2), b, PRIMARY KEY (a, b), UNIQUE(b))",
+			wantColumns: []string{"a", "b"},
+		},
+		{
+			sql:         "CREATE TABLE t (key TEXT PRIMARY KEY, value)",
+			wantColumns: []string{"key", "value"},
+		},
+	}
+
+	for _, tt := range tests {
+		columns, rowid := parseColumns(tt.sql)
+		if !reflect.DeepEqual(columns, tt.wantColumns) || rowid != tt.wantRowid {
+			t.Errorf("parseColumns(%q) = %v, %q, want %v, %q", tt.sql, columns, rowid, tt.wantColumns, tt.wantRowid)
+		}
+	}
+}
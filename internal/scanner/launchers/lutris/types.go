@@ -0,0 +1,34 @@
+package lutris
+
+// flatpakID is the Flatpak application ID of Lutris
+const flatpakID = "net.lutris.Lutris"
+
+// Categories Lutris manages itself rather than the user
+const (
+	categoryHidden   = ".hidden"
+	categoryFavorite = "favorite"
+)
+
+// Game is the part of a row of the games table we use
+type Game struct {
+	ID         int64
+	Name       string
+	Slug       string
+	Runner     string
+	ConfigPath string
+	Service    string  // Store the game was imported from, if any
+	LastPlayed int64   // Unix time, 0 if never played
+	Playtime   float64 // Hours
+	Installed  bool
+	Hidden     bool
+	Categories []string
+}
+
+// installation holds the directories of a Lutris install and how to run
+// its client
+type installation struct {
+	dataDir   string // pga.db and, in newer versions, game configs
+	configDir string // game configs in older versions
+	cacheDir  string // banners and cover art in newer versions
+	flatpak   bool
+}
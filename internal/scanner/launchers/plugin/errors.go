@@ -0,0 +1,33 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidOutput indicates a plugin printed something other than
+	// JSON entries
+	ErrInvalidOutput = errors.New("plugin: invalid output")
+
+	// ErrInvalidEntry indicates an entry printed by a plugin lacks a name
+	// or a command
+	ErrInvalidEntry = errors.New("plugin: invalid entry")
+)
+
+// Error describes why a plugin's entries are missing or incomplete.
+// It unwraps to the underlying error.
+type Error struct {
+	Plugin string
+	Err    error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("plugin %s: %v", e.Plugin, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
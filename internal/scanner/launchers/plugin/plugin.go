@@ -0,0 +1,261 @@
+// Package plugin runs external programs as a game launcher source, so
+// launchers can be added without rebuilding gofi. Every executable in
+// ~/.config/gofi/launchers is run on each scan and prints its entries as
+// JSON on stdout, either as an array or as one object after the other:
+//
+//	[{"id": "celeste", "name": "Celeste", "exec": "itch-launch celeste",
+//	  "icon": "itch", "categories": ["Game"]}]
+//
+// Only name and exec are required; exec is quoted as in a desktop file's
+// Exec key. A plugin that fails, times out or prints invalid JSON only loses
+// its own entries.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers"
+)
+
+func init() {
+	// Auto-register the plugin launcher
+	launchers.Register(&Launcher{})
+}
+
+// Launcher implements the GameLauncher interface for external plugins
+type Launcher struct {
+	timeout time.Duration // Per plugin, DefaultTimeout if zero
+}
+
+// Name returns the launcher identifier
+func (l *Launcher) Name() string {
+	return "exec"
+}
+
+// WatchPaths returns the plugin directory, so added and removed plugins
+// are picked up
+func (l *Launcher) WatchPaths() []string {
+	return []string{pluginDir()}
+}
+
+// Scan runs every plugin at the same time and merges their entries in
+// plugin name order. The entries of the plugins that worked are returned
+// along with an *Error for each plugin that didn't.
+func (l *Launcher) Scan(ctx context.Context) ([]*entry.Entry, error) {
+	if os.Getenv("HOME") == "" && os.Getenv("XDG_CONFIG_HOME") == "" {
+		return nil, fmt.Errorf("HOME environment variable not set")
+	}
+
+	plugins, err := findPlugins(pluginDir())
+	if err != nil {
+		return nil, fmt.Errorf("listing plugins: %w", err)
+	}
+
+	type result struct {
+		entries []*entry.Entry
+		err     error
+	}
+	results := make([]result, len(plugins))
+
+	var wg sync.WaitGroup
+	for i, path := range plugins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entries, err := l.run(ctx, path)
+			results[i] = result{entries, err}
+		}()
+	}
+	wg.Wait()
+
+	entries := make([]*entry.Entry, 0)
+	var errs []error
+	for i, r := range results {
+		entries = append(entries, r.entries...)
+		if r.err != nil {
+			errs = append(errs, &Error{Plugin: filepath.Base(plugins[i]), Err: r.err})
+		}
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+// pluginDir returns the directory plugins are installed to
+func pluginDir() string {
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		return filepath.Join(xdgConfig, "gofi", "launchers")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config", "gofi", "launchers")
+}
+
+// findPlugins returns the executables in a directory, sorted by name.
+// Hidden files and editor backups are skipped; a missing directory has no
+// plugins.
+func findPlugins(dir string) ([]string, error) {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []string
+	for _, f := range files {
+		name := f.Name()
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+
+		// Follow symlinks, plugins are often linked from where they're built
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		plugins = append(plugins, path)
+	}
+	return plugins, nil
+}
+
+// run runs a plugin and converts the entries it prints. Invalid entries
+// are reported without losing the valid ones.
+func (l *Launcher) run(ctx context.Context, path string) ([]*entry.Entry, error) {
+	timeout := l.timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = filepath.Dir(path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Processes the plugin started may keep its output open after it was
+	// killed, which must not hold the scan up
+	cmd.WaitDelay = 100 * time.Millisecond
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out after %v", timeout)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if msg := errorOutput(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	items, err := parseOutput(stdout.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	plugin := filepath.Base(path)
+	entries := make([]*entry.Entry, 0, len(items))
+	var errs []error
+	for i, item := range items {
+		if item.Name == "" || item.Exec == "" {
+			errs = append(errs, fmt.Errorf("%w: entry %d needs a name and exec", ErrInvalidEntry, i+1))
+			continue
+		}
+		entries = append(entries, itemToEntry(item, plugin))
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+// errorOutput returns the error output of a failed plugin, shortened to
+// fit in a log line
+func errorOutput(stderr string) string {
+	msg := strings.Join(strings.Fields(stderr), " ")
+	if len(msg) > maxStderr {
+		// Cut at the start of a character, keeping the output valid UTF-8
+		end := maxStderr
+		for end > 0 && !utf8.RuneStart(msg[end]) {
+			end--
+		}
+		msg = msg[:end] + "…"
+	}
+	return msg
+}
+
+// parseOutput decodes the items a plugin printed, either as a JSON array
+// or as one JSON object after the other
+func parseOutput(data []byte) ([]Item, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if data[0] == '[' {
+		var items []Item
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+		}
+		return items, nil
+	}
+
+	var items []Item
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var item Item
+		err := decoder.Decode(&item)
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+		}
+		items = append(items, item)
+	}
+}
+
+// itemToEntry converts an item printed by a plugin to an Entry
+func itemToEntry(item Item, plugin string) *entry.Entry {
+	id := item.ID
+	if id == "" {
+		id = item.Name
+	}
+
+	comment := item.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Plugin: %s", plugin)
+	}
+
+	return &entry.Entry{
+		Name:       item.Name,
+		Comment:    comment,
+		Exec:       item.Exec,
+		Icon:       item.Icon,
+		Terminal:   item.Terminal,
+		Categories: item.Categories,
+		Path:       "exec-" + itemID(plugin, id), // Unique identifier
+	}
+}
+
+// itemID identifies an item of a plugin. IDs are hashed since they may
+// contain anything, including words GetAppType looks for in entry paths.
+func itemID(plugin, id string) string {
+	sum := sha256.Sum256([]byte(plugin + "\x00" + id))
+	return hex.EncodeToString(sum[:8])
+}
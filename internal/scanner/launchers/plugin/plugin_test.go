@@ -0,0 +1,195 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+)
+
+func writeFile(t *testing.T, path, content string, perm os.FileMode) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLauncherName(t *testing.T) {
+	l := &Launcher{}
+	if l.Name() != "exec" {
+		t.Errorf("Name() = %v, want %v", l.Name(), "exec")
+	}
+}
+
+func TestScanNoPlugins(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Errorf("Scan() error = %v, want nil", err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("Scan() returned entries without plugins")
+	}
+	if want := filepath.Join(tmpDir, ".config", "gofi", "launchers"); !reflect.DeepEqual(l.WatchPaths(), []string{want}) {
+		t.Errorf("WatchPaths() = %v, want [%s]", l.WatchPaths(), want)
+	}
+}
+
+func TestScan(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	dir := filepath.Join(tmpDir, ".config", "gofi", "launchers")
+	writeFile(t, filepath.Join(dir, "itch"), `#!/bin/sh
+cat <<'EOF'
+[
+  {"id": "celeste", "name": "Celeste", "exec": "itch-launch celeste", "icon": "itch", "categories": ["Game"]},
+  {"name": "No Command"}
+]
+EOF
+`, 0755)
+	writeFile(t, filepath.Join(dir, "tools"), `#!/bin/sh
+echo '{"name": "Deploy", "exec": "deploy --prod", "comment": "Ship it", "terminal": true}'
+echo '{"name": "Logs", "exec": "logs"}'
+`, 0755)
+	writeFile(t, filepath.Join(dir, "broken"), "#!/bin/sh\necho 'token expired' >&2\nexit 3\n", 0755)
+	writeFile(t, filepath.Join(dir, "garbage"), "#!/bin/sh\necho 'Loading...'\n", 0755)
+	writeFile(t, filepath.Join(dir, "slow"), "#!/bin/sh\nexec sleep 10\n", 0755)
+
+	// Not plugins
+	writeFile(t, filepath.Join(dir, "README"), "#!/bin/sh\necho '[{\"name\": \"x\", \"exec\": \"x\"}]'\n", 0644)
+	writeFile(t, filepath.Join(dir, ".hidden"), "#!/bin/sh\necho '[{\"name\": \"x\", \"exec\": \"x\"}]'\n", 0755)
+
+	l := &Launcher{timeout: 500 * time.Millisecond}
+	start := time.Now()
+	entries, err := l.Scan(context.Background())
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Scan() took %v, the slow plugin should time out", elapsed)
+	}
+
+	// Entries of the working plugins, in plugin name order
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	if want := []string{"Celeste", "Deploy", "Logs"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Scan() entries = %v, want %v", names, want)
+	}
+
+	celeste, deploy := entries[0], entries[1]
+	if celeste.Exec != "itch-launch celeste" || celeste.Icon != "itch" || celeste.Comment != "Plugin: itch" {
+		t.Errorf("Celeste = %+v", celeste)
+	}
+	if celeste.GetAppType() != entry.AppTypeGame {
+		t.Errorf("Celeste GetAppType() = %v, want %v", celeste.GetAppType(), entry.AppTypeGame)
+	}
+	if !strings.HasPrefix(celeste.Path, "exec-") || celeste.Path == deploy.Path {
+		t.Errorf("Path = %q, want a unique exec- identifier", celeste.Path)
+	}
+	if deploy.Comment != "Ship it" || !deploy.Terminal {
+		t.Errorf("Deploy = %+v", deploy)
+	}
+
+	// One error per failing plugin
+	failed := make(map[string]error)
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var pluginErr *Error
+		if !errors.As(e, &pluginErr) {
+			t.Fatalf("Scan() error %v is not a plugin error", e)
+		}
+		failed[pluginErr.Plugin] = pluginErr.Err
+	}
+
+	tests := []struct {
+		plugin string
+		want   string
+		is     error
+	}{
+		{"broken", "exit status 3: token expired", nil},
+		{"garbage", "invalid output", ErrInvalidOutput},
+		{"itch", "entry 2 needs a name and exec", ErrInvalidEntry},
+		{"slow", "timed out", nil},
+	}
+	if len(failed) != len(tests) {
+		t.Errorf("Scan() failed plugins = %v, want %d", failed, len(tests))
+	}
+	for _, tt := range tests {
+		err := failed[tt.plugin]
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.plugin, err, tt.want)
+		}
+		if tt.is != nil && !errors.Is(err, tt.is) {
+			t.Errorf("%s: error = %v, want %v", tt.plugin, err, tt.is)
+		}
+	}
+}
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []string
+		wantErr bool
+	}{
+		{"Array", `[{"name": "A"}, {"name": "B"}]`, []string{"A", "B"}, false},
+		{"Objects", "{\"name\": \"A\"}\n{\"name\": \"B\"}\n", []string{"A", "B"}, false},
+		{"Empty", "\n", nil, false},
+		{"Empty array", "[]", nil, false},
+		{"Text", "no games found", nil, true},
+		{"Truncated", `[{"name": "A"`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := parseOutput([]byte(tt.output))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var names []string
+			for _, item := range items {
+				names = append(names, item.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("parseOutput() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   string
+	}{
+		{"Short", "  no\n network ", "no network"},
+		{"Long", strings.Repeat("a", maxStderr+10), strings.Repeat("a", maxStderr) + "…"},
+		// é takes two bytes, the last one would be cut in half
+		{"Multibyte", strings.Repeat("a", maxStderr-1) + "éé", strings.Repeat("a", maxStderr-1) + "…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorOutput(tt.stderr)
+			if got != tt.want {
+				t.Errorf("errorOutput() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("errorOutput() = %q, want valid UTF-8", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package plugin
+
+import "time"
+
+// DefaultTimeout bounds how long a single plugin may run
+const DefaultTimeout = 2 * time.Second
+
+// maxStderr is how much of a failed plugin's error output is reported
+const maxStderr = 512
+
+// Item is an entry as printed by a plugin
+type Item struct {
+	ID         string   `json:"id"` // Unique within the plugin, defaults to the name
+	Name       string   `json:"name"`
+	Exec       string   `json:"exec"` // Command line as in a desktop file's Exec key
+	Icon       string   `json:"icon"`
+	Comment    string   `json:"comment"`
+	Categories []string `json:"categories"`
+	Terminal   bool     `json:"terminal"`
+}
@@ -0,0 +1,255 @@
+package retroarch
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers"
+)
+
+func init() {
+	// Auto-register the RetroArch launcher
+	launchers.Register(&Launcher{})
+}
+
+// Launcher implements the GameLauncher interface for RetroArch playlists
+type Launcher struct{}
+
+// Name returns the launcher identifier
+func (l *Launcher) Name() string {
+	return "retroarch"
+}
+
+// WatchPaths returns the playlist directories
+func (l *Launcher) WatchPaths() []string {
+	var paths []string
+	for _, inst := range installations() {
+		paths = append(paths, inst.playlistDir)
+	}
+	return paths
+}
+
+// Scan discovers the ROMs of the system playlists of native and Flatpak
+// RetroArch
+func (l *Launcher) Scan(ctx context.Context) ([]*entry.Entry, error) {
+	if os.Getenv("HOME") == "" {
+		return nil, fmt.Errorf("HOME environment variable not set")
+	}
+
+	entries := make([]*entry.Entry, 0)
+	seen := make(map[string]bool)
+
+	for _, inst := range installations() {
+		playlists, err := filepath.Glob(filepath.Join(inst.playlistDir, "*.lpl"))
+		if err != nil {
+			continue
+		}
+
+		for _, path := range playlists {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if isHistory(path) {
+				continue
+			}
+
+			playlist, err := parsePlaylist(path)
+			if err != nil {
+				// Playlists in the old line based format can't be read, a
+				// broken playlist shouldn't hide the others
+				if os.Getenv("DEBUG") == "1" {
+					fmt.Printf("Error reading playlist %s: %v\n", path, err)
+				}
+				continue
+			}
+
+			system := strings.TrimSuffix(filepath.Base(path), ".lpl")
+			for _, item := range playlist.Items {
+				e := itemToEntry(item, playlist, system, inst)
+				if e == nil || seen[e.Path] {
+					continue
+				}
+				seen[e.Path] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// installations returns the RetroArch config directories that exist,
+// native first
+func installations() []installation {
+	homeDir := os.Getenv("HOME")
+	candidates := []installation{
+		{configDir: filepath.Join(homeDir, ".config", "retroarch")},
+		{configDir: filepath.Join(homeDir, ".var", "app", flatpakID, "config", "retroarch"), flatpak: true},
+	}
+
+	var result []installation
+	seen := make(map[string]bool)
+	for _, inst := range candidates {
+		resolved, err := filepath.EvalSymlinks(inst.configDir)
+		if err != nil || seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+
+		settings := readSettings(filepath.Join(inst.configDir, "retroarch.cfg"))
+		inst.playlistDir = settingDir(settings, "playlist_directory", filepath.Join(inst.configDir, "playlists"))
+		inst.thumbnailsDir = settingDir(settings, "thumbnails_directory", filepath.Join(inst.configDir, "thumbnails"))
+		result = append(result, inst)
+	}
+	return result
+}
+
+// readSettings reads the key = "value" lines of retroarch.cfg. A missing
+// or unreadable file yields no settings, leaving every directory at its
+// default.
+func readSettings(path string) map[string]string {
+	settings := make(map[string]string)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return settings
+	}
+	defer file.Close()
+
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		key, value, ok := strings.Cut(lines.Text(), "=")
+		if !ok || strings.HasPrefix(strings.TrimSpace(key), "#") {
+			continue
+		}
+		settings[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return settings
+}
+
+// settingDir returns a directory setting, or fallback if it is unset
+func settingDir(settings map[string]string, key, fallback string) string {
+	dir := settings[key]
+	switch {
+	case dir == "" || dir == "default":
+		return fallback
+	case dir == "~" || strings.HasPrefix(dir, "~/"):
+		return filepath.Join(os.Getenv("HOME"), dir[1:])
+	}
+	return dir
+}
+
+// isHistory reports whether a playlist is one RetroArch maintains itself
+func isHistory(path string) bool {
+	for _, prefix := range historyPrefixes {
+		if strings.HasPrefix(filepath.Base(path), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePlaylist reads a JSON .lpl playlist
+func parsePlaylist(path string) (*Playlist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var playlist Playlist
+	if err := json.Unmarshal(data, &playlist); err != nil {
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+// itemToEntry converts a playlist item to an Entry, or returns nil if it
+// has no ROM or no core to run it with
+func itemToEntry(item PlaylistItem, playlist *Playlist, playlistName string, inst installation) *entry.Entry {
+	if item.Path == "" {
+		return nil
+	}
+
+	core := item.CorePath
+	if core == "" || core == coreDetect {
+		core = playlist.DefaultCorePath
+	}
+	if core == "" || core == coreDetect {
+		return nil // RetroArch would ask which core to use
+	}
+
+	// The system comes from the database the ROM was matched against,
+	// which names the playlist it was added to
+	system := strings.TrimSuffix(item.DBName, ".lpl")
+	if system == "" {
+		system = playlistName
+	}
+
+	name := item.Label
+	if name == "" {
+		name = romName(item.Path)
+	}
+
+	args := fmt.Sprintf("-L %s %s", entry.QuoteExecArg(core), entry.QuoteExecArg(item.Path))
+	exec := "retroarch " + args
+	if inst.flatpak {
+		exec = fmt.Sprintf("flatpak run %s %s", flatpakID, args)
+	}
+
+	return &entry.Entry{
+		Name:       name,
+		Comment:    fmt.Sprintf("RetroArch Game: %s", system),
+		Exec:       exec,
+		Icon:       "retroarch",
+		Image:      findThumbnail(inst.thumbnailsDir, system, name),
+		Terminal:   false,
+		Categories: []string{"Game", system},
+		Path:       "retroarch-" + romID(inst.configDir, core, item.Path), // Unique identifier
+	}
+}
+
+// romName returns the file name of a ROM without extension. ROMs inside
+// archives are given as archive.zip#rom.ext.
+func romName(path string) string {
+	if _, inner, ok := strings.Cut(path, "#"); ok {
+		path = inner
+	}
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// romID identifies a ROM run with a core in an installation. ROM paths
+// are hashed since they may contain anything, including words GetAppType
+// looks for in entry paths.
+func romID(configDir, core, rom string) string {
+	sum := sha256.Sum256([]byte(configDir + "\x00" + core + "\x00" + rom))
+	return hex.EncodeToString(sum[:8])
+}
+
+// thumbnailReplacer replaces the characters RetroArch doesn't allow in
+// thumbnail file names
+var thumbnailReplacer = strings.NewReplacer(
+	"&", "_", "*", "_", "/", "_", ":", "_", "`", "_",
+	"<", "_", ">", "_", "?", "_", `\`, "_", "|", "_",
+)
+
+// findThumbnail returns the downloaded box art of a ROM, then its title
+// screen
+func findThumbnail(thumbnailsDir, system, label string) string {
+	name := thumbnailReplacer.Replace(label) + ".png"
+	for _, kind := range []string{"Named_Boxarts", "Named_Titles"} {
+		path := filepath.Join(thumbnailsDir, system, kind, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+package retroarch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+const snesPlaylist = `{
+  "version": "1.5",
+  "default_core_path": "/usr/lib/libretro/snes9x_libretro.so",
+  "default_core_name": "Nintendo - SNES / SFC (Snes9x - Current)",
+  "items": [
+    {
+      "path": "/roms/snes/Super Mario World (USA).sfc",
+      "label": "Super Mario World (USA)",
+      "core_path": "DETECT",
+      "core_name": "DETECT",
+      "crc32": "B19ED489|crc",
+      "db_name": "Nintendo - Super Nintendo Entertainment System.lpl"
+    },
+    {
+      "path": "/roms/snes/collection.zip#Zelda: A Link to the Past.sfc",
+      "label": "",
+      "core_path": "/usr/lib/libretro/bsnes_libretro.so",
+      "core_name": "Nintendo - SNES / SFC (bsnes)",
+      "db_name": ""
+    }
+  ]
+}`
+
+func TestLauncherName(t *testing.T) {
+	l := &Launcher{}
+	if l.Name() != "retroarch" {
+		t.Errorf("Name() = %v, want %v", l.Name(), "retroarch")
+	}
+}
+
+func TestScanNoRetroArch(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Errorf("Scan() error = %v, want nil", err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("Scan() returned entries when RetroArch isn't installed")
+	}
+}
+
+func TestScan(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	configDir := filepath.Join(tmpDir, ".config", "retroarch")
+	playlistDir := filepath.Join(configDir, "playlists")
+	writeFile(t, filepath.Join(playlistDir, "Nintendo - Super Nintendo Entertainment System.lpl"), snesPlaylist)
+
+	// History repeats content, broken and old style playlists are skipped
+	writeFile(t, filepath.Join(playlistDir, "content_history.lpl"), snesPlaylist)
+	writeFile(t, filepath.Join(playlistDir, "Broken.lpl"), "{")
+	writeFile(t, filepath.Join(playlistDir, "Old.lpl"), "/roms/a.sfc\nA\nDETECT\nDETECT\n0|crc\nOld.lpl\n")
+
+	// No core to run it with
+	writeFile(t, filepath.Join(playlistDir, "Sega - Mega Drive - Genesis.lpl"), `{
+  "default_core_path": "",
+  "items": [{"path": "/roms/md/Sonic.md", "label": "Sonic", "core_path": "DETECT"}]
+}`)
+
+	boxart := filepath.Join(configDir, "thumbnails", "Nintendo - Super Nintendo Entertainment System", "Named_Boxarts", "Super Mario World (USA).png")
+	writeFile(t, boxart, "")
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Scan() returned %d entries, want 2", len(entries))
+	}
+
+	mario, zelda := entries[0], entries[1]
+	system := "Nintendo - Super Nintendo Entertainment System"
+
+	if mario.Name != "Super Mario World (USA)" {
+		t.Errorf("Name = %q", mario.Name)
+	}
+	if want := `retroarch -L /usr/lib/libretro/snes9x_libretro.so "/roms/snes/Super Mario World (USA).sfc"`; mario.Exec != want {
+		t.Errorf("Exec = %q, want %q", mario.Exec, want)
+	}
+	if mario.Image != boxart {
+		t.Errorf("Image = %q, want %q", mario.Image, boxart)
+	}
+	if want := []string{"Game", system}; !reflect.DeepEqual(mario.Categories, want) {
+		t.Errorf("Categories = %v, want %v", mario.Categories, want)
+	}
+	if !strings.HasPrefix(mario.Path, "retroarch-") || mario.Path == zelda.Path {
+		t.Errorf("Path = %q, want a unique retroarch- identifier", mario.Path)
+	}
+
+	// No label or database: named after the ROM, system from the playlist
+	if zelda.Name != "Zelda: A Link to the Past" {
+		t.Errorf("Name = %q, want the ROM name", zelda.Name)
+	}
+	if want := `retroarch -L /usr/lib/libretro/bsnes_libretro.so "/roms/snes/collection.zip#Zelda: A Link to the Past.sfc"`; zelda.Exec != want {
+		t.Errorf("Exec = %q, want %q", zelda.Exec, want)
+	}
+	if zelda.Icon != "retroarch" || zelda.Image != "" {
+		t.Errorf("Icon = %q, Image = %q, want the retroarch icon only", zelda.Icon, zelda.Image)
+	}
+	if want := []string{"Game", system}; !reflect.DeepEqual(zelda.Categories, want) {
+		t.Errorf("Categories = %v, want %v", zelda.Categories, want)
+	}
+}
+
+func TestScanCustomPlaylistDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	configDir := filepath.Join(tmpDir, ".var", "app", flatpakID, "config", "retroarch")
+	writeFile(t, filepath.Join(configDir, "retroarch.cfg"), `# Generated
+playlist_directory = "~/Games/playlists"
+thumbnails_directory = "default"
+`)
+	writeFile(t, filepath.Join(tmpDir, "Games", "playlists", "SNES.lpl"), snesPlaylist)
+
+	l := &Launcher{}
+	if paths := l.WatchPaths(); len(paths) != 1 || paths[0] != filepath.Join(tmpDir, "Games", "playlists") {
+		t.Errorf("WatchPaths() = %v, want the configured playlist directory", paths)
+	}
+
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Scan() returned %d entries, want 2", len(entries))
+	}
+	if want := "flatpak run org.libretro.RetroArch -L "; !strings.HasPrefix(entries[0].Exec, want) {
+		t.Errorf("Exec = %q, want prefix %q", entries[0].Exec, want)
+	}
+}
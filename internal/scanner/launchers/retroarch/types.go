@@ -0,0 +1,35 @@
+package retroarch
+
+// flatpakID is the Flatpak application ID of RetroArch
+const flatpakID = "org.libretro.RetroArch"
+
+// coreDetect is the core path of items whose core RetroArch asks for at
+// launch time
+const coreDetect = "DETECT"
+
+// historyPrefixes mark the playlists RetroArch maintains itself, such as
+// content_history.lpl, which repeat content from the system playlists
+var historyPrefixes = []string{"content_", "builtin_"}
+
+// Playlist is the part of a JSON .lpl playlist we use
+type Playlist struct {
+	DefaultCorePath string         `json:"default_core_path"`
+	Items           []PlaylistItem `json:"items"`
+}
+
+// PlaylistItem is a ROM in a playlist
+type PlaylistItem struct {
+	Path     string `json:"path"`
+	Label    string `json:"label"`
+	CorePath string `json:"core_path"`
+	DBName   string `json:"db_name"`
+}
+
+// installation is a RetroArch config directory, the directories its
+// config points to, and how to run it
+type installation struct {
+	configDir     string
+	playlistDir   string
+	thumbnailsDir string
+	flatpak       bool
+}
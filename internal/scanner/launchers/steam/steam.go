@@ -0,0 +1,303 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers/steam/vdf"
+)
+
+func init() {
+	// Auto-register the Steam launcher
+	launchers.Register(&Launcher{})
+}
+
+// Launcher implements the GameLauncher interface for Steam
+type Launcher struct{}
+
+// Name returns the launcher identifier
+func (l *Launcher) Name() string {
+	return "steam"
+}
+
+// WatchPaths returns the steamapps directories holding the app manifests
+func (l *Launcher) WatchPaths() []string {
+	var paths []string
+	for _, inst := range installations() {
+		for _, library := range libraryFolders(inst.root) {
+			paths = append(paths, filepath.Join(library, "steamapps"))
+		}
+	}
+	return paths
+}
+
+// Scan discovers installed games from native and Flatpak Steam
+func (l *Launcher) Scan(ctx context.Context) ([]*entry.Entry, error) {
+	if os.Getenv("HOME") == "" {
+		return nil, fmt.Errorf("HOME environment variable not set")
+	}
+
+	entries := make([]*entry.Entry, 0)
+	seen := make(map[string]bool)
+
+	for _, inst := range installations() {
+		stats := playStats(inst.root)
+
+		for _, library := range libraryFolders(inst.root) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			manifests, err := filepath.Glob(filepath.Join(library, "steamapps", "appmanifest_*.acf"))
+			if err != nil {
+				continue
+			}
+
+			for _, path := range manifests {
+				manifest, err := parseManifest(path)
+				if err != nil || seen[manifest.AppID] || !isGame(manifest) {
+					continue
+				}
+				seen[manifest.AppID] = true
+				entries = append(entries, gameToEntry(manifest, inst, stats[manifest.AppID]))
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// installations returns the Steam root directories that exist, native
+// installs first. Symlinked roots such as ~/.steam/steam are only listed once.
+func installations() []installation {
+	homeDir := os.Getenv("HOME")
+	candidates := []installation{
+		{root: filepath.Join(homeDir, ".steam", "steam")},
+		{root: filepath.Join(homeDir, ".steam", "root")},
+		{root: filepath.Join(homeDir, ".local", "share", "Steam")},
+		{root: filepath.Join(homeDir, ".var", "app", flatpakID, ".local", "share", "Steam"), flatpak: true},
+		{root: filepath.Join(homeDir, ".var", "app", flatpakID, "data", "Steam"), flatpak: true},
+	}
+
+	var result []installation
+	seen := make(map[string]bool)
+	for _, inst := range candidates {
+		resolved, err := filepath.EvalSymlinks(inst.root)
+		if err != nil || seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		inst.root = resolved
+		result = append(result, inst)
+	}
+	return result
+}
+
+// libraryFolders returns the library directories of a Steam root, which
+// always include the root itself
+func libraryFolders(root string) []string {
+	folders := []string{root}
+	seen := map[string]bool{root: true}
+
+	add := func(path string) {
+		if path == "" {
+			return
+		}
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		if !seen[path] {
+			seen[path] = true
+			folders = append(folders, path)
+		}
+	}
+
+	data, err := vdf.ParseFile(filepath.Join(root, "steamapps", "libraryfolders.vdf"))
+	if err != nil {
+		return folders
+	}
+
+	for _, folder := range data.Get("libraryfolders").Children {
+		if folder.IsSection() {
+			add(folder.String("path"))
+			continue
+		}
+		// Older format: "1" "/path/to/library"
+		if _, err := strconv.Atoi(folder.Key); err == nil {
+			add(folder.Value)
+		}
+	}
+
+	return folders
+}
+
+// parseManifest reads an appmanifest_<id>.acf file
+func parseManifest(path string) (*AppManifest, error) {
+	data, err := vdf.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := data.Get("AppState")
+	if state == nil {
+		return nil, fmt.Errorf("%s: missing AppState", path)
+	}
+
+	manifest := &AppManifest{
+		AppID:      state.String("appid"),
+		Name:       state.String("name"),
+		InstallDir: state.String("installdir"),
+	}
+	manifest.StateFlags, _ = strconv.Atoi(state.String("StateFlags"))
+	manifest.LastPlayed, _ = strconv.ParseInt(state.String("LastPlayed"), 10, 64)
+
+	if manifest.AppID == "" || manifest.Name == "" {
+		return nil, fmt.Errorf("%s: missing appid or name", path)
+	}
+	return manifest, nil
+}
+
+// playStats returns the play statistics of each app, keyed by app ID.
+// Steam records them per user; the time played is added up across users
+// and the last played time is the latest of them.
+func playStats(root string) map[string]PlayStats {
+	stats := make(map[string]PlayStats)
+
+	configs, _ := filepath.Glob(filepath.Join(root, "userdata", "*", "config", "localconfig.vdf"))
+	for _, path := range configs {
+		data, err := vdf.ParseFile(path)
+		if err != nil {
+			if os.Getenv("DEBUG") == "1" {
+				fmt.Printf("Error reading steam local config %s: %v\n", path, err)
+			}
+			continue
+		}
+
+		apps := data.Get("UserLocalConfigStore").Get("Software").Get("Valve").Get("Steam").Get("apps")
+		if apps == nil {
+			continue
+		}
+		for _, app := range apps.Children {
+			if !app.IsSection() {
+				continue
+			}
+			lastPlayed, _ := strconv.ParseInt(app.String("LastPlayed"), 10, 64)
+			minutes, _ := strconv.ParseInt(app.String("Playtime"), 10, 64)
+
+			stat := stats[app.Key]
+			if played := time.Unix(lastPlayed, 0); lastPlayed > 0 && played.After(stat.LastPlayed) {
+				stat.LastPlayed = played
+			}
+			stat.Playtime += time.Duration(minutes) * time.Minute
+			stats[app.Key] = stat
+		}
+	}
+
+	return stats
+}
+
+// isGame reports whether a manifest is an installed game rather than a
+// tool, runtime or redistributable
+func isGame(manifest *AppManifest) bool {
+	if manifest.StateFlags&stateFullyInstalled == 0 {
+		return false
+	}
+	if toolAppIDs[manifest.AppID] {
+		return false
+	}
+	for _, prefix := range toolNamePrefixes {
+		if strings.HasPrefix(manifest.Name, prefix) {
+			return false
+		}
+	}
+	return manifest.Name != "Proton"
+}
+
+// gameToEntry converts a Steam app manifest and the app's play statistics
+// to an Entry
+func gameToEntry(manifest *AppManifest, inst installation, stats PlayStats) *entry.Entry {
+	uri := fmt.Sprintf("steam://rungameid/%s", manifest.AppID)
+	exec := "steam " + uri
+	if inst.flatpak {
+		exec = fmt.Sprintf("flatpak run %s %s", flatpakID, uri)
+	}
+
+	icon := findIcon(inst.root, manifest.AppID)
+	if icon == "" {
+		icon = "applications-games" // Generic game icon
+	}
+
+	e := &entry.Entry{
+		Name:       manifest.Name,
+		Comment:    fmt.Sprintf("Steam Game: %s", manifest.InstallDir),
+		Exec:       exec,
+		Icon:       icon,
+		Image:      findCover(inst.root, manifest.AppID),
+		Terminal:   false,
+		Categories: []string{"Game"},
+		Path:       fmt.Sprintf("steam-%s", manifest.AppID), // Unique identifier
+	}
+
+	// Without a local config the manifest still knows when the game last ran
+	lastPlayed := stats.LastPlayed
+	if lastPlayed.IsZero() && manifest.LastPlayed > 0 {
+		lastPlayed = time.Unix(manifest.LastPlayed, 0)
+	}
+	e.SetLastPlayed(lastPlayed)
+	e.SetPlaytime(stats.Playtime)
+	e.SetMeta(entry.MetaStore, "steam")
+
+	return e
+}
+
+// artworkHash matches the hash-named icon in the per-app artwork directory
+var artworkHash = regexp.MustCompile(`^[0-9a-f]{40}\.jpg$`)
+
+// findIcon returns the cached square icon of an app. Newer Steam clients
+// keep artwork in a directory per app, named after its hash; older ones use
+// flat files.
+func findIcon(root, appID string) string {
+	cacheDir := filepath.Join(root, "appcache", "librarycache")
+	appDir := filepath.Join(cacheDir, appID)
+
+	if files, err := os.ReadDir(appDir); err == nil {
+		for _, f := range files {
+			if !f.IsDir() && artworkHash.MatchString(f.Name()) {
+				return filepath.Join(appDir, f.Name())
+			}
+		}
+	}
+
+	return existingFile(filepath.Join(cacheDir, appID+"_icon.jpg"))
+}
+
+// findCover returns the cached portrait capsule of an app, then its header
+func findCover(root, appID string) string {
+	cacheDir := filepath.Join(root, "appcache", "librarycache")
+	appDir := filepath.Join(cacheDir, appID)
+
+	return existingFile(
+		filepath.Join(appDir, "library_600x900.jpg"),
+		filepath.Join(cacheDir, appID+"_library_600x900.jpg"),
+		filepath.Join(appDir, "header.jpg"),
+		filepath.Join(cacheDir, appID+"_header.jpg"),
+	)
+}
+
+// existingFile returns the first of paths that exists
+func existingFile(paths ...string) string {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
@@ -0,0 +1,253 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func writeManifest(t *testing.T, library, appID, name string, stateFlags int) {
+	t.Helper()
+	content := fmt.Sprintf(`"AppState"
+{
+	"appid"		"%s"
+	"name"		"%s"
+	"StateFlags"		"%d"
+	"installdir"		"%s"
+}
+`, appID, name, stateFlags, name)
+	writeFile(t, filepath.Join(library, "steamapps", "appmanifest_"+appID+".acf"), content)
+}
+
+func TestLauncherName(t *testing.T) {
+	l := &Launcher{}
+	if l.Name() != "steam" {
+		t.Errorf("Name() = %v, want %v", l.Name(), "steam")
+	}
+}
+
+func TestScanNoSteam(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Errorf("Scan() error = %v, want nil", err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("Scan() returned entries when Steam isn't installed")
+	}
+}
+
+func TestScanWithLibraries(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	// Native Steam with a second library, reachable through ~/.steam/steam
+	root := filepath.Join(tmpDir, ".local", "share", "Steam")
+	extra := filepath.Join(tmpDir, "games")
+	os.MkdirAll(filepath.Join(tmpDir, ".steam"), 0755)
+	os.MkdirAll(root, 0755)
+	os.Symlink(root, filepath.Join(tmpDir, ".steam", "steam"))
+
+	writeFile(t, filepath.Join(root, "steamapps", "libraryfolders.vdf"), fmt.Sprintf(`"libraryfolders"
+{
+	"0"
+	{
+		"path"		"%s"
+	}
+	"1"
+	{
+		"path"		"%s"
+	}
+}
+`, root, extra))
+
+	writeManifest(t, root, "620", "Portal 2", 4)
+	writeManifest(t, root, "1493710", "Proton Experimental", 4)
+	writeManifest(t, root, "2348590", "Proton 8.0", 4)
+	writeManifest(t, root, "228980", "Steamworks Common Redistributables", 4)
+	writeManifest(t, extra, "570", "Dota 2", 4)
+	writeManifest(t, extra, "440", "Team Fortress 2", 6)  // Update required, still installed
+	writeManifest(t, extra, "730", "Counter-Strike 2", 2) // Not installed yet
+
+	// Artwork in both layouts
+	iconPath := filepath.Join(root, "appcache", "librarycache", "620", "0123456789abcdef0123456789abcdef01234567.jpg")
+	writeFile(t, iconPath, "jpg")
+	headerPath := filepath.Join(root, "appcache", "librarycache", "620", "header.jpg")
+	writeFile(t, headerPath, "jpg")
+	coverPath := filepath.Join(root, "appcache", "librarycache", "570_library_600x900.jpg")
+	writeFile(t, coverPath, "jpg")
+
+	// Flatpak Steam
+	flatpakRoot := filepath.Join(tmpDir, ".var", "app", flatpakID, ".local", "share", "Steam")
+	writeManifest(t, flatpakRoot, "105600", "Terraria", 4)
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	byName := make(map[string]int)
+	for i, e := range entries {
+		byName[e.Name] = i
+	}
+	if len(entries) != 4 {
+		t.Errorf("Scan() returned %d entries, want 4: %v", len(entries), byName)
+	}
+
+	tests := []struct {
+		name  string
+		exec  string
+		icon  string
+		image string
+	}{
+		{"Portal 2", "steam steam://rungameid/620", iconPath, headerPath},
+		{"Dota 2", "steam steam://rungameid/570", "applications-games", coverPath},
+		{"Team Fortress 2", "steam steam://rungameid/440", "applications-games", ""},
+		{"Terraria", "flatpak run com.valvesoftware.Steam steam://rungameid/105600", "applications-games", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, ok := byName[tt.name]
+			if !ok {
+				t.Fatalf("%s missing", tt.name)
+			}
+			e := entries[i]
+			if e.Exec != tt.exec {
+				t.Errorf("Exec = %q, want %q", e.Exec, tt.exec)
+			}
+			if e.Icon != tt.icon {
+				t.Errorf("Icon = %q, want %q", e.Icon, tt.icon)
+			}
+			if e.Image != tt.image {
+				t.Errorf("Image = %q, want %q", e.Image, tt.image)
+			}
+			if len(e.Categories) != 1 || e.Categories[0] != "Game" {
+				t.Errorf("Categories = %v, want [Game]", e.Categories)
+			}
+		})
+	}
+}
+
+func TestScanPlayStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	defer os.Unsetenv("HOME")
+
+	root := filepath.Join(tmpDir, ".local", "share", "Steam")
+	writeManifest(t, root, "620", "Portal 2", 4)
+	writeManifest(t, root, "440", "Team Fortress 2", 4)
+	writeFile(t, filepath.Join(root, "steamapps", "appmanifest_570.acf"), `"AppState"
+{
+	"appid"		"570"
+	"name"		"Dota 2"
+	"StateFlags"		"4"
+	"LastPlayed"		"1690000000"
+}
+`)
+
+	// Two users played Portal 2
+	localConfig := `"UserLocalConfigStore"
+{
+	"Software"
+	{
+		"Valve"
+		{
+			"Steam"
+			{
+				"apps"
+				{
+					"620"
+					{
+						"LastPlayed"		"%d"
+						"Playtime"		"%d"
+					}
+				}
+			}
+		}
+	}
+}
+`
+	writeFile(t, filepath.Join(root, "userdata", "1001", "config", "localconfig.vdf"), fmt.Sprintf(localConfig, 1700000000, 90))
+	writeFile(t, filepath.Join(root, "userdata", "1002", "config", "localconfig.vdf"), fmt.Sprintf(localConfig, 1710000000, 30))
+
+	l := &Launcher{}
+	entries, err := l.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		lastPlayed time.Time
+		playtime   time.Duration
+	}{
+		{"Portal 2", time.Unix(1710000000, 0), 2 * time.Hour},
+		{"Dota 2", time.Unix(1690000000, 0), 0},
+		{"Team Fortress 2", time.Time{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e *entry.Entry
+			for _, candidate := range entries {
+				if candidate.Name == tt.name {
+					e = candidate
+				}
+			}
+			if e == nil {
+				t.Fatalf("%s missing", tt.name)
+			}
+			if !e.LastPlayed().Equal(tt.lastPlayed) {
+				t.Errorf("LastPlayed() = %v, want %v", e.LastPlayed(), tt.lastPlayed)
+			}
+			if e.Playtime() != tt.playtime {
+				t.Errorf("Playtime() = %v, want %v", e.Playtime(), tt.playtime)
+			}
+			if e.Meta(entry.MetaStore) != "steam" {
+				t.Errorf("Meta(MetaStore) = %q, want steam", e.Meta(entry.MetaStore))
+			}
+		})
+	}
+}
+
+func TestIsGame(t *testing.T) {
+	tests := []struct {
+		manifest AppManifest
+		want     bool
+	}{
+		{AppManifest{AppID: "620", Name: "Portal 2", StateFlags: 4}, true},
+		{AppManifest{AppID: "620", Name: "Portal 2", StateFlags: 6}, true},
+		{AppManifest{AppID: "620", Name: "Portal 2", StateFlags: 2}, false},
+		{AppManifest{AppID: "1628350", Name: "Steam Linux Runtime 3.0 (sniper)", StateFlags: 4}, false},
+		{AppManifest{AppID: "9999999", Name: "Proton 9.0", StateFlags: 4}, false},
+		{AppManifest{AppID: "9999998", Name: "Protonwar", StateFlags: 4}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.manifest.Name, func(t *testing.T) {
+			if got := isGame(&tt.manifest); got != tt.want {
+				t.Errorf("isGame(%+v) = %v, want %v", tt.manifest, got, tt.want)
+			}
+		})
+	}
+}
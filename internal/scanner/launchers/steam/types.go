@@ -0,0 +1,52 @@
+package steam
+
+import "time"
+
+// stateFullyInstalled is the StateFlags bit set once an app is installed
+const stateFullyInstalled = 4
+
+// flatpakID is the Flatpak application ID of Steam
+const flatpakID = "com.valvesoftware.Steam"
+
+// AppManifest is the part of an appmanifest_<id>.acf file we use
+type AppManifest struct {
+	AppID      string
+	Name       string
+	InstallDir string
+	StateFlags int
+	LastPlayed int64 // Unix time, 0 if never played
+}
+
+// PlayStats is the time a user spent in an app, from their localconfig.vdf
+type PlayStats struct {
+	LastPlayed time.Time
+	Playtime   time.Duration
+}
+
+// installation is a Steam root directory and how to run its client
+type installation struct {
+	root    string
+	flatpak bool
+}
+
+// toolAppIDs are compatibility tools, runtimes and redistributables that
+// Steam installs as apps but that can't be played
+var toolAppIDs = map[string]bool{
+	"228980":  true, // Steamworks Common Redistributables
+	"1070560": true, // Steam Linux Runtime 1.0 (scout)
+	"1391110": true, // Steam Linux Runtime 2.0 (soldier)
+	"1628350": true, // Steam Linux Runtime 3.0 (sniper)
+	"1493710": true, // Proton Experimental
+	"2180100": true, // Proton Hotfix
+	"1826330": true, // Proton EasyAntiCheat Runtime
+	"1161040": true, // Proton BattlEye Runtime
+}
+
+// toolNamePrefixes catch tools missing from toolAppIDs, such as each
+// numbered Proton release
+var toolNamePrefixes = []string{
+	"Proton ",
+	"Steam Linux Runtime",
+	"Steamworks Common Redistributables",
+	"Steamworks SDK Redist",
+}
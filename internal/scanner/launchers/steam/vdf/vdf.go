@@ -0,0 +1,277 @@
+// Package vdf parses Valve's KeyValues text format, used by Steam for files
+// such as libraryfolders.vdf and appmanifest_*.acf
+// See: https://developer.valvesoftware.com/wiki/KeyValues
+package vdf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// KeyValue is a key with either a string value or child key values
+type KeyValue struct {
+	Key      string
+	Value    string
+	Children []*KeyValue // nil for string values
+}
+
+// SyntaxError describes malformed input
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+// Error implements the error interface
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("vdf: line %d: %s", e.Line, e.Msg)
+}
+
+// IsSection reports whether the key holds child key values
+func (kv *KeyValue) IsSection() bool {
+	return kv.Children != nil
+}
+
+// Get returns the first child with the given key, compared case-insensitively
+// as Steam does, or nil
+func (kv *KeyValue) Get(key string) *KeyValue {
+	if kv == nil {
+		return nil
+	}
+	for _, child := range kv.Children {
+		if strings.EqualFold(child.Key, key) {
+			return child
+		}
+	}
+	return nil
+}
+
+// String returns the string value of the first child with the given key,
+// or an empty string
+func (kv *KeyValue) String(key string) string {
+	if child := kv.Get(key); child != nil && !child.IsSection() {
+		return child.Value
+	}
+	return ""
+}
+
+// ParseFile parses a KeyValues file
+func ParseFile(path string) (*KeyValue, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads KeyValues text and returns a root section holding the
+// top-level key values
+func Parse(r io.Reader) (*KeyValue, error) {
+	p := &parser{reader: bufio.NewReader(r), line: 1}
+
+	root := &KeyValue{Children: []*KeyValue{}}
+	if err := p.parseSection(root, false); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// tokenKind identifies a lexical token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenCondition
+)
+
+// parser reads tokens from the input
+type parser struct {
+	reader *bufio.Reader
+	line   int
+}
+
+// parseSection reads key values into section until the closing brace, or
+// until the end of input for the root section
+func (p *parser) parseSection(section *KeyValue, nested bool) error {
+	for {
+		kind, key, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case tokenEOF:
+			if nested {
+				return p.errorf("unexpected end of input, missing }")
+			}
+			return nil
+		case tokenClose:
+			if !nested {
+				return p.errorf("unexpected }")
+			}
+			return nil
+		case tokenOpen:
+			return p.errorf("unexpected {, missing key")
+		case tokenCondition:
+			continue // Platform conditions are ignored
+		}
+
+		kind, value, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		kv := &KeyValue{Key: key}
+		switch kind {
+		case tokenString:
+			kv.Value = value
+		case tokenOpen:
+			kv.Children = []*KeyValue{}
+			if err := p.parseSection(kv, true); err != nil {
+				return err
+			}
+		default:
+			return p.errorf("missing value for key %q", key)
+		}
+		section.Children = append(section.Children, kv)
+	}
+}
+
+// next returns the next token, skipping whitespace and comments
+func (p *parser) next() (tokenKind, string, error) {
+	for {
+		r, err := p.read()
+		if err == io.EOF {
+			return tokenEOF, "", nil
+		}
+		if err != nil {
+			return tokenEOF, "", err
+		}
+
+		switch {
+		case r == '\n':
+			p.line++
+		case r == ' ' || r == '\t' || r == '\r' || r == '\uFEFF':
+		case r == '{':
+			return tokenOpen, "", nil
+		case r == '}':
+			return tokenClose, "", nil
+		case r == '"':
+			value, err := p.readQuoted()
+			return tokenString, value, err
+		case r == '[':
+			_, err := p.readUntil(']')
+			return tokenCondition, "", err
+		case r == '/' && p.peek() == '/':
+			if _, err := p.readUntil('\n'); err != nil {
+				return tokenEOF, "", err
+			}
+			p.line++
+		default:
+			return tokenString, string(r) + p.readUnquoted(), nil
+		}
+	}
+}
+
+// readQuoted reads a quoted string after the opening quote
+func (p *parser) readQuoted() (string, error) {
+	var b strings.Builder
+	for {
+		r, err := p.read()
+		if err != nil {
+			return "", p.errorf("unterminated string")
+		}
+
+		switch r {
+		case '"':
+			return b.String(), nil
+		case '\n':
+			p.line++
+			b.WriteRune(r)
+		case '\\':
+			escaped, err := p.read()
+			if err != nil {
+				return "", p.errorf("unterminated string")
+			}
+			switch escaped {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case '\\', '"':
+				b.WriteRune(escaped)
+			default:
+				// Unknown escapes are kept, Windows paths rely on it
+				b.WriteRune('\\')
+				b.WriteRune(escaped)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// readUnquoted reads a string delimited by whitespace, braces or quotes
+func (p *parser) readUnquoted() string {
+	var b strings.Builder
+	for {
+		r, err := p.read()
+		if err != nil {
+			return b.String()
+		}
+		if strings.ContainsRune(" \t\r\n{}\"", r) {
+			p.reader.UnreadRune()
+			return b.String()
+		}
+		b.WriteRune(r)
+	}
+}
+
+// readUntil skips input up to and including the delimiter
+func (p *parser) readUntil(delim rune) (string, error) {
+	var b strings.Builder
+	for {
+		r, err := p.read()
+		if err == io.EOF {
+			if delim == '\n' {
+				return b.String(), nil
+			}
+			return "", p.errorf("missing %q", delim)
+		}
+		if err != nil {
+			return "", err
+		}
+		if r == delim {
+			return b.String(), nil
+		}
+		b.WriteRune(r)
+	}
+}
+
+// read returns the next rune
+func (p *parser) read() (rune, error) {
+	r, _, err := p.reader.ReadRune()
+	return r, err
+}
+
+// peek returns the next rune without consuming it
+func (p *parser) peek() rune {
+	r, _, err := p.reader.ReadRune()
+	if err != nil {
+		return 0
+	}
+	p.reader.UnreadRune()
+	return r
+}
+
+// errorf returns a SyntaxError for the current line
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
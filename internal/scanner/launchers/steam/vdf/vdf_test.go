@@ -0,0 +1,99 @@
+package vdf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := `// Steam library folders
+"libraryfolders"
+{
+	"0"
+	{
+		"path"		"/home/user/.local/share/Steam"
+		"label"		""
+		"apps"
+		{
+			"620"		"12345"
+		}
+	}
+	"1"
+	{
+		"path"		"/mnt/games/Steam Library"
+	}
+	unquoted value [$LINUX]
+	"escaped"	"C:\\Games\t\"quoted\"\n"
+	"windows"	"D:\Games"
+}
+`
+
+	root, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	folders := root.Get("LibraryFolders")
+	if folders == nil || !folders.IsSection() {
+		t.Fatalf("Get(LibraryFolders) = %+v, want section", folders)
+	}
+	if len(folders.Children) != 5 {
+		t.Fatalf("len(Children) = %d, want 5", len(folders.Children))
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Nested value", folders.Get("0").String("path"), "/home/user/.local/share/Steam"},
+		{"Empty value", folders.Get("0").String("label"), ""},
+		{"Deeply nested", folders.Get("0").Get("apps").String("620"), "12345"},
+		{"Spaces in value", folders.Get("1").String("path"), "/mnt/games/Steam Library"},
+		{"Unquoted", folders.String("unquoted"), "value"},
+		{"Escapes", folders.String("escaped"), "C:\\Games\t\"quoted\"\n"},
+		{"Unknown escape", folders.String("windows"), `D:\Games`},
+		{"Missing key", folders.String("missing"), ""},
+		{"Section as string", folders.String("0"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	if root.Get("missing").Get("deeper") != nil {
+		t.Error("Get() on a missing key should return nil")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLine int
+	}{
+		{"Missing close brace", "\"a\"\n{\n\"b\" \"c\"\n", 4},
+		{"Unexpected close brace", "\"a\" \"b\"\n}", 2},
+		{"Unterminated string", "\"a\" \"b", 1},
+		{"Missing value", "\"a\"\n{\n\"b\"\n}", 4},
+		{"Missing key", "{ }", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse() error = %v, want SyntaxError", err)
+			}
+			if syntaxErr.Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d (%v)", syntaxErr.Line, tt.wantLine, err)
+			}
+		})
+	}
+}
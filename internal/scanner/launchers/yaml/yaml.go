@@ -0,0 +1,119 @@
+// Package yaml reads the scalar values of the block style YAML configs
+// written by launchers such as Lutris and Bottles. It is not a general
+// YAML parser: lists, flow collections and multi-line strings are skipped.
+package yaml
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseFile reads a YAML file, see Parse
+func ParseFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse returns the scalar values of a YAML document keyed by their dotted
+// path, such as "game.exe" for the exe key of the game mapping
+func Parse(r io.Reader) (map[string]string, error) {
+	type section struct {
+		indent int
+		key    string
+	}
+
+	values := make(map[string]string)
+	var sections []section
+	skipIndent := -1 // Lines indented deeper belong to a skipped block
+
+	lines := bufio.NewScanner(r)
+	for lines.Scan() {
+		line := strings.TrimRight(lines.Text(), " \t\r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || trimmed == "---" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indent := len(line) - len(trimmed)
+		if skipIndent >= 0 {
+			if indent > skipIndent {
+				continue
+			}
+			skipIndent = -1
+		}
+
+		// Leave the sections this line is not nested in
+		for len(sections) > 0 && sections[len(sections)-1].indent >= indent {
+			sections = sections[:len(sections)-1]
+		}
+
+		if trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+			skipIndent = indent // List item, with any continuation lines
+			continue
+		}
+
+		key, value, ok := splitKey(trimmed)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case value == "":
+			sections = append(sections, section{indent: indent, key: key})
+		case value[0] == '|' || value[0] == '>':
+			skipIndent = indent
+		case value[0] == '{' || value[0] == '[':
+			// Flow collection, usually an empty {} or []
+		default:
+			path := key
+			for i := len(sections) - 1; i >= 0; i-- {
+				path = sections[i].key + "." + path
+			}
+			values[path] = parseScalar(value)
+		}
+	}
+
+	return values, lines.Err()
+}
+
+// splitKey splits a "key: value" line
+func splitKey(line string) (string, string, bool) {
+	key, value, found := strings.Cut(line, ": ")
+	if !found {
+		if !strings.HasSuffix(line, ":") {
+			return "", "", false
+		}
+		key, value = strings.TrimSuffix(line, ":"), ""
+	}
+	return parseScalar(key), strings.TrimSpace(value), true
+}
+
+// parseScalar returns the string value of a plain or quoted scalar
+func parseScalar(value string) string {
+	switch {
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		return value[1 : len(value)-1]
+	}
+
+	// Plain scalars end at a comment
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	if value == "~" || value == "null" {
+		return ""
+	}
+	return value
+}
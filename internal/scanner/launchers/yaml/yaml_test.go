@@ -0,0 +1,41 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := `# Lutris game config
+game:
+  args: '--windowed ''fast'''
+  exe: "/games/My Game/game.exe"
+  launch_configs:
+  - exe: other.exe
+    name: Other
+  notes: |
+    exe: not a key
+  prefix: ~
+system:
+  env:
+    DXVK_HUD: fps
+  gamemode: true
+`
+
+	got, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := map[string]string{
+		"game.args":           "--windowed 'fast'",
+		"game.exe":            "/games/My Game/game.exe",
+		"game.prefix":         "",
+		"system.env.DXVK_HUD": "fps",
+		"system.gamemode":     "true",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package scanner
+
+import (
+	"os"
+	"strings"
+)
+
+// localeMatcher ranks the locale suffixes of localized keys (Name[de_DE])
+// against the current message locale
+// See: https://specifications.freedesktop.org/desktop-entry-spec/latest/localized-keys.html
+type localeMatcher struct {
+	candidates []string // Most specific first
+}
+
+// newLocaleMatcher creates a matcher for the locale set in LC_ALL,
+// LC_MESSAGES or LANG, in that order of precedence
+func newLocaleMatcher() *localeMatcher {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return &localeMatcher{candidates: localeCandidates(value)}
+		}
+	}
+	return &localeMatcher{}
+}
+
+// localeCandidates returns the locale names to look for, in order of
+// preference, for a locale of the form lang_COUNTRY.ENCODING@MODIFIER:
+// lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER, lang
+func localeCandidates(locale string) []string {
+	if locale == "C" || locale == "POSIX" || strings.HasPrefix(locale, "C.") {
+		return nil
+	}
+
+	modifier := ""
+	if idx := strings.Index(locale, "@"); idx >= 0 {
+		modifier = locale[idx+1:]
+		locale = locale[:idx]
+	}
+
+	// The encoding is not used for matching
+	if idx := strings.Index(locale, "."); idx >= 0 {
+		locale = locale[:idx]
+	}
+
+	lang, country, _ := strings.Cut(locale, "_")
+	if lang == "" {
+		return nil
+	}
+
+	var candidates []string
+	if country != "" && modifier != "" {
+		candidates = append(candidates, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		candidates = append(candidates, lang+"_"+country)
+	}
+	if modifier != "" {
+		candidates = append(candidates, lang+"@"+modifier)
+	}
+	return append(candidates, lang)
+}
+
+// rank returns the preference of a locale suffix, lower is better. The
+// unlocalized value ranks last. Locales that don't match are not accepted.
+func (m *localeMatcher) rank(locale string) (int, bool) {
+	if locale == "" {
+		return len(m.candidates), true
+	}
+	for i, candidate := range m.candidates {
+		if candidate == locale {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// splitLocale splits a key such as Name[de_DE] into Name and de_DE
+func splitLocale(key string) (string, string) {
+	idx := strings.Index(key, "[")
+	if idx < 0 || !strings.HasSuffix(key, "]") {
+		return key, ""
+	}
+	return key[:idx], key[idx+1 : len(key)-1]
+}
+
+// localizedKeys remembers the rank of the value kept for each key of a group
+type localizedKeys map[string]int
+
+// accept reports whether a value with the given rank replaces the current one
+func (l localizedKeys) accept(key string, rank int) bool {
+	if current, ok := l[key]; ok && current <= rank {
+		return false
+	}
+	l[key] = rank
+	return true
+}
@@ -6,41 +6,89 @@ import (
 	"strings"
 )
 
-// SearchPaths returns all directories to search for .desktop files
+// DataDirs returns the XDG base data directories in precedence order:
+// XDG_DATA_HOME first, then each entry of XDG_DATA_DIRS
+// See: https://specifications.freedesktop.org/basedir-spec/latest/
+func DataDirs() []string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(os.Getenv("HOME"), ".local/share")
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+
+	dirs := []string{dataHome}
+	for _, dir := range strings.Split(dataDirs, ":") {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// SearchPaths returns all directories to search for .desktop files, in
+// precedence order. Desktop files found earlier override those with the same
+// desktop file ID found later.
 func SearchPaths() []string {
 	homeDir := os.Getenv("HOME")
 	userName := os.Getenv("USER")
 
-	paths := []string{
+	var paths []string
+	for _, dir := range DataDirs() {
+		paths = append(paths, filepath.Join(dir, "applications"))
+	}
+
+	// Well-known locations that may be missing from XDG_DATA_DIRS
+	paths = append(paths,
 		// Standard Linux paths
-		"/usr/share/applications",
-		"/usr/local/share/applications",
 		filepath.Join(homeDir, ".local/share/applications"),
+		"/usr/local/share/applications",
+		"/usr/share/applications",
 
 		// Flatpak paths
-		"/var/lib/flatpak/exports/share/applications",
 		filepath.Join(homeDir, ".local/share/flatpak/exports/share/applications"),
+		"/var/lib/flatpak/exports/share/applications",
+
+		// NixOS user profiles (home-manager)
+		filepath.Join(homeDir, ".nix-profile/share/applications"),
+		filepath.Join(homeDir, ".local/state/nix/profiles/profile/share/applications"),
+		"/etc/profiles/per-user/"+userName+"/share/applications",
 
 		// NixOS system profile
 		"/run/current-system/sw/share/applications",
 		"/nix/var/nix/profiles/default/share/applications",
+	)
 
-		// NixOS user profiles (home-manager)
-		filepath.Join(homeDir, ".nix-profile/share/applications"),
-		filepath.Join(homeDir, ".local/state/nix/profiles/profile/share/applications"),
-		"/etc/profiles/per-user/" + userName + "/share/applications",
+	return uniquePaths(paths)
+}
+
+// DesktopFileID returns the desktop file ID of a file below an applications
+// directory: its path relative to that directory with "/" replaced by "-",
+// so applications/kde/foo.desktop has the ID kde-foo.desktop
+func DesktopFileID(appsDir, path string) string {
+	rel, err := filepath.Rel(appsDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.Base(path)
 	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+}
 
-	// Also check XDG_DATA_DIRS environment variable
-	if xdgDataDirs := os.Getenv("XDG_DATA_DIRS"); xdgDataDirs != "" {
-		for _, dir := range strings.Split(xdgDataDirs, ":") {
-			if dir != "" {
-				paths = append(paths, filepath.Join(dir, "applications"))
-			}
+// uniquePaths removes duplicate paths, keeping the first occurrence
+func uniquePaths(paths []string) []string {
+	seen := make(map[string]bool, len(paths))
+	unique := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if seen[path] {
+			continue
 		}
+		seen[path] = true
+		unique = append(unique, path)
 	}
-
-	return paths
+	return unique
 }
 
 // FilterExistingPaths returns only paths that exist on the filesystem
@@ -0,0 +1,49 @@
+package scanner
+
+import "github.com/antoniosarro/gofi/internal/domain/entry"
+
+// pinTarget returns the entry pins apply to: the application of a desktop
+// action, or the entry itself
+func pinTarget(e *entry.Entry) *entry.Entry {
+	if e.Parent != nil {
+		return e.Parent
+	}
+	return e
+}
+
+// Pin pins an entry to the top of the list, below those already pinned
+func (s *Scanner) Pin(e *entry.Entry) error {
+	s.pins.Pin(pinTarget(e).Path)
+	return s.pins.Save()
+}
+
+// Unpin returns a pinned entry to its place in the list
+func (s *Scanner) Unpin(e *entry.Entry) error {
+	s.pins.Unpin(pinTarget(e).Path)
+	return s.pins.Save()
+}
+
+// MovePin moves a pinned entry up, for a negative offset, or down among
+// the pinned entries
+func (s *Scanner) MovePin(e *entry.Entry, offset int) error {
+	s.pins.Move(pinTarget(e).Path, offset)
+	return s.pins.Save()
+}
+
+// Hide hides an entry from the list and search results
+func (s *Scanner) Hide(e *entry.Entry) error {
+	s.pins.Hide(pinTarget(e).Path)
+	return s.pins.Save()
+}
+
+// Unhide shows a hidden entry again
+func (s *Scanner) Unhide(e *entry.Entry) error {
+	s.pins.Unhide(pinTarget(e).Path)
+	return s.pins.Save()
+}
+
+// IsPinned checks if an entry is pinned, or for a desktop action its
+// application
+func (s *Scanner) IsPinned(e *entry.Entry) bool {
+	return s.pins.IsPinned(pinTarget(e).Path)
+}
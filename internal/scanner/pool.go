@@ -0,0 +1,41 @@
+package scanner
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultWorkers returns the number of files parsed at the same time
+func defaultWorkers() int {
+	return runtime.NumCPU()
+}
+
+// forEach calls fn for every index below n, running at most workers calls
+// at the same time, and returns once all calls are done. Callers keep the
+// results ordered by writing them to index i of a slice.
+func forEach(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,310 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers"
+)
+
+// ChangeType describes how an entry changed during a rescan
+type ChangeType int
+
+const (
+	ChangeAdded ChangeType = iota
+	ChangeUpdated
+	ChangeRemoved
+)
+
+// String returns a readable name for the change type
+func (c ChangeType) String() string {
+	switch c {
+	case ChangeAdded:
+		return "added"
+	case ChangeUpdated:
+		return "updated"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a single entry added, updated or removed by a rescan.
+// Removed changes carry the entry as it was before removal.
+type Change struct {
+	Type  ChangeType
+	Entry *entry.Entry
+}
+
+// SearchDirs returns the applications directories the last scan looked at,
+// including those that did not exist at the time
+func (s *Scanner) SearchDirs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.searchDirs
+}
+
+// ScansGameLaunchers reports whether game launcher entries are included
+func (s *Scanner) ScansGameLaunchers() bool {
+	return s.scanGameLaunchers
+}
+
+// WatchPaths returns the files and directories game launchers read their
+// entries from
+func (s *Scanner) WatchPaths() []string {
+	if !s.scanGameLaunchers {
+		return nil
+	}
+
+	var paths []string
+	for _, launcher := range launchers.GetAll() {
+		if w, ok := launcher.(launchers.Watchable); ok {
+			paths = append(paths, w.WatchPaths()...)
+		}
+	}
+	return paths
+}
+
+// RescanPaths re-reads the given .desktop files, which may have been
+// created, modified or deleted, and updates the entries and search index
+func (s *Scanner) RescanPaths(paths ...string) []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changes []Change
+	for _, id := range s.updateSources(paths) {
+		changes = append(changes, s.rescanID(id)...)
+	}
+	s.saveCache()
+
+	return changes
+}
+
+// RescanDirectory re-reads every .desktop file below a directory that was
+// created, moved or deleted
+func (s *Scanner) RescanDirectory(dir string) []Change {
+	dir = filepath.Clean(dir)
+
+	// Files currently on disk
+	var paths []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(path, ".desktop") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+
+	// Files known from earlier scans, which may be gone now
+	s.mu.RLock()
+	for _, sources := range s.sources {
+		for _, path := range sources {
+			if isWithin(dir, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	return s.RescanPaths(paths...)
+}
+
+// RescanGameLaunchers scans the game launchers again and applies the
+// difference to the entries
+func (s *Scanner) RescanGameLaunchers() []Change {
+	if !s.scanGameLaunchers {
+		return nil
+	}
+
+	// Scan before locking, launchers may be slow
+	current, complete := s.gameLauncherEntries()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := make(map[string]*entry.Entry)
+	for _, e := range s.entries {
+		if e.ID == "" {
+			previous[e.Path] = e
+		}
+	}
+
+	var changes []Change
+	for _, e := range current {
+		old := previous[e.Path]
+		delete(previous, e.Path)
+		if change, ok := s.apply(old, e); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	// Whatever is left is gone, unless a launcher failed to report its games
+	if !complete {
+		return changes
+	}
+	for _, old := range previous {
+		if change, ok := s.apply(old, nil); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// updateSources records the current state of the given files in the
+// sources of their desktop file IDs and returns the IDs that were touched
+func (s *Scanner) updateSources(paths []string) []string {
+	var ids []string
+	touched := make(map[string]bool)
+
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if !strings.HasSuffix(path, ".desktop") {
+			continue
+		}
+
+		dirIndex := s.searchDirIndex(path)
+		if dirIndex < 0 {
+			continue
+		}
+		id := DesktopFileID(s.searchDirs[dirIndex], path)
+
+		// Drop the file, then insert it again at its precedence if it exists
+		sources := removeString(s.sources[id], path)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			pos := len(sources)
+			for i, source := range sources {
+				if s.searchDirIndex(source) > dirIndex {
+					pos = i
+					break
+				}
+			}
+			sources = append(sources[:pos], append([]string{path}, sources[pos:]...)...)
+		}
+
+		if len(sources) == 0 {
+			delete(s.sources, id)
+		} else {
+			s.sources[id] = sources
+		}
+
+		if !touched[id] {
+			touched[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// rescanID resolves a desktop file ID again and applies the result
+func (s *Scanner) rescanID(id string) []Change {
+	s.forgetDropped(id)
+
+	var old *entry.Entry
+	for _, e := range s.entries {
+		if e.ID == id {
+			old = e
+			break
+		}
+	}
+
+	if change, ok := s.apply(old, s.resolve(id)); ok {
+		return []Change{change}
+	}
+	return nil
+}
+
+// apply replaces an entry, adds it when old is nil or removes it when e is
+// nil, keeping the search index in sync. Unchanged entries are left alone.
+func (s *Scanner) apply(old, e *entry.Entry) (Change, bool) {
+	switch {
+	case old == nil && e == nil:
+		return Change{}, false
+
+	case old == nil:
+		s.entries = append(s.entries, e)
+		if s.searchEngine != nil {
+			s.searchEngine.UpdateIndex(e)
+		}
+		return Change{Type: ChangeAdded, Entry: e}, true
+
+	case e == nil:
+		for i, existing := range s.entries {
+			if existing == old {
+				s.entries = append(s.entries[:i], s.entries[i+1:]...)
+				break
+			}
+		}
+		if s.searchEngine != nil {
+			s.searchEngine.RemoveIndex(old.Path)
+		}
+		return Change{Type: ChangeRemoved, Entry: old}, true
+
+	default:
+		e.LastUsed = old.LastUsed
+		if reflect.DeepEqual(old, e) {
+			return Change{}, false
+		}
+
+		// Keep the position so the list doesn't jump around
+		for i, existing := range s.entries {
+			if existing == old {
+				s.entries[i] = e
+				break
+			}
+		}
+		if s.searchEngine != nil {
+			if old.Path != e.Path {
+				s.searchEngine.RemoveIndex(old.Path)
+			}
+			s.searchEngine.UpdateIndex(e)
+		}
+		return Change{Type: ChangeUpdated, Entry: e}, true
+	}
+}
+
+// forgetDropped removes the dropped records of a desktop file ID's files
+func (s *Scanner) forgetDropped(id string) {
+	var kept []DroppedEntry
+	for _, d := range s.dropped {
+		dirIndex := s.searchDirIndex(d.Path)
+		if dirIndex >= 0 && DesktopFileID(s.searchDirs[dirIndex], d.Path) == id {
+			continue
+		}
+		kept = append(kept, d)
+	}
+	s.dropped = kept
+}
+
+// searchDirIndex returns the index of the search directory a file belongs
+// to, preferring the deepest one when directories are nested, or -1
+func (s *Scanner) searchDirIndex(path string) int {
+	best := -1
+	for i, dir := range s.searchDirs {
+		if isWithin(dir, path) && (best < 0 || len(dir) > len(s.searchDirs[best])) {
+			best = i
+		}
+	}
+	return best
+}
+
+// isWithin reports whether path is dir or below it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// removeString returns the slice without the given value
+func removeString(values []string, value string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -1,10 +1,12 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
 	"github.com/antoniosarro/gofi/internal/favorites"
@@ -12,44 +14,184 @@ import (
 	"github.com/antoniosarro/gofi/internal/search"
 
 	// Import game launchers to trigger registration
+	_ "github.com/antoniosarro/gofi/internal/scanner/launchers/bottles"
 	_ "github.com/antoniosarro/gofi/internal/scanner/launchers/heroic"
+	_ "github.com/antoniosarro/gofi/internal/scanner/launchers/lutris"
+	_ "github.com/antoniosarro/gofi/internal/scanner/launchers/plugin"
+	_ "github.com/antoniosarro/gofi/internal/scanner/launchers/retroarch"
+	_ "github.com/antoniosarro/gofi/internal/scanner/launchers/steam"
 )
 
+// DefaultLauncherTimeout bounds how long a single game launcher may take to
+// scan before its entries are left out
+const DefaultLauncherTimeout = 3 * time.Second
+
+// maxRankLog is how many ranked results are logged in debug mode
+const maxRankLog = 10
+
 // Scanner finds and manages application entries
 type Scanner struct {
+	mu                sync.RWMutex
 	entries           []*entry.Entry
 	searchEngine      *search.Engine
 	favoritesManager  *favorites.Manager
+	pins              *favorites.PinStore
 	scanGameLaunchers bool
+	dropped           []DroppedEntry
+
+	// List games most recently played first when no text is searched
+	sortGamesByLastPlayed bool
+
+	// Query characters per typo tolerated in names, 0 to disable
+	typoThreshold int
+
+	// How search results blend match score and frecency
+	rankWeights search.Weights
+
+	// Options of the favorites manager, such as how usage is scored
+	favoritesOpts []favorites.Option
+
+	// Desktop files providing each desktop file ID, in precedence order
+	searchDirs []string
+	sources    map[string][]string
+
+	cachePath string
+	cache     *entryCache
+
+	workers         int
+	launcherTimeout time.Duration
+}
+
+// Option is a functional option for Scanner
+type Option func(*Scanner)
+
+// WithWorkers sets how many desktop files are parsed at the same time
+func WithWorkers(workers int) Option {
+	return func(s *Scanner) {
+		s.workers = workers
+	}
+}
+
+// WithLauncherTimeout sets how long each game launcher may take to scan
+func WithLauncherTimeout(timeout time.Duration) Option {
+	return func(s *Scanner) {
+		s.launcherTimeout = timeout
+	}
+}
+
+// WithGamesByLastPlayed lists games most recently played first, rather
+// than favorites first, when filtering games without text to match
+func WithGamesByLastPlayed(enabled bool) Option {
+	return func(s *Scanner) {
+		s.sortGamesByLastPlayed = enabled
+	}
+}
+
+// WithTypoThreshold sets how many query characters tolerate one typo in
+// the names searched. Zero or less disables typo tolerance.
+func WithTypoThreshold(threshold int) Option {
+	return func(s *Scanner) {
+		s.typoThreshold = threshold
+	}
+}
+
+// WithRankWeights sets how search results blend how well they match with
+// how often and recently they were launched
+func WithRankWeights(weights search.Weights) Option {
+	return func(s *Scanner) {
+		s.rankWeights = weights
+	}
+}
+
+// WithFavoritesOptions sets options of the favorites manager, such as how
+// usage is scored
+func WithFavoritesOptions(opts ...favorites.Option) Option {
+	return func(s *Scanner) {
+		s.favoritesOpts = append(s.favoritesOpts, opts...)
+	}
+}
+
+// DroppedEntry records a desktop file that was skipped and why
+type DroppedEntry struct {
+	Path   string
+	Reason DropReason
 }
 
 // NewScanner creates a new scanner
-func NewScanner(enableFavorites bool, scanGameLaunchers bool) (*Scanner, error) {
-	fm, err := favorites.NewManager(enableFavorites)
+func NewScanner(enableFavorites bool, scanGameLaunchers bool, opts ...Option) (*Scanner, error) {
+	s := &Scanner{
+		entries:           make([]*entry.Entry, 0),
+		scanGameLaunchers: scanGameLaunchers,
+		sources:           make(map[string][]string),
+		cachePath:         entryCachePath(),
+		workers:           defaultWorkers(),
+		launcherTimeout:   DefaultLauncherTimeout,
+		typoThreshold:     search.DefaultTypoThreshold,
+		rankWeights:       search.DefaultWeights,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	fm, err := favorites.NewManager(enableFavorites, s.favoritesOpts...)
 	if err != nil {
 		return nil, err
 	}
+	s.favoritesManager = fm
 
-	return &Scanner{
-		entries:           make([]*entry.Entry, 0),
-		favoritesManager:  fm,
-		scanGameLaunchers: scanGameLaunchers,
-	}, nil
+	// Pins are set by hand, so they apply even without favorites
+	s.pins = favorites.OpenPinStore()
+
+	return s, nil
 }
 
-// Scan searches for .desktop files and game launcher entries
+// Scan searches for .desktop files and game launcher entries, leaving out
+// launchers that time out
 func (s *Scanner) Scan() error {
-	// Track seen entries to avoid duplicates
-	seen := make(map[string]bool)
+	return s.scan(s.scanGameLaunchers)
+}
+
+// ScanApplications searches for .desktop files only, so the window can open
+// without waiting on game launchers. LoadGameLaunchers adds their entries.
+func (s *Scanner) ScanApplications() error {
+	return s.scan(false)
+}
+
+// scan searches for .desktop files and, if withLaunchers, game launcher
+// entries
+func (s *Scanner) scan(withLaunchers bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make([]*entry.Entry, 0)
+	s.dropped = nil
+
+	// Reuse what didn't change since the last scan
+	cache, err := loadEntryCache(s.cachePath)
+	if err != nil && os.Getenv("DEBUG") == "1" {
+		fmt.Printf("Discarding entry cache: %v\n", err)
+	}
+	s.cache = cache
+
+	// Scan game launchers alongside the desktop files
+	var games chan []*entry.Entry
+	if withLaunchers {
+		games = make(chan []*entry.Entry, 1)
+		go func() {
+			entries, _ := s.gameLauncherEntries()
+			games <- entries
+		}()
+	}
 
 	// Scan .desktop files
-	if err := s.scanDesktopFiles(seen); err != nil {
+	if err := s.scanDesktopFiles(); err != nil {
 		return fmt.Errorf("scanning desktop files: %w", err)
 	}
 
-	// Scan game launchers if enabled
-	if s.scanGameLaunchers {
-		s.scanGameLauncherEntries(seen)
+	if games != nil {
+		s.entries = append(s.entries, <-games...)
 	}
 
 	// Sort entries: favorites first (by score), then alphabetically.
@@ -58,7 +200,11 @@ func (s *Scanner) Scan() error {
 	s.favoritesManager.SortByFavorites(s.entries)
 
 	// Initialize search engine with entries
-	s.searchEngine = search.New(s.entries)
+	opts := []search.Option{search.WithTypoThreshold(s.typoThreshold)}
+	if s.favoritesManager != nil {
+		opts = append(opts, search.WithFavorites(s.favoritesManager.IsFavorite))
+	}
+	s.searchEngine = search.New(s.entries, opts...)
 
 	// Cleanup old favorites in background
 	if s.favoritesManager != nil {
@@ -69,12 +215,14 @@ func (s *Scanner) Scan() error {
 }
 
 // scanDesktopFiles scans all .desktop file locations
-func (s *Scanner) scanDesktopFiles(seen map[string]bool) error {
-	searchPaths := SearchPaths()
-	existingPaths := FilterExistingPaths(searchPaths)
-
-	for _, searchPath := range existingPaths {
-		if err := s.scanDirectory(searchPath, seen); err != nil {
+func (s *Scanner) scanDesktopFiles() error {
+	s.searchDirs = SearchPaths()
+	s.sources = make(map[string][]string)
+
+	// Collect the files providing each desktop file ID
+	var ids []string
+	for _, searchPath := range FilterExistingPaths(s.searchDirs) {
+		if err := s.scanDirectory(searchPath, &ids); err != nil {
 			// Log error but continue with other paths
 			if os.Getenv("DEBUG") == "1" {
 				fmt.Printf("Error scanning %s: %v\n", searchPath, err)
@@ -82,69 +230,297 @@ func (s *Scanner) scanDesktopFiles(seen map[string]bool) error {
 		}
 	}
 
+	// Parse in parallel, then merge in scan order so results don't depend
+	// on which worker finished first
+	results := make([]resolution, len(ids))
+	forEach(len(ids), s.workers, func(i int) {
+		results[i] = s.resolveID(ids[i])
+	})
+	for _, r := range results {
+		s.record(r)
+		if r.entry != nil {
+			s.entries = append(s.entries, r.entry)
+		}
+	}
+
+	// Forget files that are gone and write the changes
+	s.cache.prune()
+	s.saveCache()
+
 	return nil
 }
 
-// scanDirectory walks a directory and parses .desktop files
-func (s *Scanner) scanDirectory(dir string, seen map[string]bool) error {
-	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip paths we can't access
-		}
+// scanDirectory walks an applications directory and records the .desktop
+// files it contains as sources of their desktop file ID
+func (s *Scanner) scanDirectory(root string, ids *[]string) error {
+	return s.walkDirectory(root, root, ids)
+}
 
-		// Only process .desktop files
-		if info.IsDir() || !strings.HasSuffix(path, ".desktop") {
-			return nil
-		}
+// walkDirectory records the .desktop files of dir and its subdirectories in
+// lexical order. Unchanged directories are listed from the cache.
+func (s *Scanner) walkDirectory(root, dir string, ids *[]string) error {
+	entries, err := s.cache.listDir(dir)
+	if err != nil {
+		return err
+	}
 
-		// Use basename to detect duplicates
-		basename := filepath.Base(path)
-		if seen[basename] {
-			return nil // Skip duplicate
+	for _, d := range entries {
+		path := filepath.Join(dir, d.Name)
+		if d.IsDir {
+			s.walkDirectory(root, path, ids) // Skip paths we can't access
+			continue
 		}
 
-		// Parse the desktop file
-		e, err := ParseDesktopFile(path)
-		if err != nil || e == nil {
-			return nil // Skip invalid or non-application entries
+		id := DesktopFileID(root, path)
+		if _, ok := s.sources[id]; !ok {
+			*ids = append(*ids, id)
 		}
+		s.sources[id] = append(s.sources[id], path)
+	}
 
-		// Mark as seen and add to entries
-		seen[basename] = true
-		s.entries = append(s.entries, e)
+	return nil
+}
 
-		return nil
-	})
+// resolution is the outcome of resolving a desktop file ID
+type resolution struct {
+	entry   *entry.Entry
+	dropped []DroppedEntry
 }
 
-// scanGameLauncherEntries scans all registered game launchers
-func (s *Scanner) scanGameLauncherEntries(seen map[string]bool) {
-	for _, launcher := range launchers.GetAll() {
-		entries, err := launcher.Scan()
+// resolve resolves a desktop file ID and records the dropped files
+func (s *Scanner) resolve(id string) *entry.Entry {
+	r := s.resolveID(id)
+	s.record(r)
+	return r.entry
+}
+
+// resolveID parses the files providing a desktop file ID and returns the
+// entry of the first readable one. That file claims the ID even if it is
+// hidden, so that user files can override or mask system entries.
+// It is safe to call from several goroutines.
+func (s *Scanner) resolveID(id string) resolution {
+	var r resolution
+
+	paths := s.sources[id]
+	for i, path := range paths {
+		parsed, err := s.parse(path)
 		if err != nil {
-			if os.Getenv("DEBUG") == "1" {
-				fmt.Printf("Error scanning %s: %v\n", launcher.Name(), err)
-			}
-			continue
+			continue // Skip unreadable files
 		}
+		e, reason := parsed.result()
+
+		for _, shadowed := range paths[i+1:] {
+			r.dropped = append(r.dropped, DroppedEntry{Path: shadowed, Reason: DropShadowed})
+		}
+		if reason != "" {
+			r.dropped = append(r.dropped, DroppedEntry{Path: path, Reason: reason})
+		}
+		if e != nil {
+			e.ID = id
+		}
+		r.entry = e
+		return r
+	}
+	return r
+}
+
+// record remembers the files dropped while resolving an ID
+func (s *Scanner) record(r resolution) {
+	for _, d := range r.dropped {
+		s.recordDropped(d.Path, d.Reason)
+	}
+}
+
+// parse reads a desktop file through the cache when there is one
+func (s *Scanner) parse(path string) (*desktopFile, error) {
+	if s.cache == nil {
+		return parseDesktopFile(path)
+	}
+	return s.cache.parse(path)
+}
+
+// saveCache writes the entry cache, a failure only costs a slower next start
+func (s *Scanner) saveCache() {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.save(); err != nil && os.Getenv("DEBUG") == "1" {
+		fmt.Printf("Error saving entry cache: %v\n", err)
+	}
+}
+
+// recordDropped remembers a skipped desktop file
+func (s *Scanner) recordDropped(path string, reason DropReason) {
+	s.dropped = append(s.dropped, DroppedEntry{Path: path, Reason: reason})
+	if os.Getenv("DEBUG") == "1" {
+		fmt.Printf("Dropped %s: %s\n", path, reason)
+	}
+}
+
+// gameLauncherEntries scans all registered game launchers in parallel. It
+// reports false if any launcher failed or timed out, in which case the
+// entries are incomplete.
+func (s *Scanner) gameLauncherEntries() ([]*entry.Entry, bool) {
+	all := launchers.GetAll()
+	results := make([][]*entry.Entry, len(all))
+	complete := make([]bool, len(all))
+	forEach(len(all), len(all), func(i int) {
+		results[i], complete[i] = s.scanLauncher(all[i])
+	})
+
+	seen := make(map[string]bool)
+	var result []*entry.Entry
+	ok := true
+
+	// Merge in registry order, which is sorted by name
+	for i, entries := range results {
+		ok = ok && complete[i]
 
 		// Add entries that haven't been seen
 		for _, e := range entries {
 			if !seen[e.Path] {
 				seen[e.Path] = true
-				s.entries = append(s.entries, e)
+				result = append(result, e)
 			}
 		}
 	}
+
+	return result, ok
 }
 
-// GetEntries returns all scanned entries
+// LoadGameLaunchers scans the game launchers in parallel and adds the
+// entries of each as soon as it finishes, passing the changes to onChange
+// if set. Unlike rescans, launchers aren't timed out: the games of a slow
+// launcher show up late rather than not at all. It returns once every
+// launcher finished or ctx is done.
+func (s *Scanner) LoadGameLaunchers(ctx context.Context, onChange func([]Change)) {
+	if !s.scanGameLaunchers {
+		return
+	}
+
+	all := launchers.GetAll()
+	done := make(chan scanResult, len(all))
+	for _, launcher := range all {
+		go func() {
+			done <- runLauncher(ctx, launcher)
+		}()
+	}
+
+	for range all {
+		select {
+		case r := <-done:
+			if r.err != nil && os.Getenv("DEBUG") == "1" {
+				fmt.Printf("Error scanning %s: %v\n", r.launcher, r.err)
+			}
+			if changes := s.addGameEntries(r.entries); len(changes) > 0 && onChange != nil {
+				onChange(changes)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// addGameEntries adds game launcher entries that aren't known yet, such as
+// from a rescan that finished first
+func (s *Scanner) addGameEntries(entries []*entry.Entry) []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	known := make(map[string]bool)
+	for _, e := range s.entries {
+		if e.ID == "" {
+			known[e.Path] = true
+		}
+	}
+
+	var changes []Change
+	for _, e := range entries {
+		if known[e.Path] {
+			continue
+		}
+		known[e.Path] = true
+		if change, ok := s.apply(nil, e); ok {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// scanResult is the outcome of a launcher scan
+type scanResult struct {
+	launcher string
+	entries  []*entry.Entry
+	err      error
+}
+
+// runLauncher runs a launcher scan. A panic, such as from a bug in the
+// launcher's parser, only fails that launcher.
+func runLauncher(ctx context.Context, launcher launchers.GameLauncher) (r scanResult) {
+	r.launcher = launcher.Name()
+	defer func() {
+		if p := recover(); p != nil {
+			r.entries, r.err = nil, fmt.Errorf("%w: %v", ErrLauncherPanic, p)
+		}
+	}()
+
+	r.entries, r.err = launcher.Scan(ctx)
+	return r
+}
+
+// scanLauncher runs a single launcher scan, giving up after the launcher
+// timeout even if the launcher ignores the cancellation
+func (s *Scanner) scanLauncher(launcher launchers.GameLauncher) ([]*entry.Entry, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.launcherTimeout)
+	defer cancel()
+
+	done := make(chan scanResult, 1)
+	go func() {
+		done <- runLauncher(ctx, launcher)
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			if os.Getenv("DEBUG") == "1" {
+				fmt.Printf("Error scanning %s: %v\n", launcher.Name(), r.err)
+			}
+			// Keep what the launcher did find
+			return r.entries, false
+		}
+		return r.entries, true
+	case <-ctx.Done():
+		if os.Getenv("DEBUG") == "1" {
+			fmt.Printf("Error scanning %s: %v\n", launcher.Name(), ctx.Err())
+		}
+		return nil, false
+	}
+}
+
+// GetEntries returns the scanned entries that aren't hidden, pinned ones
+// first
 func (s *Scanner) GetEntries() []*entry.Entry {
-	return s.entries
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pins.Apply(s.entries)
+}
+
+// Dropped returns the desktop files skipped during the last scan
+func (s *Scanner) Dropped() []DroppedEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dropped
 }
 
-// GetEntriesByType returns entries filtered by app type
+// GetEntriesByType returns entries filtered by app type, as GetEntries
 func (s *Scanner) GetEntriesByType(appType entry.AppType) []*entry.Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pins.Apply(s.entriesByType(appType))
+}
+
+// entriesByType filters entries by app type, the caller must hold the lock
+func (s *Scanner) entriesByType(appType entry.AppType) []*entry.Entry {
 	if appType == entry.AppTypeAll {
 		return s.entries
 	}
@@ -160,49 +536,90 @@ func (s *Scanner) GetEntriesByType(appType entry.AppType) []*entry.Entry {
 
 // Filter searches and filters entries by query and app type
 func (s *Scanner) Filter(query string, appType entry.AppType) []*entry.Entry {
+	// Sorting may reorder the entries themselves for empty queries
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Safety check: ensure search engine is initialized
 	if s.searchEngine == nil {
 		// Return all entries if search engine not ready
-		if appType == entry.AppTypeAll {
-			return s.entries
-		}
-		// Filter by type manually
-		return s.GetEntriesByType(appType)
+		return s.pins.Apply(s.entriesByType(appType))
 	}
 
-	// Use search engine for fuzzy matching
-	results := s.searchEngine.Search(query, appType, s.entries)
+	// Use search engine for fuzzy matching, hidden entries never match
+	scored, ranked := s.searchEngine.SearchScored(query, appType, s.pins.Visible(s.entries))
+
+	var results []*entry.Entry
+	if ranked {
+		// Blend relevance with frecency, keeping the engine's order as the base
+		results = s.rank(query, scored)
+	} else {
+		results = make([]*entry.Entry, len(scored))
+		for i := range scored {
+			results[i] = scored[i].Entry
+		}
 
-	// Apply favorites sorting if enabled
-	if s.favoritesManager != nil {
-		s.favoritesManager.SortByFavorites(results)
+		// Apply favorites sorting if enabled
+		if s.favoritesManager != nil {
+			s.favoritesManager.SortByFavorites(results)
+		}
 	}
 
-	// Record search event for non-empty queries (for first result)
-	if query != "" && len(results) > 0 && s.favoritesManager != nil {
-		s.favoritesManager.RecordSearch(results[0])
+	// Games listed without text to match, such as by played:<7d, follow
+	// when they were last played
+	if s.sortGamesByLastPlayed && (query == "" && appType == entry.AppTypeGame || search.IsFilterQuery(query)) {
+		entry.SortByLastPlayed(results)
 	}
 
+	// Pinned entries that match go first, whatever else ranks them
+	return s.pins.Apply(results)
+}
+
+// rank orders search results by match score, frecency and what was
+// launched for the query before
+func (s *Scanner) rank(query string, scored []search.ScoredEntry) []*entry.Entry {
+	var signals search.Signals
+	if s.favoritesManager != nil {
+		signals.Frecency = s.favoritesManager.Frecency
+		signals.Adaptive = s.favoritesManager.Adaptive(search.QueryText(query))
+	}
+	ranked := search.Rank(scored, signals, s.rankWeights)
+
+	debug := os.Getenv("DEBUG") == "1"
+	results := make([]*entry.Entry, len(ranked))
+	for i, r := range ranked {
+		results[i] = r.Entry
+		if debug && i < maxRankLog {
+			fmt.Printf("%2d. %s: %v\n", i+1, r.Entry.Name, r.Breakdown)
+		}
+	}
 	return results
 }
 
-// GetAppTypeCounts returns the count of apps for each type
+// GetAppTypeCounts returns the count of apps for each type, leaving hidden
+// ones out
 func (s *Scanner) GetAppTypeCounts() map[entry.AppType]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	counts := make(map[entry.AppType]int)
+	entries := s.pins.Visible(s.entries)
 
-	for _, e := range s.entries {
+	for _, e := range entries {
 		appType := e.GetAppType()
 		counts[appType]++
 	}
 
-	counts[entry.AppTypeAll] = len(s.entries)
+	counts[entry.AppTypeAll] = len(entries)
 
 	return counts
 }
 
-// Count returns the total number of entries
+// Count returns the total number of entries that aren't hidden
 func (s *Scanner) Count() int {
-	return len(s.entries)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.pins.Visible(s.entries))
 }
 
 // GetFavoritesManager returns the favorites manager
@@ -1,13 +1,18 @@
 package scanner
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/search"
 )
 
 func TestNewScanner(t *testing.T) {
@@ -132,6 +137,334 @@ Categories=Utility;Development;
 	}
 }
 
+func TestParseDesktopFileActions(t *testing.T) {
+	tmpDir := t.TempDir()
+	desktopFile := filepath.Join(tmpDir, "firefox.desktop")
+
+	content := `[Desktop Entry]
+Type=Application
+Name=Firefox
+Exec=firefox %u
+Icon=firefox
+Actions=new-window;new-private-window;missing;
+
+[Desktop Action new-private-window]
+Name=New Private Window
+Exec=firefox --private-window %u
+Icon=firefox-private
+
+[Desktop Action new-window]
+Name=New Window
+Exec=firefox --new-window %u
+
+[Desktop Action unlisted]
+Name=Unlisted
+Exec=firefox --unlisted
+`
+
+	if err := os.WriteFile(desktopFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	e, err := ParseDesktopFile(desktopFile)
+	if err != nil {
+		t.Fatalf("ParseDesktopFile() error = %v", err)
+	}
+
+	if e.Exec != "firefox %u" {
+		t.Errorf("Exec = %v, want %v", e.Exec, "firefox %u")
+	}
+
+	expected := []entry.Action{
+		{ID: "new-window", Name: "New Window", Exec: "firefox --new-window %u"},
+		{ID: "new-private-window", Name: "New Private Window", Exec: "firefox --private-window %u", Icon: "firefox-private"},
+	}
+
+	if len(e.Actions) != len(expected) {
+		t.Fatalf("Actions = %+v, want %+v", e.Actions, expected)
+	}
+	for i := range expected {
+		if e.Actions[i] != expected[i] {
+			t.Errorf("Actions[%d] = %+v, want %+v", i, e.Actions[i], expected[i])
+		}
+	}
+}
+
+func TestParseDesktopFileVisibility(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CURRENT_DESKTOP", "Hyprland:wlroots")
+	defer os.Unsetenv("XDG_CURRENT_DESKTOP")
+
+	tests := []struct {
+		name       string
+		extra      string
+		wantReason DropReason
+	}{
+		{"Visible", "", ""},
+		{"TryExec found", "TryExec=sh", ""},
+		{"TryExec missing", "TryExec=gofi-not-installed-binary", DropTryExec},
+		{"TryExec absolute missing", "TryExec=/nonexistent/bin/app", DropTryExec},
+		{"OnlyShowIn current desktop", "OnlyShowIn=GNOME;Hyprland;", ""},
+		{"OnlyShowIn other desktop", "OnlyShowIn=GNOME;KDE;", DropOnlyShowIn},
+		{"NotShowIn current desktop", "NotShowIn=wlroots;", DropNotShowIn},
+		{"NotShowIn other desktop", "NotShowIn=KDE;", ""},
+		{"Hidden", "Hidden=true", DropHidden},
+		{"NoDisplay", "NoDisplay=true", DropNoDisplay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desktopFile := filepath.Join(tmpDir, "app.desktop")
+			content := "[Desktop Entry]\nType=Application\nName=App\nExec=app\n" + tt.extra + "\n"
+			if err := os.WriteFile(desktopFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			e, reason, err := ParseDesktopFileWithReason(desktopFile)
+			if err != nil {
+				t.Fatalf("ParseDesktopFileWithReason() error = %v", err)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", reason, tt.wantReason)
+			}
+			if (e != nil) != (tt.wantReason == "") {
+				t.Errorf("entry = %v, want entry only when not dropped", e)
+			}
+		})
+	}
+}
+
+func TestParseDesktopFileDBusActivatable(t *testing.T) {
+	tmpDir := t.TempDir()
+	desktopFile := filepath.Join(tmpDir, "org.gnome.Nautilus.desktop")
+
+	content := `[Desktop Entry]
+Type=Application
+Name=Files
+DBusActivatable=true
+Actions=new-window;
+
+[Desktop Action new-window]
+Name=New Window
+`
+
+	if err := os.WriteFile(desktopFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	e, err := ParseDesktopFile(desktopFile)
+	if err != nil {
+		t.Fatalf("ParseDesktopFile() error = %v", err)
+	}
+
+	if e == nil || !e.DBusActivatable {
+		t.Fatalf("ParseDesktopFile() = %+v, want D-Bus activatable entry without Exec", e)
+	}
+	if len(e.Actions) != 1 || e.Actions[0].Name != "New Window" {
+		t.Errorf("Actions = %+v, want New Window without Exec", e.Actions)
+	}
+}
+
+func TestCheckShowIn(t *testing.T) {
+	tests := []struct {
+		name       string
+		desktops   []string
+		onlyShowIn []string
+		notShowIn  []string
+		expected   DropReason
+	}{
+		{"No restrictions", nil, nil, nil, ""},
+		{"OnlyShowIn without current desktop", nil, []string{"GNOME"}, nil, DropOnlyShowIn},
+		{"NotShowIn without current desktop", nil, nil, []string{"GNOME"}, ""},
+		{"Case sensitive", []string{"gnome"}, []string{"GNOME"}, nil, DropOnlyShowIn},
+		{"Any listed desktop matches", []string{"ubuntu", "GNOME"}, []string{"GNOME"}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := checkShowIn(tt.desktops, tt.onlyShowIn, tt.notShowIn); result != tt.expected {
+				t.Errorf("checkShowIn() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScanRecordsDropped(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+	}()
+
+	appsDir := filepath.Join(tmpDir, ".local/share/applications")
+	os.MkdirAll(appsDir, 0755)
+
+	content := `[Desktop Entry]
+Type=Application
+Name=Missing App
+Exec=missing-app
+TryExec=gofi-not-installed-binary
+`
+	desktopPath := filepath.Join(appsDir, "missing.desktop")
+	os.WriteFile(desktopPath, []byte(content), 0644)
+
+	s, err := NewScanner(false, false)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	found := false
+	for _, d := range s.Dropped() {
+		if d.Path == desktopPath {
+			found = true
+			if d.Reason != DropTryExec {
+				t.Errorf("Dropped reason = %q, want %q", d.Reason, DropTryExec)
+			}
+		}
+	}
+	if !found {
+		t.Error("Dropped() should contain the desktop file with a missing TryExec")
+	}
+}
+
+func TestLocaleCandidates(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected []string
+	}{
+		{"de_DE.UTF-8", []string{"de_DE", "de"}},
+		{"sr_RS@latin", []string{"sr_RS@latin", "sr_RS", "sr@latin", "sr"}},
+		{"it", []string{"it"}},
+		{"it@euro", []string{"it@euro", "it"}},
+		{"C", nil},
+		{"C.UTF-8", nil},
+		{"POSIX", nil},
+	}
+
+	for _, tt := range tests {
+		result := localeCandidates(tt.locale)
+		if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("localeCandidates(%q) = %v, want %v", tt.locale, result, tt.expected)
+		}
+	}
+}
+
+func TestParseDesktopFileLocalized(t *testing.T) {
+	tmpDir := t.TempDir()
+	desktopFile := filepath.Join(tmpDir, "firefox.desktop")
+
+	content := `[Desktop Entry]
+Type=Application
+Name[de_DE]=Firefox Deutschland
+Name=Firefox
+Name[de]=Firefox Deutsch
+Name[it]=Firefox Italiano
+GenericName=Web Browser
+GenericName[de]=Webbrowser
+Comment=Browse the Web
+Comment[it]=Naviga sul web
+Keywords=Internet;WWW;Browser;
+Keywords[de]=Internet;WWW;Browser;Netz;
+Exec=firefox
+Actions=new-window;
+
+[Desktop Action new-window]
+Name=New Window
+Name[de]=Neues Fenster
+Exec=firefox --new-window
+`
+
+	if err := os.WriteFile(desktopFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	os.Setenv("LC_MESSAGES", "de_AT.UTF-8")
+	defer os.Unsetenv("LC_MESSAGES")
+
+	e, err := ParseDesktopFile(desktopFile)
+	if err != nil || e == nil {
+		t.Fatalf("ParseDesktopFile() = %v, %v", e, err)
+	}
+
+	if e.Name != "Firefox Deutsch" {
+		t.Errorf("Name = %q, want %q", e.Name, "Firefox Deutsch")
+	}
+	if e.GenericName != "Webbrowser" {
+		t.Errorf("GenericName = %q, want %q", e.GenericName, "Webbrowser")
+	}
+	if e.Comment != "Browse the Web" {
+		t.Errorf("Comment = %q, want %q", e.Comment, "Browse the Web")
+	}
+	if strings.Join(e.Keywords, ";") != "Internet;WWW;Browser;Netz" {
+		t.Errorf("Keywords = %v, want German keywords", e.Keywords)
+	}
+	if len(e.Actions) != 1 || e.Actions[0].Name != "Neues Fenster" {
+		t.Errorf("Actions = %+v, want localized action name", e.Actions)
+	}
+
+	// A more specific locale wins regardless of order in the file
+	os.Setenv("LC_MESSAGES", "de_DE.UTF-8")
+	e, _ = ParseDesktopFile(desktopFile)
+	if e.Name != "Firefox Deutschland" {
+		t.Errorf("Name = %q, want %q", e.Name, "Firefox Deutschland")
+	}
+
+	// Unknown locales fall back to the default value
+	os.Setenv("LC_MESSAGES", "fr_FR.UTF-8")
+	e, _ = ParseDesktopFile(desktopFile)
+	if e.Name != "Firefox" {
+		t.Errorf("Name = %q, want %q", e.Name, "Firefox")
+	}
+	if strings.Join(e.Keywords, ";") != "Internet;WWW;Browser" {
+		t.Errorf("Keywords = %v, want default keywords", e.Keywords)
+	}
+}
+
+func TestUnescapeValue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{`a\sb`, "a b"},
+		{`line\nbreak`, "line\nbreak"},
+		{`tab\there`, "tab\there"},
+		{`back\\slash`, `back\slash`},
+		{`sh -c "echo \\"hi\\""`, `sh -c "echo \"hi\""`},
+		{`keep\ space`, `keep\ space`},
+	}
+
+	for _, tt := range tests {
+		if result := unescapeValue(tt.input); result != tt.expected {
+			t.Errorf("unescapeValue(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestParseStringList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"Internet;WWW;Browser;", []string{"Internet", "WWW", "Browser"}},
+		{`a\;b;c`, []string{"a;b", "c"}},
+		{`a\\;b`, []string{`a\`, "b"}},
+		{`web\sbrowser;;`, []string{"web browser"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if result := parseStringList(tt.input); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("parseStringList(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
 func TestParseDesktopFileHidden(t *testing.T) {
 	tmpDir := t.TempDir()
 	desktopFile := filepath.Join(tmpDir, "hidden.desktop")
@@ -214,6 +547,239 @@ func TestGetEntriesByType(t *testing.T) {
 	}
 }
 
+func TestFilterGamesByLastPlayed(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	game := func(name string, daysAgo int) *entry.Entry {
+		e := &entry.Entry{Name: name, Path: "game-" + name, Categories: []string{"Game"}}
+		e.SetMeta(entry.MetaStore, "steam")
+		e.SetLastPlayed(time.Now().AddDate(0, 0, -daysAgo))
+		return e
+	}
+
+	s, _ := NewScanner(false, false, WithGamesByLastPlayed(true))
+	s.entries = []*entry.Entry{
+		game("Antichamber", 20),
+		{Name: "Firefox", Path: "/usr/share/applications/firefox.desktop"},
+		game("Braid", 1),
+		game("Celeste", 5),
+	}
+	s.searchEngine = search.New(s.entries)
+
+	tests := []struct {
+		name    string
+		query   string
+		appType entry.AppType
+		want    []string
+	}{
+		{"Games", "", entry.AppTypeGame, []string{"Braid", "Celeste", "Antichamber"}},
+		{"Filter query", "played:<10d", entry.AppTypeAll, []string{"Braid", "Celeste"}},
+		{"All entries stay alphabetical", "", entry.AppTypeAll, []string{"Antichamber", "Braid", "Celeste", "Firefox"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, e := range s.Filter(tt.query, tt.appType) {
+				names = append(names, e.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Filter(%q) = %v, want %v", tt.query, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRanking(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	entries := []*entry.Entry{
+		{Name: "Barcode Reader", Path: "/usr/share/applications/barcode.desktop"},
+		{Name: "Code", Path: "/usr/share/applications/code.desktop"},
+		{Name: "Color Designer", Path: "/usr/share/applications/color.desktop"},
+	}
+
+	tests := []struct {
+		name      string
+		favorites bool
+		launches  int // Of Color Designer
+		want      string
+	}{
+		{"Relevance without favorites", false, 0, "Code"},
+		{"Exact match above weak favorite", true, 20, "Code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewScanner(tt.favorites, false)
+			if err != nil {
+				t.Fatalf("NewScanner() error = %v", err)
+			}
+			s.entries = entries
+			s.searchEngine = search.New(s.entries)
+			for i := 0; i < tt.launches; i++ {
+				s.favoritesManager.RecordLaunch(entries[2])
+			}
+
+			results := s.Filter("code", entry.AppTypeAll)
+			if len(results) == 0 || results[0].Name != tt.want {
+				t.Errorf("Filter(code) = %v, want %s first", results, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterAdaptive(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	s, err := NewScanner(true, false)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	s.entries = []*entry.Entry{
+		{Name: "Text Editor", Path: "/usr/share/applications/editor.desktop"},
+		{Name: "Alacritty", Path: "/usr/share/applications/alacritty.desktop", GenericName: "Terminal"},
+		{Name: "Telegram", Path: "/usr/share/applications/telegram.desktop"},
+	}
+	s.searchEngine = search.New(s.entries)
+
+	if results := s.Filter("te", entry.AppTypeAll); len(results) == 0 || results[0].Name != "Text Editor" {
+		t.Fatalf("Filter(te) = %v, want Text Editor first", results)
+	}
+
+	// Searching alone doesn't count as using the first result
+	if score := s.favoritesManager.GetScore(s.entries[0]); score != 0 {
+		t.Errorf("GetScore() after searching = %v, want 0", score)
+	}
+
+	// The terminal usually picked after typing "te" ranks first for it
+	s.favoritesManager.RecordSelection("te", s.entries[1])
+	s.favoritesManager.RecordSelection("term", s.entries[1])
+	if results := s.Filter("te", entry.AppTypeAll); len(results) == 0 || results[0].Name != "Alacritty" {
+		t.Errorf("Filter(te) = %v, want Alacritty first", results)
+	}
+	// Filters are left out of the query learned from
+	for range 3 {
+		s.favoritesManager.RecordSelection(search.QueryText("type:system tel"), s.entries[2])
+	}
+	if results := s.Filter("type:system te", entry.AppTypeAll); len(results) == 0 || results[0].Name != "Telegram" {
+		t.Errorf("Filter(type:system te) = %v, want Telegram first", results)
+	}
+}
+
+func TestFilterPins(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	s, err := NewScanner(false, false)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	s.entries = []*entry.Entry{
+		{Name: "Alacritty", Path: "/usr/share/applications/alacritty.desktop"},
+		{Name: "Firefox", Path: "/usr/share/applications/firefox.desktop", Actions: []entry.Action{{ID: "private", Name: "Private Window", Exec: "firefox --private-window"}}},
+		{Name: "Fish", Path: "/usr/share/applications/fish.desktop"},
+		{Name: "Slack", Path: "/usr/share/applications/slack.desktop"},
+	}
+	s.searchEngine = search.New(s.entries)
+	alacritty, firefox, fish, slack := s.entries[0], s.entries[1], s.entries[2], s.entries[3]
+
+	names := func(entries []*entry.Entry) string {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	s.Pin(slack)
+	s.Pin(fish)
+	s.MovePin(fish, -1)
+	s.Hide(firefox)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "Fish,Slack,Alacritty"},
+		{"f", "Fish"},
+		{"private", ""},
+		{"al|sl", "Slack,Alacritty"},
+	}
+	for _, tt := range tests {
+		if got := names(s.Filter(tt.query, entry.AppTypeAll)); got != tt.want {
+			t.Errorf("Filter(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+	if got := names(s.GetEntries()); got != "Fish,Slack,Alacritty" {
+		t.Errorf("GetEntries() = %s, want Fish,Slack,Alacritty", got)
+	}
+	if s.Count() != 3 {
+		t.Errorf("Count() = %d, want 3", s.Count())
+	}
+	if !s.IsPinned(fish) || s.IsPinned(alacritty) {
+		t.Errorf("IsPinned() = %v for Fish, %v for Alacritty", s.IsPinned(fish), s.IsPinned(alacritty))
+	}
+
+	// A desktop action follows its application
+	private := firefox.ActionEntries()[0]
+	if s.IsPinned(private) {
+		t.Error("IsPinned() = true for an action of an unpinned application")
+	}
+	s.Pin(private)
+	if !s.IsPinned(private) || !s.IsPinned(firefox) {
+		t.Errorf("IsPinned() = %v for the action, %v for Firefox after pinning the action", s.IsPinned(private), s.IsPinned(firefox))
+	}
+	s.Unpin(firefox)
+
+	// Pins are kept for the next start
+	s2, _ := NewScanner(false, false)
+	s2.entries = s.entries
+	s2.Unpin(fish)
+	s2.Unhide(firefox)
+	if got := names(s2.GetEntries()); got != "Slack,Alacritty,Firefox,Fish" {
+		t.Errorf("GetEntries() after unpinning and unhiding = %s, want Slack,Alacritty,Firefox,Fish", got)
+	}
+}
+
+func TestNewScannerMalformedPins(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	os.MkdirAll(filepath.Join(tmpDir, "gofi"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "gofi", "pins.json"), []byte("{not json"), 0644)
+
+	s, err := NewScanner(false, false)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	slack := &entry.Entry{Name: "Slack", Path: "/usr/share/applications/slack.desktop"}
+	s.entries = []*entry.Entry{slack}
+	if s.IsPinned(slack) {
+		t.Error("IsPinned() = true with a malformed pins file")
+	}
+
+	// Pinning replaces the malformed file
+	if err := s.Pin(slack); err != nil {
+		t.Fatalf("Pin() error = %v", err)
+	}
+	s2, err := NewScanner(false, false)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	if !s2.IsPinned(slack) {
+		t.Error("IsPinned() = false after pinning over a malformed pins file")
+	}
+}
+
 func TestGetAppTypeCounts(t *testing.T) {
 	tmpDir := t.TempDir()
 	os.Setenv("XDG_CACHE_HOME", tmpDir)
@@ -273,6 +839,498 @@ func TestSearchPaths(t *testing.T) {
 	}
 }
 
+func TestDataDirs(t *testing.T) {
+	os.Setenv("HOME", "/home/test")
+	os.Setenv("XDG_DATA_HOME", "")
+	os.Setenv("XDG_DATA_DIRS", "")
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_DATA_HOME")
+		os.Unsetenv("XDG_DATA_DIRS")
+	}()
+
+	expected := []string{"/home/test/.local/share", "/usr/local/share", "/usr/share"}
+	if dirs := DataDirs(); strings.Join(dirs, ":") != strings.Join(expected, ":") {
+		t.Errorf("DataDirs() = %v, want %v", dirs, expected)
+	}
+
+	os.Setenv("XDG_DATA_HOME", "/data/home")
+	os.Setenv("XDG_DATA_DIRS", "/opt/share::/usr/share")
+
+	expected = []string{"/data/home", "/opt/share", "/usr/share"}
+	if dirs := DataDirs(); strings.Join(dirs, ":") != strings.Join(expected, ":") {
+		t.Errorf("DataDirs() = %v, want %v", dirs, expected)
+	}
+}
+
+func TestSearchPathsOrder(t *testing.T) {
+	os.Setenv("XDG_DATA_HOME", "/data/home")
+	os.Setenv("XDG_DATA_DIRS", "/opt/share:/usr/share")
+	defer func() {
+		os.Unsetenv("XDG_DATA_HOME")
+		os.Unsetenv("XDG_DATA_DIRS")
+	}()
+
+	paths := SearchPaths()
+
+	expected := []string{"/data/home/applications", "/opt/share/applications", "/usr/share/applications"}
+	for i, path := range expected {
+		if paths[i] != path {
+			t.Errorf("SearchPaths()[%d] = %v, want %v", i, paths[i], path)
+		}
+	}
+
+	// Fallback paths must not repeat XDG directories
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if seen[path] {
+			t.Errorf("SearchPaths() contains %v twice", path)
+		}
+		seen[path] = true
+	}
+}
+
+func TestDesktopFileID(t *testing.T) {
+	tests := []struct {
+		dir      string
+		path     string
+		expected string
+	}{
+		{"/usr/share/applications", "/usr/share/applications/firefox.desktop", "firefox.desktop"},
+		{"/usr/share/applications", "/usr/share/applications/kde/foo.desktop", "kde-foo.desktop"},
+		{"/usr/share/applications", "/usr/share/applications/a/b/c.desktop", "a-b-c.desktop"},
+		{"/usr/share/applications", "/elsewhere/bar.desktop", "bar.desktop"},
+	}
+
+	for _, tt := range tests {
+		if result := DesktopFileID(tt.dir, tt.path); result != tt.expected {
+			t.Errorf("DesktopFileID(%q, %q) = %v, want %v", tt.dir, tt.path, result, tt.expected)
+		}
+	}
+}
+
+func TestScanOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataHome := filepath.Join(tmpDir, "home")
+	systemDir := filepath.Join(tmpDir, "system")
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	os.Setenv("XDG_DATA_HOME", dataHome)
+	os.Setenv("XDG_DATA_DIRS", systemDir)
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+		os.Unsetenv("XDG_DATA_HOME")
+		os.Unsetenv("XDG_DATA_DIRS")
+	}()
+
+	write := func(path, content string) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	// System entries
+	write(filepath.Join(systemDir, "applications/masked-app.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Masked App\nExec=masked\n")
+	write(filepath.Join(systemDir, "applications/editor.desktop"),
+		"[Desktop Entry]\nType=Application\nName=System Editor\nExec=editor\n")
+	write(filepath.Join(systemDir, "applications/kde/dolphin.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Dolphin\nExec=dolphin\n")
+
+	// User overrides
+	write(filepath.Join(dataHome, "applications/masked-app.desktop"),
+		"[Desktop Entry]\nType=Application\nName=Masked App\nExec=masked\nHidden=true\n")
+	write(filepath.Join(dataHome, "applications/editor.desktop"),
+		"[Desktop Entry]\nType=Application\nName=User Editor\nExec=editor --user\n")
+
+	s, err := NewScanner(false, false)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	byName := make(map[string]*entry.Entry)
+	for _, e := range s.GetEntries() {
+		byName[e.Name] = e
+	}
+
+	if _, ok := byName["Masked App"]; ok {
+		t.Error("Hidden user file should mask the system entry")
+	}
+	if _, ok := byName["System Editor"]; ok {
+		t.Error("User file should override the system entry")
+	}
+	if e, ok := byName["User Editor"]; !ok || e.ID != "editor.desktop" {
+		t.Errorf("User Editor = %+v, want entry with ID editor.desktop", e)
+	}
+	if e, ok := byName["Dolphin"]; !ok || e.ID != "kde-dolphin.desktop" {
+		t.Errorf("Dolphin = %+v, want entry with ID kde-dolphin.desktop", e)
+	}
+}
+
+func TestRescanPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataHome := filepath.Join(tmpDir, "home")
+	systemDir := filepath.Join(tmpDir, "system")
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	os.Setenv("XDG_DATA_HOME", dataHome)
+	os.Setenv("XDG_DATA_DIRS", systemDir)
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+		os.Unsetenv("XDG_DATA_HOME")
+		os.Unsetenv("XDG_DATA_DIRS")
+	}()
+
+	write := func(path, content string) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	systemEditor := filepath.Join(systemDir, "applications/editor.desktop")
+	userEditor := filepath.Join(dataHome, "applications/editor.desktop")
+	write(systemEditor, "[Desktop Entry]\nType=Application\nName=System Editor\nExec=editor\n")
+
+	s, err := NewScanner(false, false)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	find := func(id string) *entry.Entry {
+		for _, e := range s.GetEntries() {
+			if e.ID == id {
+				return e
+			}
+		}
+		return nil
+	}
+
+	expect := func(step string, changes []Change, changeType ChangeType, name string) {
+		t.Helper()
+		if len(changes) != 1 || changes[0].Type != changeType || changes[0].Entry.Name != name {
+			t.Fatalf("%s: changes = %+v, want one %s change for %q", step, changes, changeType, name)
+		}
+	}
+
+	// A new file is added and becomes searchable
+	drawPath := filepath.Join(systemDir, "applications/draw.desktop")
+	write(drawPath, "[Desktop Entry]\nType=Application\nName=Drawing Board\nExec=draw\n")
+	expect("create", s.RescanPaths(drawPath), ChangeAdded, "Drawing Board")
+	if results := s.Filter("drawing", entry.AppTypeAll); len(results) == 0 || results[0].Name != "Drawing Board" {
+		t.Errorf("Filter(drawing) = %v, want Drawing Board first", results)
+	}
+
+	// An unchanged file produces no change
+	if changes := s.RescanPaths(drawPath); len(changes) != 0 {
+		t.Errorf("unchanged: changes = %+v, want none", changes)
+	}
+
+	// A user file overrides the system file
+	write(userEditor, "[Desktop Entry]\nType=Application\nName=User Editor\nExec=editor --user\n")
+	expect("override", s.RescanPaths(userEditor), ChangeUpdated, "User Editor")
+	if e := find("editor.desktop"); e == nil || e.Path != userEditor {
+		t.Errorf("editor.desktop = %+v, want user file", e)
+	}
+
+	// Removing the override brings the system file back
+	os.Remove(userEditor)
+	expect("remove override", s.RescanPaths(userEditor), ChangeUpdated, "System Editor")
+
+	// Hiding the entry removes it
+	write(drawPath, "[Desktop Entry]\nType=Application\nName=Drawing Board\nExec=draw\nNoDisplay=true\n")
+	expect("hide", s.RescanPaths(drawPath), ChangeRemoved, "Drawing Board")
+	if find("draw.desktop") != nil {
+		t.Error("Hidden entry should be removed")
+	}
+	if results := s.Filter("drawing", entry.AppTypeAll); len(results) != 0 {
+		t.Errorf("Filter(drawing) = %v, want no results", results)
+	}
+
+	// Files outside of the search dirs are ignored
+	outside := filepath.Join(tmpDir, "elsewhere/app.desktop")
+	write(outside, "[Desktop Entry]\nType=Application\nName=Elsewhere\nExec=app\n")
+	if changes := s.RescanPaths(outside); len(changes) != 0 {
+		t.Errorf("outside: changes = %+v, want none", changes)
+	}
+}
+
+func TestRescanDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataHome := filepath.Join(tmpDir, "home")
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	os.Setenv("XDG_DATA_HOME", dataHome)
+	os.Setenv("XDG_DATA_DIRS", filepath.Join(tmpDir, "system"))
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+		os.Unsetenv("XDG_DATA_HOME")
+		os.Unsetenv("XDG_DATA_DIRS")
+	}()
+
+	s, err := NewScanner(false, false)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	before := s.Count()
+
+	// The applications directory didn't exist during the scan
+	kdeDir := filepath.Join(dataHome, "applications/kde")
+	os.MkdirAll(kdeDir, 0755)
+	for _, name := range []string{"dolphin", "kate"} {
+		content := "[Desktop Entry]\nType=Application\nName=" + name + "\nExec=" + name + "\n"
+		os.WriteFile(filepath.Join(kdeDir, name+".desktop"), []byte(content), 0644)
+	}
+
+	changes := s.RescanDirectory(kdeDir)
+	if len(changes) != 2 {
+		t.Fatalf("create: changes = %+v, want 2", changes)
+	}
+	if s.Count() != before+2 {
+		t.Errorf("Count() = %d, want %d", s.Count(), before+2)
+	}
+	for _, change := range changes {
+		if change.Type != ChangeAdded || !strings.HasPrefix(change.Entry.ID, "kde-") {
+			t.Errorf("change = %+v, want added kde- entry", change)
+		}
+	}
+
+	os.RemoveAll(kdeDir)
+	changes = s.RescanDirectory(kdeDir)
+	if len(changes) != 2 || changes[0].Type != ChangeRemoved || changes[1].Type != ChangeRemoved {
+		t.Errorf("remove: changes = %+v, want 2 removals", changes)
+	}
+	if s.Count() != before {
+		t.Errorf("Count() = %d, want %d", s.Count(), before)
+	}
+}
+
+func TestScanUsesEntryCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	appsDir := filepath.Join(tmpDir, "data", "applications")
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmpDir, "data"))
+	os.Setenv("XDG_DATA_DIRS", filepath.Join(tmpDir, "system"))
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+		os.Unsetenv("XDG_DATA_HOME")
+		os.Unsetenv("XDG_DATA_DIRS")
+		os.Unsetenv("LC_MESSAGES")
+	}()
+
+	os.MkdirAll(filepath.Join(appsDir, "sub"), 0755)
+	path := filepath.Join(appsDir, "sub", "notes.desktop")
+	os.WriteFile(path, []byte("[Desktop Entry]\nType=Application\nName=Notes\nName[de]=Notiz\nExec=notes\n"), 0644)
+
+	scan := func() *Scanner {
+		t.Helper()
+		s, err := NewScanner(false, false)
+		if err != nil {
+			t.Fatalf("NewScanner() error = %v", err)
+		}
+		if err := s.Scan(); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		return s
+	}
+
+	names := func(s *Scanner) map[string]string {
+		result := make(map[string]string)
+		for _, e := range s.GetEntries() {
+			result[e.ID] = e.Name
+		}
+		return result
+	}
+
+	scan()
+	if _, err := os.Stat(filepath.Join(tmpDir, "gofi", cacheFileName)); err != nil {
+		t.Fatalf("cache not written: %v", err)
+	}
+
+	// Same size and mtime: the file is not parsed again
+	info, _ := os.Stat(path)
+	os.WriteFile(path, []byte("[Desktop Entry]\nType=Application\nName=Nopes\nName[de]=Notiz\nExec=notes\n"), 0644)
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+	if got := names(scan())["sub-notes.desktop"]; got != "Notes" {
+		t.Errorf("unchanged file: Name = %q, want cached Notes", got)
+	}
+
+	// A different mtime invalidates the file
+	later := info.ModTime().Add(time.Second)
+	os.Chtimes(path, later, later)
+	if got := names(scan())["sub-notes.desktop"]; got != "Nopes" {
+		t.Errorf("modified file: Name = %q, want Nopes", got)
+	}
+
+	// A different locale invalidates the whole cache
+	os.Setenv("LC_MESSAGES", "de_DE.UTF-8")
+	if got := names(scan())["sub-notes.desktop"]; got != "Notiz" {
+		t.Errorf("new locale: Name = %q, want Notiz", got)
+	}
+
+	// New files in a cached directory are found
+	os.WriteFile(filepath.Join(appsDir, "sub", "draw.desktop"), []byte("[Desktop Entry]\nType=Application\nName=Draw\nExec=draw\n"), 0644)
+	if got := names(scan())["sub-draw.desktop"]; got != "Draw" {
+		t.Errorf("new file: Name = %q, want Draw", got)
+	}
+
+	// Removed files are gone and pruned from the cache
+	os.Remove(path)
+	s := scan()
+	if _, ok := names(s)["sub-notes.desktop"]; ok {
+		t.Error("removed file should not be listed")
+	}
+	if _, ok := s.cache.data.Files[path]; ok {
+		t.Error("removed file should be pruned from the cache")
+	}
+}
+
+func TestLoadEntryCacheInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, cacheFileName)
+
+	valid := newEntryCache(path)
+	valid.data.Files["/apps/a.desktop"] = &cachedFile{ModTime: 1, Size: 2, desktopFile: desktopFile{Reason: DropHidden}}
+	valid.dirty = true
+	if err := valid.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	saved, _ := os.ReadFile(path)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{"Valid", string(saved), nil},
+		{"Missing", "", nil},
+		{"Garbage", "not json", ErrCacheCorrupt},
+		{"Truncated", string(saved[:len(saved)/2]), ErrCacheCorrupt},
+		{"Tampered", strings.Replace(string(saved), `"mtime":1`, `"mtime":9`, 1), ErrCacheCorrupt},
+		{"Old version", strings.Replace(string(saved), fmt.Sprintf(`"version":%d`, cacheVersion), `"version":0`, 1), ErrCacheVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(path)
+			if tt.content != "" {
+				os.WriteFile(path, []byte(tt.content), 0644)
+			}
+
+			c, err := loadEntryCache(path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("loadEntryCache() error = %v, want %v", err, tt.wantErr)
+			}
+			if c == nil {
+				t.Fatal("loadEntryCache() should always return a usable cache")
+			}
+
+			_, cached := c.data.Files["/apps/a.desktop"]
+			if cached != (tt.name == "Valid") {
+				t.Errorf("cached = %v, want %v", cached, tt.name == "Valid")
+			}
+		})
+	}
+}
+
+func TestForEach(t *testing.T) {
+	for _, workers := range []int{0, 1, 4, 100} {
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+		calls := make([]int, 50)
+
+		forEach(len(calls), workers, func(i int) {
+			mu.Lock()
+			calls[i]++
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+
+		for i, n := range calls {
+			if n != 1 {
+				t.Errorf("workers=%d: index %d called %d times, want 1", workers, i, n)
+			}
+		}
+		if limit := max(workers, 1); maxRunning > limit {
+			t.Errorf("workers=%d: %d calls ran at once, want at most %d", workers, maxRunning, limit)
+		}
+	}
+}
+
+func TestScanDeterministic(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataHome := filepath.Join(tmpDir, "home")
+	systemDir := filepath.Join(tmpDir, "system")
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	os.Setenv("XDG_DATA_HOME", dataHome)
+	os.Setenv("XDG_DATA_DIRS", systemDir)
+	defer func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+		os.Unsetenv("XDG_DATA_HOME")
+		os.Unsetenv("XDG_DATA_DIRS")
+	}()
+
+	// Every other system file is overridden by the user
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprintf("app%03d.desktop", i)
+		content := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=System %d\nExec=app\n", i)
+		os.MkdirAll(filepath.Join(systemDir, "applications"), 0755)
+		os.WriteFile(filepath.Join(systemDir, "applications", name), []byte(content), 0644)
+		if i%2 == 0 {
+			content = fmt.Sprintf("[Desktop Entry]\nType=Application\nName=User %d\nExec=app\n", i)
+			os.MkdirAll(filepath.Join(dataHome, "applications"), 0755)
+			os.WriteFile(filepath.Join(dataHome, "applications", name), []byte(content), 0644)
+		}
+	}
+
+	scan := func(workers int) ([]string, []DroppedEntry) {
+		os.RemoveAll(filepath.Join(tmpDir, "gofi"))
+		s, err := NewScanner(false, false, WithWorkers(workers))
+		if err != nil {
+			t.Fatalf("NewScanner() error = %v", err)
+		}
+		if err := s.Scan(); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		var names []string
+		for _, e := range s.GetEntries() {
+			names = append(names, e.Name)
+		}
+		return names, s.Dropped()
+	}
+
+	wantNames, wantDropped := scan(1)
+	for run := 0; run < 3; run++ {
+		names, dropped := scan(16)
+		if !reflect.DeepEqual(names, wantNames) {
+			t.Fatalf("run %d: entries differ from sequential scan", run)
+		}
+		if !reflect.DeepEqual(dropped, wantDropped) {
+			t.Fatalf("run %d: dropped files differ from sequential scan", run)
+		}
+	}
+}
+
 func TestFilterExistingPaths(t *testing.T) {
 	tmpDir := t.TempDir()
 	existingPath := filepath.Join(tmpDir, "existing")
@@ -0,0 +1,11 @@
+package watcher
+
+import "errors"
+
+var (
+	// ErrUnsupported indicates file system notifications are not available
+	ErrUnsupported = errors.New("watcher: file system notifications not supported")
+
+	// ErrClosed indicates the watcher was already closed
+	ErrClosed = errors.New("watcher: closed")
+)
@@ -0,0 +1,182 @@
+//go:build linux
+
+package watcher
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// watchMask selects the notifications that can change the entries
+const watchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_CLOSE_WRITE |
+	syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF | syscall.IN_ONLYDIR
+
+// inotify wraps an inotify instance watching a set of directories
+type inotify struct {
+	fd      int
+	file    *os.File // Non-blocking, so closing it interrupts a pending read
+	mu      sync.Mutex
+	closed  bool
+	watches map[int32]string // key: watch descriptor
+	paths   map[string]int32
+}
+
+// rawEvent is a single file system notification
+type rawEvent struct {
+	path     string
+	dir      bool // The event is about a directory
+	overflow bool // Events were lost, everything must be rescanned
+}
+
+// newInotify creates a new inotify instance
+func newInotify() (*inotify, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("creating inotify instance: %w", err)
+	}
+
+	return &inotify{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		watches: make(map[int32]string),
+		paths:   make(map[string]int32),
+	}, nil
+}
+
+// add starts watching a directory
+func (n *inotify) add(path string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return ErrClosed
+	}
+	if _, ok := n.paths[path]; ok {
+		return nil
+	}
+
+	wd, err := syscall.InotifyAddWatch(n.fd, path, watchMask)
+	if err != nil {
+		return fmt.Errorf("watching %s: %w", path, err)
+	}
+
+	// Watching the same inode under another name returns the same descriptor
+	if old, ok := n.watches[int32(wd)]; ok {
+		delete(n.paths, old)
+	}
+	n.watches[int32(wd)] = path
+	n.paths[path] = int32(wd)
+	return nil
+}
+
+// remove stops watching a directory
+func (n *inotify) remove(path string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	wd, ok := n.paths[path]
+	if !ok {
+		return
+	}
+	delete(n.paths, path)
+	delete(n.watches, wd)
+	if !n.closed {
+		syscall.InotifyRmWatch(n.fd, uint32(wd))
+	}
+}
+
+// watched returns the directories currently watched
+func (n *inotify) watched() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	paths := make([]string, 0, len(n.paths))
+	for path := range n.paths {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// read delivers events until the instance is closed or done is closed
+func (n *inotify) read(events chan<- rawEvent, done <-chan struct{}) error {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			n.mu.Lock()
+			closed := n.closed
+			n.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("reading inotify events: %w", err)
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= count; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			name := string(bytes.TrimRight(buf[nameStart:nameStart+int(raw.Len)], "\x00"))
+			offset = nameStart + int(raw.Len)
+
+			if event, ok := n.translate(raw.Wd, raw.Mask, name); ok {
+				select {
+				case events <- event:
+				case <-done:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// translate turns a kernel event into a rawEvent
+func (n *inotify) translate(wd int32, mask uint32, name string) (rawEvent, bool) {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		return rawEvent{overflow: true}, true
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	dir, ok := n.watches[wd]
+	if !ok {
+		return rawEvent{}, false
+	}
+
+	// The kernel dropped the watch, the directory is gone
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(n.watches, wd)
+		if n.paths[dir] == wd {
+			delete(n.paths, dir)
+		}
+		return rawEvent{path: dir, dir: true}, true
+	}
+
+	if name == "" {
+		return rawEvent{path: dir, dir: true}, true
+	}
+	return rawEvent{
+		path: filepath.Join(dir, name),
+		dir:  mask&syscall.IN_ISDIR != 0,
+	}, true
+}
+
+// close releases the inotify instance, ending read
+func (n *inotify) close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+	n.closed = true
+	n.mu.Unlock()
+
+	return n.file.Close()
+}
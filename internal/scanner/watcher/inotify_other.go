@@ -0,0 +1,35 @@
+//go:build !linux
+
+package watcher
+
+// inotify is unavailable outside of Linux
+type inotify struct{}
+
+// rawEvent is a single file system notification
+type rawEvent struct {
+	path     string
+	dir      bool
+	overflow bool
+}
+
+func newInotify() (*inotify, error) {
+	return nil, ErrUnsupported
+}
+
+func (n *inotify) add(path string) error {
+	return ErrUnsupported
+}
+
+func (n *inotify) remove(path string) {}
+
+func (n *inotify) watched() []string {
+	return nil
+}
+
+func (n *inotify) read(events chan<- rawEvent, done <-chan struct{}) error {
+	return ErrUnsupported
+}
+
+func (n *inotify) close() error {
+	return nil
+}
@@ -0,0 +1,389 @@
+// Package watcher keeps the scanner up to date by watching the application
+// directories and game launcher libraries for changes
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/scanner"
+)
+
+const (
+	// DefaultDebounce is how long to wait for more events before rescanning.
+	// Package managers touch many files at once, this folds them into one batch.
+	DefaultDebounce = 300 * time.Millisecond
+
+	// subscriberBuffer is the number of batches a slow subscriber may lag
+	subscriberBuffer = 16
+)
+
+// Event is a batch of entry changes applied to the scanner
+type Event struct {
+	Changes []scanner.Change
+}
+
+// Watcher watches the directories the scanner reads and rescans on change
+type Watcher struct {
+	scanner  *scanner.Scanner
+	notify   *inotify
+	debounce time.Duration
+
+	// Paths game launchers read, a change below any of them rescans launchers
+	launcherPaths []string
+
+	mu          sync.Mutex
+	subscribers []chan Event
+	closed      bool
+	done        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// Option is a functional option for Watcher
+type Option func(*Watcher)
+
+// WithDebounce sets how long to wait for further events before rescanning
+func WithDebounce(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.debounce = d
+	}
+}
+
+// New creates a watcher for the scanner's directories. The scanner must have
+// completed its initial scan.
+func New(s *scanner.Scanner, opts ...Option) (*Watcher, error) {
+	notify, err := newInotify()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		scanner:       s,
+		notify:        notify,
+		debounce:      DefaultDebounce,
+		launcherPaths: cleanPaths(s.WatchPaths()),
+		done:          make(chan struct{}),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.refreshWatches()
+
+	return w, nil
+}
+
+// Subscribe returns a channel receiving every batch of changes. Batches are
+// dropped for subscribers that fall behind, so receivers should re-read the
+// scanner rather than rely on seeing every change. The channel is closed by
+// Close.
+func (w *Watcher) Subscribe() <-chan Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	if w.closed {
+		close(ch)
+		return ch
+	}
+	w.subscribers = append(w.subscribers, ch)
+	return ch
+}
+
+// Start begins processing file system events in the background
+func (w *Watcher) Start() {
+	events := make(chan rawEvent, 64)
+
+	w.wg.Add(2)
+	go func() {
+		defer w.wg.Done()
+		defer close(events)
+		if err := w.notify.read(events, w.done); err != nil && os.Getenv("DEBUG") == "1" {
+			fmt.Printf("Watcher stopped: %v\n", err)
+		}
+	}()
+	go func() {
+		defer w.wg.Done()
+		w.loop(events)
+	}()
+}
+
+// LoadGameLaunchers adds the game launcher entries in the background for a
+// scanner started with ScanApplications, publishing the games of each
+// launcher as it finishes. Close stops waiting on them.
+func (w *Watcher) LoadGameLaunchers() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer cancel()
+
+		go func() {
+			select {
+			case <-w.done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		w.scanner.LoadGameLaunchers(ctx, func(changes []scanner.Change) {
+			if os.Getenv("DEBUG") == "1" {
+				for _, change := range changes {
+					fmt.Printf("Loaded: %s %s\n", change.Type, change.Entry.Path)
+				}
+			}
+			w.publish(Event{Changes: changes})
+		})
+	}()
+}
+
+// Close stops watching and closes all subscriber channels
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+	w.closed = true
+	close(w.done)
+	w.mu.Unlock()
+
+	err := w.notify.close()
+	w.wg.Wait()
+
+	w.mu.Lock()
+	for _, ch := range w.subscribers {
+		close(ch)
+	}
+	w.subscribers = nil
+	w.mu.Unlock()
+
+	return err
+}
+
+// batch collects the events seen during one debounce period
+type batch struct {
+	paths     []string
+	dirs      []string
+	launchers bool
+	rescanAll bool
+}
+
+// empty reports whether the batch needs no work
+func (b *batch) empty() bool {
+	return len(b.paths) == 0 && len(b.dirs) == 0 && !b.launchers && !b.rescanAll
+}
+
+// loop gathers events and processes them once they stop arriving
+func (w *Watcher) loop(events <-chan rawEvent) {
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	pending := &batch{}
+	for {
+		select {
+		case <-w.done:
+			timer.Stop()
+			return
+
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if w.classify(event, pending) {
+				timer.Reset(w.debounce)
+			}
+
+		case <-timer.C:
+			w.process(pending)
+			pending = &batch{}
+		}
+	}
+}
+
+// classify adds an event to the batch and reports whether it was relevant
+func (w *Watcher) classify(event rawEvent, b *batch) bool {
+	if event.overflow {
+		b.rescanAll = true
+		return true
+	}
+
+	relevant := false
+
+	if w.relatedToLauncher(event.path) {
+		b.launchers = true
+		relevant = true
+	}
+
+	for _, dir := range w.scanner.SearchDirs() {
+		switch {
+		case event.dir && (within(dir, event.path) || within(event.path, dir)):
+			// A directory appeared or vanished in or above a search dir
+			b.dirs = append(b.dirs, event.path)
+			return true
+		case !event.dir && within(dir, event.path) && strings.HasSuffix(event.path, ".desktop"):
+			b.paths = append(b.paths, event.path)
+			return true
+		}
+	}
+
+	return relevant
+}
+
+// relatedToLauncher reports whether a path is, contains or is inside a
+// launcher path
+func (w *Watcher) relatedToLauncher(path string) bool {
+	for _, launcherPath := range w.launcherPaths {
+		if within(launcherPath, path) || within(path, launcherPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// process applies a batch to the scanner and notifies subscribers
+func (w *Watcher) process(b *batch) {
+	if b.empty() {
+		return
+	}
+
+	var changes []scanner.Change
+	if b.rescanAll {
+		for _, dir := range w.scanner.SearchDirs() {
+			changes = append(changes, w.scanner.RescanDirectory(dir)...)
+		}
+		b.launchers = true
+	} else {
+		changes = append(changes, w.scanner.RescanPaths(b.paths...)...)
+		for _, dir := range b.dirs {
+			changes = append(changes, w.rescanDirectory(dir)...)
+		}
+	}
+
+	if b.launchers {
+		changes = append(changes, w.scanner.RescanGameLaunchers()...)
+	}
+
+	// Directories may have been created or removed
+	if len(b.dirs) > 0 || b.rescanAll || b.launchers {
+		w.refreshWatches()
+	}
+
+	if os.Getenv("DEBUG") == "1" {
+		for _, change := range changes {
+			fmt.Printf("Rescan: %s %s\n", change.Type, change.Entry.Path)
+		}
+	}
+
+	if len(changes) > 0 {
+		w.publish(Event{Changes: changes})
+	}
+}
+
+// rescanDirectory rescans a directory that is inside a search dir, or the
+// search dirs inside a directory that was created above them
+func (w *Watcher) rescanDirectory(dir string) []scanner.Change {
+	var changes []scanner.Change
+	for _, searchDir := range w.scanner.SearchDirs() {
+		if within(searchDir, dir) {
+			return w.scanner.RescanDirectory(dir)
+		}
+		if within(dir, searchDir) {
+			changes = append(changes, w.scanner.RescanDirectory(searchDir)...)
+		}
+	}
+	return changes
+}
+
+// publish sends an event to every subscriber without blocking
+func (w *Watcher) publish(event Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, ch := range w.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is behind, it will catch up with the next batch
+		}
+	}
+}
+
+// refreshWatches watches every existing search dir with its subdirectories,
+// the parent of each launcher path, and for paths that don't exist yet the
+// closest existing ancestor so their creation is noticed
+func (w *Watcher) refreshWatches() {
+	wanted := make(map[string]bool)
+
+	for _, dir := range w.scanner.SearchDirs() {
+		if !isDir(dir) {
+			wanted[existingAncestor(dir)] = true
+			continue
+		}
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.IsDir() {
+				wanted[path] = true
+			}
+			return nil
+		})
+	}
+
+	for _, path := range w.launcherPaths {
+		if isDir(path) {
+			wanted[path] = true
+		}
+		wanted[existingAncestor(filepath.Dir(path))] = true
+	}
+
+	for _, path := range w.notify.watched() {
+		if !wanted[path] {
+			w.notify.remove(path)
+		}
+	}
+	for path := range wanted {
+		if err := w.notify.add(path); err != nil && os.Getenv("DEBUG") == "1" {
+			fmt.Printf("Error watching %s: %v\n", path, err)
+		}
+	}
+}
+
+// existingAncestor returns the closest existing directory at or above path
+func existingAncestor(path string) string {
+	for !isDir(path) {
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+	return path
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// within reports whether path is dir or below it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// cleanPaths returns the paths in canonical form
+func cleanPaths(paths []string) []string {
+	cleaned := make([]string, len(paths))
+	for i, path := range paths {
+		cleaned[i] = filepath.Clean(path)
+	}
+	return cleaned
+}
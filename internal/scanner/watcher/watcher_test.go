@@ -0,0 +1,149 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/scanner"
+	"github.com/antoniosarro/gofi/internal/scanner/launchers"
+)
+
+func newTestScanner(t *testing.T) (*scanner.Scanner, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	os.Setenv("HOME", tmpDir)
+	os.Setenv("XDG_CACHE_HOME", tmpDir)
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmpDir, "data"))
+	os.Setenv("XDG_DATA_DIRS", filepath.Join(tmpDir, "system"))
+	t.Cleanup(func() {
+		os.Unsetenv("HOME")
+		os.Unsetenv("XDG_CACHE_HOME")
+		os.Unsetenv("XDG_DATA_HOME")
+		os.Unsetenv("XDG_DATA_DIRS")
+	})
+
+	s, err := scanner.NewScanner(false, false)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	return s, tmpDir
+}
+
+func waitForChange(t *testing.T, events <-chan Event, changeType scanner.ChangeType, name string) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			for _, change := range event.Changes {
+				if change.Type == changeType && change.Entry.Name == name {
+					return
+				}
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s change of %q", changeType, name)
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	s, tmpDir := newTestScanner(t)
+
+	w, err := New(s, WithDebounce(20*time.Millisecond))
+	if err == ErrUnsupported {
+		t.Skip("file system notifications not supported")
+	}
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	events := w.Subscribe()
+	w.Start()
+
+	// Neither the data dir nor its applications dir exist yet
+	appsDir := filepath.Join(tmpDir, "data", "applications")
+	os.MkdirAll(appsDir, 0755)
+	path := filepath.Join(appsDir, "notes.desktop")
+	os.WriteFile(path, []byte("[Desktop Entry]\nType=Application\nName=Notes\nExec=notes\n"), 0644)
+	waitForChange(t, events, scanner.ChangeAdded, "Notes")
+
+	os.WriteFile(path, []byte("[Desktop Entry]\nType=Application\nName=Notebook\nExec=notes\n"), 0644)
+	waitForChange(t, events, scanner.ChangeUpdated, "Notebook")
+
+	os.Remove(path)
+	waitForChange(t, events, scanner.ChangeRemoved, "Notebook")
+}
+
+func TestWatcherClose(t *testing.T) {
+	s, _ := newTestScanner(t)
+
+	w, err := New(s)
+	if err == ErrUnsupported {
+		t.Skip("file system notifications not supported")
+	}
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	events := w.Subscribe()
+	w.Start()
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, ok := <-events; ok {
+		t.Error("Subscriber channel should be closed")
+	}
+	if err := w.Close(); err != ErrClosed {
+		t.Errorf("second Close() error = %v, want ErrClosed", err)
+	}
+}
+
+// gameLauncher is a game launcher with a single game
+type gameLauncher struct{}
+
+func (l *gameLauncher) Name() string {
+	return "stub-watcher"
+}
+
+func (l *gameLauncher) Scan(ctx context.Context) ([]*entry.Entry, error) {
+	return []*entry.Entry{{Name: "Stub Game", Exec: "stub", Path: "stub-watcher-game"}}, nil
+}
+
+func TestWatcherLoadGameLaunchers(t *testing.T) {
+	newTestScanner(t)
+	launchers.Register(&gameLauncher{})
+	defer launchers.Unregister("stub-watcher")
+
+	s, err := scanner.NewScanner(false, true)
+	if err != nil {
+		t.Fatalf("NewScanner() error = %v", err)
+	}
+	if err := s.ScanApplications(); err != nil {
+		t.Fatalf("ScanApplications() error = %v", err)
+	}
+
+	w, err := New(s)
+	if err == ErrUnsupported {
+		t.Skip("file system notifications not supported")
+	}
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	events := w.Subscribe()
+	w.Start()
+	w.LoadGameLaunchers()
+	waitForChange(t, events, scanner.ChangeAdded, "Stub Game")
+}
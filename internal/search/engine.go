@@ -1,8 +1,11 @@
 package search
 
 import (
+	"fmt"
+	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
 	"github.com/antoniosarro/gofi/internal/search/fuzzy"
@@ -21,9 +24,28 @@ const (
 	PrefixMatch
 	FuzzyMatch
 	ContainsMatch
+	TypoMatch // Name misspelled by a few characters
 	TokenMatch
 )
 
+// matchTypeNames are the names of the match types, for logging
+var matchTypeNames = map[MatchType]string{
+	ExactMatch:    "exact",
+	PrefixMatch:   "prefix",
+	FuzzyMatch:    "fuzzy",
+	ContainsMatch: "contains",
+	TypoMatch:     "typo",
+	TokenMatch:    "token",
+}
+
+// String returns the name of the match type
+func (m MatchType) String() string {
+	if name, ok := matchTypeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("MatchType(%d)", int(m))
+}
+
 // ScoredEntry represents an entry with its match score
 type ScoredEntry struct {
 	Entry     *entry.Entry
@@ -33,8 +55,11 @@ type ScoredEntry struct {
 
 // Engine provides optimized search with caching and fuzzy matching
 type Engine struct {
-	indexer      *Indexer
-	fuzzyMatcher *fuzzy.Matcher
+	indexer       *Indexer
+	fuzzyMatcher  *fuzzy.Matcher
+	typoThreshold int
+	isFavorite    func(*entry.Entry) bool // Entries fav: filters for
+	now           func() time.Time        // Clock metadata filters compare against
 }
 
 // Option is a functional option for Engine
@@ -47,11 +72,29 @@ func WithFuzzyMatcher(matcher *fuzzy.Matcher) Option {
 	}
 }
 
+// WithTypoThreshold sets how many query characters tolerate one typo in
+// names. Zero or less disables typo tolerance.
+func WithTypoThreshold(threshold int) Option {
+	return func(e *Engine) {
+		e.typoThreshold = threshold
+	}
+}
+
+// WithFavorites sets how favorites are told apart, for fav: filters.
+// Without it no entry is a favorite.
+func WithFavorites(isFavorite func(*entry.Entry) bool) Option {
+	return func(e *Engine) {
+		e.isFavorite = isFavorite
+	}
+}
+
 // New creates a new search engine
 func New(entries []*entry.Entry, opts ...Option) *Engine {
 	e := &Engine{
-		indexer:      NewIndexer(),
-		fuzzyMatcher: fuzzy.New(),
+		indexer:       NewIndexer(),
+		fuzzyMatcher:  fuzzy.New(),
+		typoThreshold: DefaultTypoThreshold,
+		now:           time.Now,
 	}
 
 	// Apply options
@@ -65,17 +108,50 @@ func New(entries []*entry.Entry, opts ...Option) *Engine {
 	return e
 }
 
-// Search performs optimized search with ranking and score filtering
-func (e *Engine) Search(query string, appType entry.AppType, entries []*entry.Entry) []*entry.Entry {
-	if query == "" {
-		// Return all entries of the specified type
-		return filterByType(entries, appType)
+// Search performs optimized search with ranking and score filtering.
+// Queries may filter entries, as in "type:flatpak -steam"; see package
+// query. Queries that only filter keep the order of entries, without
+// fuzzy scoring.
+func (e *Engine) Search(q string, appType entry.AppType, entries []*entry.Entry) []*entry.Entry {
+	scored, _ := e.SearchScored(q, appType, entries)
+
+	// Extract entries
+	result := make([]*entry.Entry, len(scored))
+	for i := range scored {
+		result[i] = scored[i].Entry
+	}
+
+	return result
+}
+
+// SearchScored is Search keeping the score of each result. scored reports
+// whether the query had text to score results by; results of queries that
+// don't, such as filters, keep the order of entries and have no score.
+func (e *Engine) SearchScored(q string, appType entry.AppType, entries []*entry.Entry) (results []ScoredEntry, scored bool) {
+	cq, err := compileQuery(q, e.now(), e.isFavorite)
+	if err != nil {
+		if os.Getenv("DEBUG") == "1" {
+			fmt.Printf("Searching %q as text: %v\n", q, err)
+		}
+		cq = textOnly(q)
 	}
 
-	queryLower := strings.ToLower(query)
-	queryTokens := tokenize(query)
+	if !cq.scored {
+		// Return all entries of the specified type the filters keep
+		results = make([]ScoredEntry, 0)
+		for _, ent := range filterByType(entries, appType) {
+			if cq.root != nil {
+				index := e.indexer.Get(ent.Path)
+				if index == nil || !e.match(cq.root, cq, ent, index).ok {
+					continue
+				}
+			}
+			results = append(results, ScoredEntry{Entry: ent, MatchType: TokenMatch})
+		}
+		return results, false
+	}
 
-	scored := make([]ScoredEntry, 0)
+	results = make([]ScoredEntry, 0)
 
 	for _, ent := range entries {
 		// Filter by app type first
@@ -89,35 +165,39 @@ func (e *Engine) Search(query string, appType entry.AppType, entries []*entry.En
 			continue
 		}
 
-		score, matchType := e.scoreEntry(query, queryLower, queryTokens, index)
+		// Score the entry followed by its desktop actions
+		candidates := append([]*Index{index}, index.Actions...)
+		for i, candidate := range candidates {
+			m := e.match(cq.root, cq, ent, candidate)
 
-		// Filter out results below minimum score threshold
-		if score < MinimumScore {
-			continue
-		}
+			// Filter out entries failing the query or below minimum
+			// score threshold. Actions only show up for their text.
+			if !m.ok || i > 0 && !m.scored {
+				continue
+			}
+			if !m.scored {
+				// Matched by filters alone, after all text matches
+				m.score, m.matchType = 0, TokenMatch
+			}
 
-		scored = append(scored, ScoredEntry{
-			Entry:     ent,
-			Score:     score,
-			MatchType: matchType,
-		})
+			results = append(results, ScoredEntry{
+				Entry:     candidate.Entry,
+				Score:     m.score,
+				MatchType: m.matchType,
+			})
+		}
 	}
 
-	// Sort by match type first, then by score
-	sort.Slice(scored, func(i, j int) bool {
-		if scored[i].MatchType != scored[j].MatchType {
-			return scored[i].MatchType < scored[j].MatchType
+	// Sort by match type first, then by score.
+	// The stable sort keeps applications ahead of their actions on ties.
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].MatchType != results[j].MatchType {
+			return results[i].MatchType < results[j].MatchType
 		}
-		return scored[i].Score > scored[j].Score
+		return results[i].Score > results[j].Score
 	})
 
-	// Extract entries
-	result := make([]*entry.Entry, len(scored))
-	for i := range scored {
-		result[i] = scored[i].Entry
-	}
-
-	return result
+	return results, true
 }
 
 // scoreEntry calculates the score and match type for an entry
@@ -149,11 +229,19 @@ func (e *Engine) scoreEntry(query, queryLower string, queryTokens []string, inde
 		if strings.Contains(index.NameNormalized, queryLower) {
 			score = 400
 			matchType = ContainsMatch
-		} else if strings.Contains(strings.ToLower(index.Entry.Comment), queryLower) {
+		} else if matchKeywords(queryLower, index.Keywords) {
+			score = 300
+			matchType = ContainsMatch
+		} else if strings.Contains(fuzzy.Fold(index.Entry.Comment), queryLower) {
 			score = 200
 			matchType = ContainsMatch
+		} else if distance, ok := e.matchTypo(queryLower, index); ok {
+			// 5. Misspelled name, fewer typos scoring higher
+			maxEdits := maxTypos(queryLower, e.typoThreshold)
+			score = 100 + 200*(maxEdits-distance)/maxEdits
+			matchType = TypoMatch
 		} else if matchTokens(queryTokens, index.CommentTokens) {
-			// 5. Token-based match
+			// 6. Token-based match
 			score = 100
 			matchType = TokenMatch
 		} else {
@@ -164,14 +252,19 @@ func (e *Engine) scoreEntry(query, queryLower string, queryTokens []string, inde
 
 	// Bonus for generic name match
 	if index.Entry.GenericName != "" {
-		if strings.Contains(strings.ToLower(index.Entry.GenericName), queryLower) {
+		if strings.Contains(fuzzy.Fold(index.Entry.GenericName), queryLower) {
 			score += 100
 		}
 	}
 
+	// Bonus for keyword match
+	if matchType == FuzzyMatch && matchKeywords(queryLower, index.Keywords) {
+		score += 75
+	}
+
 	// Bonus for category match
 	for _, cat := range index.CategoryTokens {
-		if strings.Contains(strings.ToLower(cat), queryLower) {
+		if strings.Contains(fuzzy.Fold(cat), queryLower) {
 			score += 50
 			break
 		}
@@ -180,6 +273,16 @@ func (e *Engine) scoreEntry(query, queryLower string, queryTokens []string, inde
 	return score, matchType
 }
 
+// matchTypo returns how many typos the query is away from the name, if it
+// is within the typo threshold
+func (e *Engine) matchTypo(queryLower string, index *Index) (int, bool) {
+	maxEdits := maxTypos(queryLower, e.typoThreshold)
+	if maxEdits == 0 {
+		return 0, false
+	}
+	return typoDistance(queryLower, index.NameNormalized, maxEdits)
+}
+
 // UpdateIndex updates the search index for a new/modified entry
 func (e *Engine) UpdateIndex(ent *entry.Entry) {
 	e.indexer.Add(ent)
@@ -194,7 +297,7 @@ func (e *Engine) RemoveIndex(path string) {
 func matchTokens(queryTokens, targetTokens []string) bool {
 	for _, qt := range queryTokens {
 		for _, tt := range targetTokens {
-			if strings.Contains(strings.ToLower(tt), strings.ToLower(qt)) {
+			if strings.Contains(fuzzy.Fold(tt), fuzzy.Fold(qt)) {
 				return true
 			}
 		}
@@ -202,6 +305,16 @@ func matchTokens(queryTokens, targetTokens []string) bool {
 	return false
 }
 
+// matchKeywords checks if any keyword starts with the query
+func matchKeywords(queryLower string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.HasPrefix(keyword, queryLower) {
+			return true
+		}
+	}
+	return false
+}
+
 // filterByType filters entries by app type
 func filterByType(entries []*entry.Entry, appType entry.AppType) []*entry.Entry {
 	if appType == entry.AppTypeAll {
@@ -1,10 +1,16 @@
 package search
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
 	"github.com/antoniosarro/gofi/internal/search/fuzzy"
+	"github.com/antoniosarro/gofi/internal/search/query"
 )
 
 func TestNew(t *testing.T) {
@@ -100,6 +106,62 @@ func TestSearchFuzzyMatch(t *testing.T) {
 	}
 }
 
+func TestSearchActions(t *testing.T) {
+	entries := []*entry.Entry{
+		{
+			Name: "Firefox",
+			Path: "/path/firefox.desktop",
+			Exec: "firefox",
+			Actions: []entry.Action{
+				{ID: "new-private-window", Name: "New Private Window", Exec: "firefox --private-window"},
+			},
+		},
+		{Name: "Chrome", Path: "/path/chrome.desktop", Exec: "chrome"},
+	}
+
+	engine := New(entries)
+
+	results := engine.Search("private", entry.AppTypeAll, entries)
+	if len(results) == 0 {
+		t.Fatal("Search() returned no results for action name")
+	}
+	if results[0].Name != "Firefox › New Private Window" {
+		t.Errorf("First result = %s, want Firefox › New Private Window", results[0].Name)
+	}
+	if results[0].Exec != "firefox --private-window" {
+		t.Errorf("Action Exec = %s, want firefox --private-window", results[0].Exec)
+	}
+
+	// The application ranks ahead of its actions
+	results = engine.Search("fire", entry.AppTypeAll, entries)
+	if len(results) < 2 {
+		t.Fatalf("Search() returned %d results, want 2", len(results))
+	}
+	if results[0].Name != "Firefox" {
+		t.Errorf("First result = %s, want Firefox", results[0].Name)
+	}
+
+	// Actions are not listed without a query
+	results = engine.Search("", entry.AppTypeAll, entries)
+	if len(results) != 2 {
+		t.Errorf("Search(\"\") returned %d results, want 2", len(results))
+	}
+}
+
+func TestSearchKeywordMatch(t *testing.T) {
+	entries := []*entry.Entry{
+		{Name: "Firefox", Path: "/path/firefox.desktop", Exec: "firefox", Keywords: []string{"Internet", "WWW", "Netz"}},
+		{Name: "Thunderbird", Path: "/path/thunderbird.desktop", Exec: "thunderbird", Keywords: []string{"Mail"}},
+	}
+
+	engine := New(entries)
+	results := engine.Search("netz", entry.AppTypeAll, entries)
+
+	if len(results) != 1 || results[0].Name != "Firefox" {
+		t.Errorf("Search(netz) = %v, want [Firefox]", results)
+	}
+}
+
 func TestSearchByAppType(t *testing.T) {
 	entries := []*entry.Entry{
 		{
@@ -384,6 +446,318 @@ func TestFilterByType(t *testing.T) {
 	}
 }
 
+func TestSearchUnicode(t *testing.T) {
+	entries := []*entry.Entry{
+		{Name: "Mensch ärgere dich nicht", Path: "/path/aergere.desktop", Comment: "Brettspiel"},
+		{Name: "Ärger", Path: "/path/aerger.desktop"},
+		{Name: "系统设置", Path: "/path/settings.desktop"},
+		{Name: "Firefox", Path: "/path/firefox.desktop", Comment: "Navigateur Web sécurisé"},
+	}
+
+	engine := New(entries)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"arger", "Ärger"},
+		{"ÄRGER", "Ärger"},
+		{"设置", "系统设置"},
+		{"securise", "Firefox"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := engine.Search(tt.query, entry.AppTypeAll, entries)
+			if len(results) == 0 || results[0].Name != tt.want {
+				t.Errorf("Search(%q) = %v, want %s first", tt.query, results, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchTypos(t *testing.T) {
+	entries := []*entry.Entry{
+		{Name: "Firefox", Path: "/path/firefox.desktop", Comment: "Browse the web"},
+		{Name: "Thunderbird", Path: "/path/thunderbird.desktop", Comment: "Read email"},
+		{Name: "Fox Tracker", Path: "/path/tracker.desktop", Keywords: []string{"fierfox"}},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		threshold int
+		want      []string
+	}{
+		{"Transposition", "fierfox", DefaultTypoThreshold, []string{"Fox Tracker", "Firefox"}},
+		{"Missing letter", "thudnerbrd", DefaultTypoThreshold, []string{"Thunderbird"}},
+		{"Being typed", "thudner", DefaultTypoThreshold, []string{"Thunderbird"}},
+		{"Too many typos", "tihnderbord", DefaultTypoThreshold, nil},
+		{"Stricter threshold", "thudnerbrd", 20, nil},
+		{"Disabled", "fierfox", 0, []string{"Fox Tracker"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := New(entries, WithTypoThreshold(tt.threshold))
+			var names []string
+			for _, e := range engine.Search(tt.query, entry.AppTypeAll, entries) {
+				names = append(names, e.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchMetadataFilters(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	game := func(name, store string, daysAgo int, playtime time.Duration) *entry.Entry {
+		e := &entry.Entry{Name: name, Path: "game-" + name, Categories: []string{"Game"}}
+		e.SetMeta(entry.MetaStore, store)
+		if daysAgo >= 0 {
+			e.SetLastPlayed(now.AddDate(0, 0, -daysAgo))
+		}
+		e.SetPlaytime(playtime)
+		return e
+	}
+	entries := []*entry.Entry{
+		game("Portal 2", "steam", 2, 12*time.Hour),
+		game("Hades", "epic", 40, 30*time.Hour),
+		game("Celeste", "steam", -1, 30*time.Minute),
+		game("Inscryption", "gog", -1, 0),
+		{Name: "Portal Viewer", Path: "/usr/share/applications/portal-viewer.desktop"},
+	}
+
+	engine := New(entries)
+	engine.now = func() time.Time { return now }
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"played:<7d", []string{"Portal 2"}},
+		{"played:7d", []string{"Portal 2"}},
+		{"played:>1w", []string{"Hades"}},
+		{"playtime:>20h", []string{"Hades"}},
+		{"playtime:<1h", []string{"Celeste"}}, // Not Inscryption, without a playtime
+		{"store:Steam", []string{"Portal 2", "Celeste"}},
+		{"store:steam played:<1y", []string{"Portal 2"}},
+		{"portal store:steam", []string{"Portal 2"}},
+		{"runner:wine", nil},
+		// Not a valid filter, searched as text
+		{"played:soon", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var names []string
+			for _, e := range engine.Search(tt.query, entry.AppTypeAll, entries) {
+				names = append(names, e.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchQuerySyntax(t *testing.T) {
+	entries := []*entry.Entry{
+		{Name: "Firefox", Path: "/usr/share/applications/firefox.desktop", Comment: "Browse the web", Categories: []string{"Network", "WebBrowser"}},
+		{Name: "GIMP", Path: "/var/lib/flatpak/exports/share/applications/org.gimp.GIMP.desktop", Comment: "Edit images", Categories: []string{"Graphics", "2DGraphics"}},
+		{Name: "Inkscape", Path: "/usr/share/applications/inkscape.desktop", Comment: "Draw vector graphics", Categories: []string{"Graphics"}},
+		{Name: "htop", Path: "/usr/share/applications/htop.desktop", Comment: "Show system processes", Terminal: true, Categories: []string{"System"}},
+		{Name: "Fire Emblem", Path: "/usr/share/applications/fire-emblem.desktop", Comment: "Strategy game", Categories: []string{"Game"}},
+	}
+
+	engine := New(entries, WithFavorites(func(e *entry.Entry) bool {
+		return e.Name == "Firefox" || e.Name == "htop"
+	}))
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"type:flatpak", []string{"GIMP"}},
+		{"cat:graphics", []string{"GIMP", "Inkscape"}},
+		{"cat:graphics -type:flatpak", []string{"Inkscape"}},
+		{"term:true", []string{"htop"}},
+		{"term:", []string{"htop"}},
+		{"fav:", []string{"Firefox", "htop"}},
+		{"fav:false cat:game", []string{"Fire Emblem"}},
+		{"fire", []string{"Firefox", "Fire Emblem"}},
+		{"fire -emblem", []string{"Firefox"}},
+		{"fire cat:game", []string{"Fire Emblem"}},
+		{`"vector graphics"`, []string{"Inkscape"}},
+		{`"graphics vector"`, nil},
+		{"inkscape | htop", []string{"Inkscape", "htop"}},
+		{"htop | type:flatpak", []string{"htop", "GIMP"}},
+		{"cat:graphics | term:true", []string{"GIMP", "Inkscape", "htop"}},
+		// Mistakes are searched as text
+		{"type:flatpk", nil},
+		{`"firefox`, []string{"Firefox"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var names []string
+			for _, e := range engine.Search(tt.query, entry.AppTypeAll, entries) {
+				names = append(names, e.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query  string
+		want   string // Error message, empty if valid
+		column int
+	}{
+		{"type:flatpak cat:dev -fire | fav:", "", 0},
+		{"Re:Zero", "", 0},
+		{"fire type:flatpk", `unknown type "flatpk"`, 6},
+		{"term:maybe", `term: "maybe" is neither true nor false`, 1},
+		{"cat:", "cat: needs a category", 1},
+		{"played:soon", `played: "soon" is not a time span`, 1},
+		{"fire |", "| needs a term on both sides", 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("ParseQuery(%q) error = %v", tt.query, err)
+				}
+				return
+			}
+
+			var syntaxErr *query.SyntaxError
+			if !errors.As(err, &syntaxErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("ParseQuery(%q) error = %v, want %q", tt.query, err, tt.want)
+			}
+			if syntaxErr.Column() != tt.column {
+				t.Errorf("ParseQuery(%q) column = %d, want %d", tt.query, syntaxErr.Column(), tt.column)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	exact := &entry.Entry{Name: "Code"}
+	prefix := &entry.Entry{Name: "Codec Manager"}
+	fuzzy := &entry.Entry{Name: "Color Designer"}
+	contains := &entry.Entry{Name: "Barcode"}
+	scored := []ScoredEntry{
+		{Entry: exact, Score: 1000, MatchType: ExactMatch},
+		{Entry: prefix, Score: 800, MatchType: PrefixMatch},
+		{Entry: fuzzy, Score: 150, MatchType: FuzzyMatch},
+		{Entry: contains, Score: 400, MatchType: ContainsMatch},
+	}
+
+	tests := []struct {
+		name     string
+		frecency map[*entry.Entry]float64
+		weights  Weights
+		want     []string
+	}{
+		{"Engine order without frecency", nil, DefaultWeights, []string{"Code", "Codec Manager", "Color Designer", "Barcode"}},
+		{"Frecency lifts a close match", map[*entry.Entry]float64{contains: 0.5}, DefaultWeights, []string{"Code", "Codec Manager", "Barcode", "Color Designer"}},
+		{"Weak favorite stays below exact match", map[*entry.Entry]float64{fuzzy: 0.99}, DefaultWeights, []string{"Code", "Codec Manager", "Color Designer", "Barcode"}},
+		{"Frecency only", map[*entry.Entry]float64{fuzzy: 0.9, contains: 0.5}, Weights{Frecency: 1}, []string{"Color Designer", "Barcode", "Code", "Codec Manager"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frecency := func(e *entry.Entry) float64 { return tt.frecency[e] }
+			var names []string
+			for _, r := range Rank(scored, Signals{Frecency: frecency}, tt.weights) {
+				names = append(names, r.Entry.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Rank() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	// A habit for the query typed overcomes match differences
+	adaptive := func(e *entry.Entry) float64 {
+		if e == contains {
+			return 0.75
+		}
+		return 0
+	}
+	if results := Rank(scored, Signals{Adaptive: adaptive}, DefaultWeights); results[0].Entry != contains {
+		t.Errorf("Rank() first = %s, want %s", results[0].Entry.Name, contains.Name)
+	}
+
+	// The breakdown explains the total
+	signals := Signals{
+		Frecency: func(e *entry.Entry) float64 { return 0.25 },
+		Adaptive: func(e *entry.Entry) float64 { return 0.5 },
+	}
+	results := Rank(scored, signals, Weights{Match: 2, Frecency: 100, Adaptive: 10})
+	want := Breakdown{MatchType: ContainsMatch, Match: 400, Relevance: 150, Frecency: 0.25, Adaptive: 0.5, Total: 330}
+	if got := results[3].Breakdown; got != want {
+		t.Errorf("Breakdown = %+v, want %+v", got, want)
+	}
+	if got := want.String(); got != "contains 400, relevance 150, frecency 0.25, adaptive 0.50, total 330.0" {
+		t.Errorf("Breakdown.String() = %q", got)
+	}
+}
+
+func TestIsFilterQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"played:<7d", true},
+		{"store:gog  played:>30d", true},
+		{"played:<7d portal", false},
+		{"played:", false},
+		{"playtime:>10x", false},
+		{"portal", false},
+		{"type:flatpak -steam", true},
+		{"cat:game | fav:", true},
+		{`"portal"`, true},
+		{"type:flatpk", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsFilterQuery(tt.query); got != tt.want {
+			t.Errorf("IsFilterQuery(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQueryText(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"fire", "fire"},
+		{"type:flatpak fire", "fire"},
+		{"played:<7d portal  type:native", "portal"},
+		{"played:<7d", ""},
+		{"type:flatpak -steam", ""},
+		{`"portal"`, ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := QueryText(tt.query); got != tt.want {
+			t.Errorf("QueryText(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
 func BenchmarkSearch(b *testing.B) {
 	entries := make([]*entry.Entry, 100)
 	for i := 0; i < 100; i++ {
@@ -419,3 +793,33 @@ func BenchmarkSearchFuzzy(b *testing.B) {
 		engine.Search("apc", entry.AppTypeAll, entries)
 	}
 }
+
+func BenchmarkSearchEntries(b *testing.B) {
+	words := []string{
+		"Visual", "Studio", "Code", "Firefox", "Web", "Browser", "Terminal",
+		"Emulator", "GNOME", "Settings", "Steam", "Proton", "Libre", "Office",
+		"Writer", "Calc", "Image", "Viewer", "Text", "Editor", "Disk", "Usage",
+	}
+
+	// About as many entries as a desktop with games installed has
+	entries := make([]*entry.Entry, 2000)
+	for i := range entries {
+		entries[i] = &entry.Entry{
+			Name:       words[i%len(words)] + " " + words[(i/len(words))%len(words)],
+			Comment:    words[(i*7)%len(words)] + " " + words[(i*11)%len(words)] + " " + words[(i*13)%len(words)],
+			Categories: []string{words[(i*3)%len(words)]},
+			Path:       fmt.Sprintf("/path/app%d.desktop", i),
+			Exec:       fmt.Sprintf("app%d", i),
+		}
+	}
+
+	engine := New(entries)
+
+	for _, query := range []string{"code", "vsc", "terminal emulator"} {
+		b.Run(query, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				engine.Search(query, entry.AppTypeAll, entries)
+			}
+		})
+	}
+}
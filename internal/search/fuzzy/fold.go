@@ -0,0 +1,88 @@
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldStart is the first rune of foldTable, à
+const foldStart = 0xE0
+
+// foldTable maps the lowercase letters of the Latin-1 Supplement and Latin
+// Extended-A blocks, from foldStart on, to their letter without diacritics.
+// Letters such as æ and ß, which stand for two letters, are kept as '.'.
+const foldTable = "" +
+	"aaaaaa.ceeeeiiii" + // U+00E0
+	"dnooooo.ouuuuy.y" + // U+00F0
+	"aaaaaaccccccccdd" + // U+0100
+	"ddeeeeeeeeeegggg" + // U+0110
+	"gggghhhhiiiiiiii" + // U+0120
+	"ii..jjkkklllllll" + // U+0130
+	"lllnnnnnnnnnoooo" + // U+0140
+	"oo..rrrrrrssssss" + // U+0150
+	"ssttttttuuuuuuuu" + // U+0160
+	"uuuuwwyyyzzzzzzs" //   U+0170
+
+// isCombiningMark reports whether r is a combining diacritical mark, as
+// found in decomposed text such as "Ärger". Folding drops them.
+func isCombiningMark(r rune) bool {
+	return r >= 0x300 && r <= 0x36F
+}
+
+// foldRune removes the diacritics of r, and its case unless keepCase
+func foldRune(r rune, keepCase bool) rune {
+	lower := unicode.ToLower(r)
+	if lower >= foldStart && lower < foldStart+rune(len(foldTable)) {
+		if base := foldTable[lower-foldStart]; base != '.' {
+			if keepCase && lower != r {
+				return unicode.ToUpper(rune(base))
+			}
+			return rune(base)
+		}
+	}
+	if keepCase {
+		return r
+	}
+	return lower
+}
+
+// Fold returns s in lowercase and without diacritics, as the matcher
+// compares text unless it is case-sensitive
+func Fold(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if !isCombiningMark(r) {
+			b.WriteRune(foldRune(r, false))
+		}
+	}
+	return b.String()
+}
+
+// foldedText is text prepared for matching, one element per rune kept
+type foldedText struct {
+	runes []rune // Folded runes, which are compared
+	orig  []rune // Original runes, which show word and camelCase boundaries
+	pos   []int  // Rune index in the original text
+}
+
+// fold prepares text for matching
+func fold(text string, keepCase bool) foldedText {
+	n := len(text) // Upper bound of the rune count
+	f := foldedText{
+		runes: make([]rune, 0, n),
+		orig:  make([]rune, 0, n),
+		pos:   make([]int, 0, n),
+	}
+
+	i := 0
+	for _, r := range text {
+		if !isCombiningMark(r) {
+			f.runes = append(f.runes, foldRune(r, keepCase))
+			f.orig = append(f.orig, r)
+			f.pos = append(f.pos, i)
+		}
+		i++
+	}
+	return f
+}
@@ -52,3 +52,43 @@ func min(a, b, c int) int {
 	}
 	return c
 }
+
+// DamerauLevenshteinDistance calculates the edit distance between two
+// strings by rune, counting a swap of adjacent runes as a single edit, as
+// in "fierfox" for "firefox"
+func DamerauLevenshteinDistance(s1, s2 string) int {
+	r1 := []rune(s1)
+	r2 := []rune(s2)
+
+	// Only the last three rows of the matrix are needed
+	prev2 := make([]int, len(r2)+1) // Row i-2
+	prev := make([]int, len(r2)+1)  // Row i-1
+	row := make([]int, len(r2)+1)   // Row i
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(r1); i++ {
+		row[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+
+			row[j] = min(
+				prev[j]+1,      // deletion
+				row[j-1]+1,     // insertion
+				prev[j-1]+cost, // substitution
+			)
+
+			// transposition
+			if i > 1 && j > 1 && r1[i-1] == r2[j-2] && r1[i-2] == r2[j-1] && prev2[j-2]+1 < row[j] {
+				row[j] = prev2[j-2] + 1
+			}
+		}
+		prev2, prev, row = prev, row, prev2
+	}
+
+	return prev[len(r2)]
+}
@@ -1,7 +1,7 @@
 package fuzzy
 
 import (
-	"strings"
+	"math"
 	"unicode"
 )
 
@@ -35,39 +35,51 @@ func New(opts ...Option) *Matcher {
 
 // Result represents a fuzzy match result
 type Result struct {
-	Text           string
-	Score          int
+	Text  string
+	Score int
+
+	// MatchedIndices are the rune indices in Text of the matched
+	// characters, in increasing order
 	MatchedIndices []int
 }
 
 // Match performs fuzzy matching and returns a result with score
-// Algorithm inspired by Sublime Text's fuzzy matching:
+// Algorithm inspired by Sublime Text's and fzf's fuzzy matching:
 // 1. Sequential character matching (all pattern chars must appear in order)
 // 2. Bonus for consecutive matches
 // 3. Bonus for camelCase/snake_case boundaries
 // 4. Bonus for start of word matches
 // 5. Penalty for gaps between matches
+// Of all the ways the pattern can be matched, the one scoring best is used.
+// Characters are compared without diacritics, so "arger" matches "Ärger".
 func (m *Matcher) Match(pattern, text string) *Result {
 	if pattern == "" {
 		return &Result{Text: text, Score: 0, MatchedIndices: []int{}}
 	}
 
-	// Normalize for case-insensitive matching
-	searchPattern := pattern
-	searchText := text
-	if !m.caseSensitive {
-		searchPattern = strings.ToLower(pattern)
-		searchText = strings.ToLower(text)
+	// Normalize case and diacritics
+	searchPattern := fold(pattern, m.caseSensitive).runes
+	if len(searchPattern) == 0 {
+		return &Result{Text: text, Score: 0, MatchedIndices: []int{}}
 	}
+	searchText := fold(text, m.caseSensitive)
 
-	// Check if all characters in pattern exist in text (in order)
-	matchedIndices := m.findMatches(searchPattern, searchText)
-	if matchedIndices == nil {
+	// Quickly reject texts missing a pattern character, which most are
+	if m.findMatches(searchPattern, searchText.runes) == nil {
 		return nil // No match
 	}
 
-	// Calculate score based on match quality
-	score := m.calculateScore(searchPattern, searchText, matchedIndices)
+	matchedIndices := m.findBestMatches(searchPattern, searchText.runes, searchText.orig)
+	if matchedIndices == nil {
+		return nil
+	}
+
+	// Calculate score based on match quality, then point the indices at
+	// the original text
+	score := m.calculateScore(searchPattern, searchText.orig, matchedIndices)
+	for i, idx := range matchedIndices {
+		matchedIndices[i] = searchText.pos[idx]
+	}
 
 	return &Result{
 		Text:           text,
@@ -76,8 +88,9 @@ func (m *Matcher) Match(pattern, text string) *Result {
 	}
 }
 
-// findMatches finds the indices of matched characters
-func (m *Matcher) findMatches(pattern, text string) []int {
+// findMatches finds the indices of the leftmost match of each pattern
+// character, or nil if they don't all appear in order
+func (m *Matcher) findMatches(pattern, text []rune) []int {
 	patternIdx := 0
 	textIdx := 0
 	matchedIndices := make([]int, 0, len(pattern))
@@ -98,8 +111,172 @@ func (m *Matcher) findMatches(pattern, text string) []int {
 	return matchedIndices
 }
 
+// noScore marks alignments that aren't possible
+const noScore = math.MinInt / 2
+
+// cell is a text position a pattern character can be matched at
+type cell struct {
+	pos int
+
+	// runs[k-1] is the best score of matching the pattern up to here with
+	// the last k characters matched consecutively, or noScore
+	runs []int
+
+	best    int // Best of runs
+	bestRun int // Run length of best
+
+	from  int // Previous row cell runs of length 1 follow, -1 if none
+	chain int // Previous row cell at pos-1 longer runs follow, -1 if none
+}
+
+// findBestMatches finds the indices of the alignment of pattern in text
+// that calculateScore rates highest, or nil if there is none. Leftmost
+// matching would take "o" from "Studio" for "ode" in "Visual Studio
+// Code", then pay for the gap it created.
+//
+// This is a Smith-Waterman style dynamic program over the positions each
+// pattern character occurs at. The consecutive bonus grows with the run a
+// character extends, so each cell keeps the best score for every run
+// length; runs are rarely longer than a few characters.
+func (m *Matcher) findBestMatches(pattern, text, boundaryText []rune) []int {
+	bonus := make([]int, len(text))
+	for j := range bonus {
+		bonus[j] = positionBonus(boundaryText, j)
+	}
+
+	rows := make([][]cell, len(pattern))
+	scores := make([]int, 0, 4*len(pattern)) // Backs the runs of all cells
+	at := make([]int, len(text))             // Previous row cell at each position, -1 if none
+	for j := range at {
+		at[j] = -1
+	}
+
+	for i := range pattern {
+		var prev []cell
+		start := 0
+		if i > 0 {
+			prev = rows[i-1]
+			start = prev[0].pos + 1
+			if i > 1 {
+				for _, c := range rows[i-2] {
+					at[c.pos] = -1
+				}
+			}
+			for ci, c := range prev {
+				at[c.pos] = ci
+			}
+		}
+
+		var row []cell
+		next := 0          // First previous row cell not yet considered for gaps
+		gapBest := noScore // Best previous score plus its position's gap credit
+		gapFrom := -1      // Cell gapBest comes from
+		for j := start; j < len(text); j++ {
+			if text[j] != pattern[i] {
+				continue
+			}
+
+			c := cell{pos: j, best: noScore, from: -1, chain: -1}
+			first := len(scores)
+			if i == 0 {
+				scores = append(scores, bonus[j])
+			} else {
+				// Cells ending before j-1 leave a gap, penalized per character
+				for next < len(prev) && prev[next].pos < j-1 {
+					if v := prev[next].best + 2*prev[next].pos; v > gapBest {
+						gapBest, gapFrom = v, next
+					}
+					next++
+				}
+
+				if gapFrom >= 0 {
+					scores = append(scores, gapBest-2*(j-1)+bonus[j])
+					c.from = gapFrom
+				} else {
+					scores = append(scores, noScore)
+				}
+
+				// The cell at j-1 continues its runs
+				if c.chain = at[j-1]; c.chain >= 0 {
+					for k, score := range prev[c.chain].runs {
+						run := k + 2
+						if score == noScore {
+							scores = append(scores, noScore)
+							continue
+						}
+						scores = append(scores, score+bonus[j]+15+5*(run-1))
+					}
+				}
+			}
+			// Earlier cells keep the old array if appending grows it
+			c.runs = scores[first:len(scores):len(scores)]
+
+			// Prefer longer runs on ties
+			for k, score := range c.runs {
+				if score >= c.best && score != noScore {
+					c.best, c.bestRun = score, k+1
+				}
+			}
+			if c.best == noScore {
+				continue // Can't be reached
+			}
+			row = append(row, c)
+		}
+
+		if len(row) == 0 {
+			return nil
+		}
+		rows[i] = row
+	}
+
+	// Walk back from the best cell of the last row
+	last := rows[len(pattern)-1]
+	ci := 0
+	for k, c := range last {
+		if c.best > last[ci].best {
+			ci = k
+		}
+	}
+	run := last[ci].bestRun
+
+	indices := make([]int, len(pattern))
+	for i := len(pattern) - 1; i >= 0; i-- {
+		c := rows[i][ci]
+		indices[i] = c.pos
+		if run > 1 {
+			ci, run = c.chain, run-1
+		} else if i > 0 {
+			ci = c.from
+			run = rows[i-1][ci].bestRun
+		}
+	}
+
+	return indices
+}
+
+// positionBonus scores matching the character at idx: at the start of the
+// text, or at a word, camelCase or number boundary
+func positionBonus(text []rune, idx int) int {
+	if idx == 0 {
+		return 15
+	}
+
+	prevChar := text[idx-1]
+	currChar := text[idx]
+
+	// Word boundary bonuses
+	if isSeparator(prevChar) {
+		return 20 // After space, dash, underscore, etc.
+	} else if isLower(prevChar) && isUpper(currChar) {
+		return 15 // CamelCase boundary
+	} else if unicode.IsDigit(prevChar) && !unicode.IsDigit(currChar) {
+		return 10 // Number to letter transition
+	}
+	return 0
+}
+
 // calculateScore computes match quality score
-func (m *Matcher) calculateScore(pattern, text string, matchedIndices []int) int {
+func (m *Matcher) calculateScore(pattern, text []rune, matchedIndices []int) int {
 	if len(matchedIndices) == 0 {
 		return 0
 	}
@@ -111,10 +288,8 @@ func (m *Matcher) calculateScore(pattern, text string, matchedIndices []int) int
 	score += len(pattern) * 10
 
 	for i, idx := range matchedIndices {
-		// Bonus for match at start
-		if idx == 0 {
-			score += 15
-		}
+		// Bonus for match at start or after a boundary
+		score += positionBonus(text, idx)
 
 		// Bonus for consecutive characters
 		if i > 0 && matchedIndices[i-1] == idx-1 {
@@ -124,21 +299,6 @@ func (m *Matcher) calculateScore(pattern, text string, matchedIndices []int) int
 			consecutive = 0
 		}
 
-		// Bonus for matching after separator or boundary
-		if idx > 0 {
-			prevChar := rune(text[idx-1])
-			currChar := rune(text[idx])
-
-			// Word boundary bonuses
-			if isSeparator(prevChar) {
-				score += 20 // After space, dash, underscore, etc.
-			} else if isLower(prevChar) && isUpper(currChar) {
-				score += 15 // CamelCase boundary
-			} else if unicode.IsDigit(prevChar) && !unicode.IsDigit(currChar) {
-				score += 10 // Number to letter transition
-			}
-		}
-
 		// Penalty for gaps
 		if i > 0 {
 			gap := idx - matchedIndices[i-1] - 1
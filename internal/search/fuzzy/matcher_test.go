@@ -1,6 +1,9 @@
 package fuzzy
 
 import (
+	"math/rand"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -121,6 +124,72 @@ func TestMatch(t *testing.T) {
 	}
 }
 
+func TestMatchUnicode(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		indices []int // Rune indices, nil for no match
+	}{
+		{"Umlaut", "arger", "Ärger", []int{0, 1, 2, 3, 4}},
+		{"Decomposed umlaut", "arger", "A\u0308rger", []int{0, 2, 3, 4, 5}},
+		{"Accented pattern", "Ärg", "Mensch ärgere dich nicht", []int{7, 8, 9}},
+		{"Accent after multibyte text", "cafe", "Crème Café", []int{6, 7, 8, 9}},
+		{"Latin Extended-A", "lodz", "Łódź", []int{0, 1, 2, 3}},
+		{"CJK", "设置", "系统设置", []int{2, 3}},
+		{"Katakana", "マネ", "ファイルマネージャー", []int{4, 5}},
+		{"Hangul no match", "설정", "시스템", nil},
+		{"After emoji", "steam", "🎮 Steam", []int{2, 3, 4, 5, 6}},
+		{"Emoji", "🎮", "Games 🎮", []int{6}},
+		{"Multi-rune emoji", "chat", "👩‍💻 Chat", []int{4, 5, 6, 7}},
+	}
+
+	m := New()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := m.Match(tt.pattern, tt.text)
+			if tt.indices == nil {
+				if result != nil {
+					t.Errorf("Match(%q, %q) = %+v, want nil", tt.pattern, tt.text, result)
+				}
+				return
+			}
+			if result == nil {
+				t.Fatalf("Match(%q, %q) = nil, want a match", tt.pattern, tt.text)
+			}
+			if !reflect.DeepEqual(result.MatchedIndices, tt.indices) {
+				t.Errorf("Match(%q, %q) indices = %v, want %v", tt.pattern, tt.text, result.MatchedIndices, tt.indices)
+			}
+		})
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Ärger", "arger"},
+		{"A\u0308rger", "arger"},
+		{"Crème Brûlée", "creme brulee"},
+		{"ŁÓDŹ", "lodz"},
+		{"Straße", "straße"},
+		{"系统设置", "系统设置"},
+		{"🎮 Steam", "🎮 steam"},
+	}
+
+	for _, tt := range tests {
+		if result := Fold(tt.input); result != tt.expected {
+			t.Errorf("Fold(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+
+	if len(foldTable) != 0x180-foldStart {
+		t.Errorf("foldTable has %d letters, want %d", len(foldTable), 0x180-foldStart)
+	}
+}
+
 func TestMatchCaseSensitive(t *testing.T) {
 	m := New(WithCaseSensitive(true))
 
@@ -133,6 +202,14 @@ func TestMatchCaseSensitive(t *testing.T) {
 	if result == nil {
 		t.Error("Case-sensitive match should succeed for matching cases")
 	}
+
+	// Diacritics are still ignored
+	if m.Match("Arger", "Ärger") == nil {
+		t.Error("Case-sensitive match should ignore diacritics")
+	}
+	if m.Match("arger", "Ärger") != nil {
+		t.Error("Case-sensitive match should fail for different cases of accented letters")
+	}
 }
 
 func TestFindMatches(t *testing.T) {
@@ -172,7 +249,7 @@ func TestFindMatches(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := m.findMatches(tt.pattern, tt.text)
+			result := m.findMatches([]rune(tt.pattern), []rune(tt.text))
 
 			if tt.expected == nil {
 				if result != nil {
@@ -195,6 +272,108 @@ func TestFindMatches(t *testing.T) {
 	}
 }
 
+func TestFindBestMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		text     string
+		expected []int
+	}{
+		{
+			name:     "Contiguous word over scattered letters",
+			pattern:  "ode",
+			text:     "Visual Studio Code",
+			expected: []int{15, 16, 17},
+		},
+		{
+			name:     "Word starts",
+			pattern:  "vsc",
+			text:     "Visual Studio Code",
+			expected: []int{0, 7, 14},
+		},
+		{
+			name:     "Later consecutive run",
+			pattern:  "term",
+			text:     "The Remote Terminal",
+			expected: []int{11, 12, 13, 14},
+		},
+		{
+			name:     "Closest camelCase boundaries",
+			pattern:  "fb",
+			text:     "FileFooBar",
+			expected: []int{4, 7},
+		},
+		{
+			name:     "No match",
+			pattern:  "xyz",
+			text:     "abc",
+			expected: nil,
+		},
+	}
+
+	m := New()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := m.findBestMatches([]rune(strings.ToLower(tt.pattern)), []rune(strings.ToLower(tt.text)), []rune(tt.text))
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("findBestMatches() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFindBestMatchesOptimal checks the alignment found against every
+// possible alignment of short random strings
+func TestFindBestMatchesOptimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomText := func(n int) string {
+		const alphabet = "aab-B_c1"
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return string(b)
+	}
+
+	// bestScore tries every way of matching pattern[i:] after text[:from]
+	var bestScore func(pattern, text string, from int, indices []int) (int, bool)
+	bestScore = func(pattern, text string, from int, indices []int) (int, bool) {
+		i := len(indices)
+		if i == len(pattern) {
+			return New().calculateScore([]rune(pattern), []rune(text), indices), true
+		}
+		best, found := 0, false
+		for j := from; j < len(text); j++ {
+			if text[j] != pattern[i] {
+				continue
+			}
+			if score, ok := bestScore(pattern, text, j+1, append(indices, j)); ok && (!found || score > best) {
+				best, found = score, true
+			}
+		}
+		return best, found
+	}
+
+	m := New(WithCaseSensitive(true))
+	for n := 0; n < 2000; n++ {
+		pattern := randomText(1 + rng.Intn(4))
+		text := randomText(rng.Intn(14))
+
+		want, ok := bestScore(pattern, text, 0, nil)
+		indices := m.findBestMatches([]rune(pattern), []rune(text), []rune(text))
+		if !ok {
+			if indices != nil {
+				t.Fatalf("findBestMatches(%q, %q) = %v, want nil", pattern, text, indices)
+			}
+			continue
+		}
+		if got := m.calculateScore([]rune(pattern), []rune(text), indices); got != want {
+			t.Fatalf("findBestMatches(%q, %q) = %v scoring %d, want score %d", pattern, text, indices, got, want)
+		}
+	}
+}
+
 func TestCalculateScore(t *testing.T) {
 	m := New()
 
@@ -230,7 +409,7 @@ func TestCalculateScore(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			score := m.calculateScore(tt.pattern, tt.text, tt.indices)
+			score := m.calculateScore([]rune(tt.pattern), []rune(tt.text), tt.indices)
 			if score < tt.minScore {
 				t.Errorf("calculateScore() = %v, want >= %v", score, tt.minScore)
 			}
@@ -287,6 +466,35 @@ func TestLevenshteinDistance(t *testing.T) {
 	}
 }
 
+func TestDamerauLevenshteinDistance(t *testing.T) {
+	tests := []struct {
+		name     string
+		s1       string
+		s2       string
+		expected int
+	}{
+		{"Identical strings", "firefox", "firefox", 0},
+		{"Transposition", "fierfox", "firefox", 1},
+		{"Deletion", "thundebird", "thunderbird", 1},
+		{"Transposition and substitution", "tihnderbord", "thunderbird", 3},
+		{"Classic example", "kitten", "sitting", 3},
+		{"Transposed ends", "ab", "ba", 1},
+		{"Not adjacent", "abc", "cba", 2},
+		{"Runes", "größe", "gröse", 1},
+		{"Empty strings", "", "", 0},
+		{"One empty", "", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DamerauLevenshteinDistance(tt.s1, tt.s2)
+			if result != tt.expected {
+				t.Errorf("DamerauLevenshteinDistance(%q, %q) = %v, want %v", tt.s1, tt.s2, result, tt.expected)
+			}
+		})
+	}
+}
+
 func BenchmarkMatch(b *testing.B) {
 	m := New()
 	pattern := "fire"
@@ -298,6 +506,43 @@ func BenchmarkMatch(b *testing.B) {
 	}
 }
 
+// benchmarkTexts returns n application names and descriptions, about as
+// many as a desktop with games installed has
+func benchmarkTexts(n int) []string {
+	words := []string{
+		"Visual", "Studio", "Code", "Firefox", "Web", "Browser", "Terminal",
+		"Emulator", "GNOME", "Settings", "Steam", "Proton", "Libre", "Office",
+		"Writer", "Calc", "Image", "Viewer", "Text", "Editor", "Disk", "Usage",
+		"Analyzer", "System", "Monitor", "Network", "Manager", "Music", "Player",
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	texts := make([]string, n)
+	for i := range texts {
+		parts := make([]string, 2+rng.Intn(6))
+		for j := range parts {
+			parts[j] = words[rng.Intn(len(words))]
+		}
+		texts[i] = strings.Join(parts, " ")
+	}
+	return texts
+}
+
+func BenchmarkMatchEntries(b *testing.B) {
+	m := New()
+	texts := benchmarkTexts(2000)
+
+	for _, pattern := range []string{"code", "vsc", "terminal emulator", "xyz"} {
+		b.Run(pattern, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, text := range texts {
+					m.Match(pattern, text)
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkLevenshteinDistance(b *testing.B) {
 	s1 := "kitten"
 	s2 := "sitting"
@@ -5,6 +5,7 @@ import (
 	"unicode"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/search/fuzzy"
 )
 
 // Index pre-processes entry data for faster searching
@@ -14,6 +15,8 @@ type Index struct {
 	NameNormalized string
 	CommentTokens  []string
 	CategoryTokens []string
+	Keywords       []string // Folded Keywords of the desktop entry
+	Actions        []*Index // Indices of the entry's desktop actions
 }
 
 // Indexer manages search indices for entries
@@ -35,15 +38,29 @@ func (idx *Indexer) Build(entries []*entry.Entry) {
 	}
 }
 
-// Add creates an index for a single entry
+// Add creates an index for a single entry and its desktop actions
 func (idx *Indexer) Add(e *entry.Entry) {
+	index := newIndex(e)
+	for _, action := range e.ActionEntries() {
+		index.Actions = append(index.Actions, newIndex(action))
+	}
+
+	idx.indices[e.Path] = index
+}
+
+// newIndex pre-processes a single entry
+func newIndex(e *entry.Entry) *Index {
 	index := &Index{
 		Entry:          e,
-		NameNormalized: strings.ToLower(e.Name),
+		NameNormalized: fuzzy.Fold(e.Name),
 		CommentTokens:  tokenize(e.Comment),
 		CategoryTokens: e.Categories,
 	}
 
+	for _, keyword := range e.Keywords {
+		index.Keywords = append(index.Keywords, fuzzy.Fold(keyword))
+	}
+
 	// Build searchable text (name + generic name + first 10 words of comment)
 	parts := []string{e.Name}
 	if e.GenericName != "" {
@@ -58,7 +75,7 @@ func (idx *Indexer) Add(e *entry.Entry) {
 	}
 	index.SearchableText = strings.Join(parts, " ")
 
-	idx.indices[e.Path] = index
+	return index
 }
 
 // Get retrieves the index for an entry
@@ -92,7 +109,7 @@ func (idx *Indexer) Count() int {
 
 // tokenize splits text into searchable tokens
 func tokenize(text string) []string {
-	text = strings.ToLower(text)
+	text = fuzzy.Fold(text)
 	tokens := make([]string, 0)
 
 	var current strings.Builder
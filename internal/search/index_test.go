@@ -55,6 +55,37 @@ func TestIndexerAdd(t *testing.T) {
 	}
 }
 
+func TestIndexerAddActions(t *testing.T) {
+	indexer := NewIndexer()
+	e := &entry.Entry{
+		Name: "Firefox",
+		Path: "/usr/share/applications/firefox.desktop",
+		Actions: []entry.Action{
+			{ID: "new-private-window", Name: "New Private Window", Exec: "firefox --private-window"},
+		},
+	}
+
+	indexer.Add(e)
+
+	if indexer.Count() != 1 {
+		t.Errorf("Count() = %d, want 1", indexer.Count())
+	}
+
+	index := indexer.Get(e.Path)
+	if len(index.Actions) != 1 {
+		t.Fatalf("Actions length = %d, want 1", len(index.Actions))
+	}
+
+	action := index.Actions[0]
+	if action.NameNormalized != "firefox › new private window" {
+		t.Errorf("Action NameNormalized = %q, want %q", action.NameNormalized, "firefox › new private window")
+	}
+
+	if action.Entry.Parent != e {
+		t.Error("Action entry should point to its application")
+	}
+}
+
 func TestIndexerBuild(t *testing.T) {
 	entries := []*entry.Entry{
 		{Name: "Firefox", Path: "/path/firefox.desktop"},
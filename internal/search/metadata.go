@@ -0,0 +1,102 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/search/query"
+)
+
+// Metadata fields a query can filter on, written as field:value
+const (
+	fieldPlayed   = "played"   // played:<7d, last played less than 7 days ago
+	fieldPlaytime = "playtime" // playtime:>10h, played for more than 10 hours
+	fieldStore    = "store"    // store:steam
+	fieldRunner   = "runner"   // runner:wine
+)
+
+// spanUnits are the units of the time spans filters compare against
+var spanUnits = map[byte]time.Duration{
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+	'y': 365 * 24 * time.Hour,
+}
+
+// metadataPredicate returns the predicate of a filter on metadata, such as
+// played:<7d, comparing times against now. Entries without the metadata,
+// such as applications, never match.
+func metadataPredicate(field, value string, now time.Time) (predicate, error) {
+	value = strings.ToLower(value)
+
+	switch field {
+	case fieldStore, fieldRunner:
+		if value == "" {
+			return nil, fmt.Errorf("%w: %s: needs a name", query.ErrInvalidFilter, field)
+		}
+		key := entry.MetaStore
+		if field == fieldRunner {
+			key = entry.MetaRunner
+		}
+		return func(e *entry.Entry) bool {
+			return strings.EqualFold(e.Meta(key), value)
+		}, nil
+
+	case fieldPlayed, fieldPlaytime:
+		// played:7d means within the last 7 days, playtime:10h at least 10 hours
+		less := field == fieldPlayed
+		span := value
+		if span != "" {
+			switch span[0] {
+			case '<':
+				less, span = true, span[1:]
+			case '>':
+				less, span = false, span[1:]
+			}
+		}
+
+		defaultUnit := byte('h')
+		if field == fieldPlayed {
+			defaultUnit = 'd'
+		}
+		d, ok := parseSpan(span, defaultUnit)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: %q is not a time span such as <7d", query.ErrInvalidFilter, field, value)
+		}
+
+		if field == fieldPlayed {
+			return func(e *entry.Entry) bool {
+				lastPlayed := e.LastPlayed()
+				return !lastPlayed.IsZero() && (now.Sub(lastPlayed) < d) == less
+			}, nil
+		}
+		return func(e *entry.Entry) bool {
+			return e.Meta(entry.MetaPlaytime) != "" && (e.Playtime() < d) == less
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown field %s", query.ErrInvalidFilter, field)
+}
+
+// parseSpan parses a time span such as 7d or 90m. Bare numbers are in
+// defaultUnit.
+func parseSpan(s string, defaultUnit byte) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+
+	unit := spanUnits[defaultUnit]
+	if u, ok := spanUnits[s[len(s)-1]]; ok {
+		unit, s = u, s[:len(s)-1]
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,276 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+	"github.com/antoniosarro/gofi/internal/search/fuzzy"
+	"github.com/antoniosarro/gofi/internal/search/query"
+)
+
+// Entry fields a query can filter on, written as field:value
+const (
+	fieldType     = "type" // type:flatpak
+	fieldCategory = "cat"  // cat:graphics, a category containing graphics
+	fieldTerminal = "term" // term:true, run in a terminal
+	fieldFavorite = "fav"  // fav:, favorites only
+)
+
+// filterFields are all fields a query can filter on
+var filterFields = []string{
+	fieldType, fieldCategory, fieldTerminal, fieldFavorite,
+	fieldPlayed, fieldPlaytime, fieldStore, fieldRunner,
+}
+
+// appTypes are the values of type:, by lowercase name
+var appTypes = map[string]entry.AppType{
+	"system":   entry.AppTypeSystem,
+	"nix-sys":  entry.AppTypeNixSystem,
+	"nix-home": entry.AppTypeNixHome,
+	"flatpak":  entry.AppTypeFlatpak,
+	"games":    entry.AppTypeGame,
+	"game":     entry.AppTypeGame,
+	"wine":     entry.AppTypeWine,
+	"other":    entry.AppTypeOther,
+}
+
+// predicate reports whether an entry satisfies a filter
+type predicate func(e *entry.Entry) bool
+
+// compiledQuery is a parsed query ready to match entries
+type compiledQuery struct {
+	root    query.Node
+	filters map[*query.Filter]predicate
+	texts   map[*query.Text]textQuery
+	scored  bool // Whether the query has text to fuzzy match
+}
+
+// textQuery is text of a query prepared for scoring
+type textQuery struct {
+	lower  string // Folded, as indexed
+	tokens []string
+}
+
+// queryMatch is how an entry matches a query
+type queryMatch struct {
+	ok        bool
+	scored    bool // Matched text, rather than only filters
+	score     int
+	matchType MatchType
+}
+
+// ParseQuery parses a search query, returning a *query.SyntaxError for
+// mistakes such as type:flatpk. An empty query parses to nil.
+func ParseQuery(q string) (query.Node, error) {
+	cq, err := compileQuery(q, time.Now(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return cq.root, nil
+}
+
+// IsFilterQuery reports whether a query only filters entries, such as
+// played:<7d, without text to match
+func IsFilterQuery(q string) bool {
+	cq, err := compileQuery(q, time.Now(), nil)
+	return err == nil && cq.root != nil && !cq.scored
+}
+
+// QueryText returns the text a query matches, leaving out filters such as
+// type:flatpak, so that "type:flatpak fire" and "fire" are the same search
+// to learn from. A filter-only query returns "", and one that doesn't parse
+// is all text.
+func QueryText(q string) string {
+	root, err := query.Parse(q, filterFields...)
+	if err != nil {
+		return strings.TrimSpace(q)
+	}
+	return query.FuzzyText(root)
+}
+
+// compileQuery parses a query and prepares its filters and text. Times are
+// compared against now, and isFavorite tells favorites apart for fav:.
+func compileQuery(q string, now time.Time, isFavorite func(*entry.Entry) bool) (*compiledQuery, error) {
+	root, err := query.Parse(q, filterFields...)
+	if err != nil {
+		return nil, err
+	}
+
+	cq := &compiledQuery{
+		root:    root,
+		filters: make(map[*query.Filter]predicate),
+		texts:   make(map[*query.Text]textQuery),
+		scored:  query.FuzzyText(root) != "",
+	}
+
+	var prepare func(n query.Node) error
+	prepare = func(n query.Node) error {
+		switch n := n.(type) {
+		case *query.Text:
+			cq.texts[n] = textQuery{lower: fuzzy.Fold(n.Value), tokens: tokenize(n.Value)}
+		case *query.Filter:
+			pred, err := filterPredicate(n, now, isFavorite)
+			if err != nil {
+				return &query.SyntaxError{Query: q, Pos: n.Pos, Err: err}
+			}
+			cq.filters[n] = pred
+		case *query.Not:
+			return prepare(n.Term)
+		case *query.And:
+			for _, term := range n.Terms {
+				if err := prepare(term); err != nil {
+					return err
+				}
+			}
+		case *query.Or:
+			for _, term := range n.Terms {
+				if err := prepare(term); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := prepare(root); err != nil {
+		return nil, err
+	}
+	return cq, nil
+}
+
+// textOnly returns a query searching q as text, for queries that don't parse
+func textOnly(q string) *compiledQuery {
+	text := &query.Text{Value: strings.TrimSpace(q)}
+	return &compiledQuery{
+		root:   text,
+		texts:  map[*query.Text]textQuery{text: {lower: fuzzy.Fold(text.Value), tokens: tokenize(text.Value)}},
+		scored: true,
+	}
+}
+
+// filterPredicate returns the predicate of a filter
+func filterPredicate(f *query.Filter, now time.Time, isFavorite func(*entry.Entry) bool) (predicate, error) {
+	switch f.Field {
+	case fieldType:
+		appType, ok := appTypes[strings.ToLower(f.Value)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown type %q, want system, nix-sys, nix-home, flatpak, games, wine or other", query.ErrInvalidFilter, f.Value)
+		}
+		return func(e *entry.Entry) bool {
+			return e.GetAppType() == appType
+		}, nil
+
+	case fieldCategory:
+		if f.Value == "" {
+			return nil, fmt.Errorf("%w: cat: needs a category", query.ErrInvalidFilter)
+		}
+		category := fuzzy.Fold(f.Value)
+		return func(e *entry.Entry) bool {
+			for _, cat := range e.Categories {
+				if strings.Contains(fuzzy.Fold(cat), category) {
+					return true
+				}
+			}
+			return false
+		}, nil
+
+	case fieldTerminal, fieldFavorite:
+		// term: and fav: alone mean term:true and fav:true
+		want := true
+		if f.Value != "" {
+			var err error
+			if want, err = strconv.ParseBool(f.Value); err != nil {
+				return nil, fmt.Errorf("%w: %s: %q is neither true nor false", query.ErrInvalidFilter, f.Field, f.Value)
+			}
+		}
+		if f.Field == fieldTerminal {
+			return func(e *entry.Entry) bool {
+				return e.Terminal == want
+			}, nil
+		}
+		return func(e *entry.Entry) bool {
+			return (isFavorite != nil && isFavorite(e)) == want
+		}, nil
+	}
+
+	return metadataPredicate(f.Field, f.Value, now)
+}
+
+// match evaluates a query node. Filters apply to ent, text to index, which
+// is the index of ent or of one of its actions.
+func (e *Engine) match(n query.Node, cq *compiledQuery, ent *entry.Entry, index *Index) queryMatch {
+	switch n := n.(type) {
+	case *query.Text:
+		text := cq.texts[n]
+		score, matchType := e.scoreEntry(n.Value, text.lower, text.tokens, index)
+		return queryMatch{ok: score >= MinimumScore, scored: true, score: score, matchType: matchType}
+
+	case *query.Phrase:
+		return queryMatch{ok: containsPhrase(index, fuzzy.Fold(n.Value))}
+
+	case *query.Filter:
+		return queryMatch{ok: cq.filters[n](ent)}
+
+	case *query.Not:
+		// Excluded words are matched as written, fuzzy matching them
+		// would exclude far more than meant
+		if text, ok := n.Term.(*query.Text); ok {
+			return queryMatch{ok: !containsPhrase(index, cq.texts[text].lower)}
+		}
+		return queryMatch{ok: !e.match(n.Term, cq, ent, index).ok}
+
+	case *query.And:
+		result := queryMatch{ok: true}
+		for _, term := range n.Terms {
+			m := e.match(term, cq, ent, index)
+			if !m.ok {
+				return queryMatch{}
+			}
+			if m.scored {
+				result = m
+			}
+		}
+		return result
+
+	case *query.Or:
+		var best queryMatch
+		for _, term := range n.Terms {
+			if m := e.match(term, cq, ent, index); m.ok && (!best.ok || m.better(best)) {
+				best = m
+			}
+		}
+		return best
+	}
+
+	return queryMatch{}
+}
+
+// better reports whether m ranks above other
+func (m queryMatch) better(other queryMatch) bool {
+	if m.scored != other.scored {
+		return m.scored
+	}
+	if m.matchType != other.matchType {
+		return m.matchType < other.matchType
+	}
+	return m.score > other.score
+}
+
+// containsPhrase reports whether the name, generic name, comment or a
+// keyword of an entry contains folded text
+func containsPhrase(index *Index, text string) bool {
+	if strings.Contains(index.NameNormalized, text) ||
+		strings.Contains(fuzzy.Fold(index.Entry.GenericName), text) ||
+		strings.Contains(fuzzy.Fold(index.Entry.Comment), text) {
+		return true
+	}
+	for _, keyword := range index.Keywords {
+		if strings.Contains(keyword, text) {
+			return true
+		}
+	}
+	return false
+}
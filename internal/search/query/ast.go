@@ -0,0 +1,154 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Node is a node of a parsed query: *Text, *Phrase, *Filter, *Not, *And
+// or *Or
+type Node interface {
+	// String returns the node in query syntax
+	String() string
+}
+
+// Text is fuzzy matched text. The words of a query are kept together as
+// a single Text, so "visual studio" matches as typed.
+type Text struct {
+	Value string
+	Pos   int
+}
+
+// Phrase is quoted text, matched exactly
+type Phrase struct {
+	Value string
+	Pos   int
+}
+
+// Filter restricts results by a field, such as type:flatpak. Value is
+// empty for filters written without one, such as fav:.
+type Filter struct {
+	Field string // Lowercase field name
+	Value string
+	Pos   int
+}
+
+// Not excludes what its term matches, as in -steam
+type Not struct {
+	Term Node
+	Pos  int
+}
+
+// And matches what all of its terms match
+type And struct {
+	Terms []Node
+}
+
+// Or matches what any of its terms match, as in firefox | chromium
+type Or struct {
+	Terms []Node
+}
+
+// String returns the text
+func (t *Text) String() string {
+	return t.Value
+}
+
+// String returns the quoted phrase
+func (p *Phrase) String() string {
+	return strconv.Quote(p.Value)
+}
+
+// String returns field:value, quoting values with spaces
+func (f *Filter) String() string {
+	if strings.ContainsAny(f.Value, " \t|") {
+		return f.Field + ":" + strconv.Quote(f.Value)
+	}
+	return f.Field + ":" + f.Value
+}
+
+// String returns the term preceded by -
+func (n *Not) String() string {
+	return "-" + n.Term.String()
+}
+
+// String returns the terms separated by spaces
+func (a *And) String() string {
+	return joinNodes(a.Terms, " ")
+}
+
+// String returns the terms separated by |
+func (o *Or) String() string {
+	return joinNodes(o.Terms, " | ")
+}
+
+// joinNodes returns nodes in query syntax joined by sep
+func joinNodes(nodes []Node, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = n.String()
+	}
+	return strings.Join(parts, sep)
+}
+
+// Match evaluates a query, leaving *Text, *Phrase and *Filter nodes to
+// match. An empty query matches everything.
+func Match(n Node, match func(Node) bool) bool {
+	switch n := n.(type) {
+	case nil:
+		return true
+	case *Not:
+		return !Match(n.Term, match)
+	case *And:
+		for _, term := range n.Terms {
+			if !Match(term, match) {
+				return false
+			}
+		}
+		return true
+	case *Or:
+		for _, term := range n.Terms {
+			if Match(term, match) {
+				return true
+			}
+		}
+		return false
+	default:
+		return match(n)
+	}
+}
+
+// FuzzyText returns the text a query fuzzy matches, without phrases, filters
+// or excluded terms. Alternatives are separated by spaces.
+func FuzzyText(n Node) string {
+	return strings.Join(collect(n, false), " ")
+}
+
+// Terms returns the text and phrases a query looks for, leaving out
+// excluded terms, for instance to highlight them
+func Terms(n Node) []string {
+	return collect(n, true)
+}
+
+// collect returns the values of the Text nodes, and of the Phrase nodes
+// if phrases is set, that aren't excluded
+func collect(n Node, phrases bool) []string {
+	var values []string
+	switch n := n.(type) {
+	case *Text:
+		values = append(values, n.Value)
+	case *Phrase:
+		if phrases {
+			values = append(values, n.Value)
+		}
+	case *And:
+		for _, term := range n.Terms {
+			values = append(values, collect(term, phrases)...)
+		}
+	case *Or:
+		for _, term := range n.Terms {
+			values = append(values, collect(term, phrases)...)
+		}
+	}
+	return values
+}
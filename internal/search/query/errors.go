@@ -0,0 +1,42 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnterminatedQuote indicates a phrase lacks its closing quote
+	ErrUnterminatedQuote = errors.New("query: unterminated quote")
+
+	// ErrMissingTerm indicates a - or | lacks the term it applies to
+	ErrMissingTerm = errors.New("query: missing term")
+
+	// ErrInvalidFilter indicates a filter value its field doesn't accept
+	ErrInvalidFilter = errors.New("query: invalid filter")
+)
+
+// SyntaxError describes where a query is wrong and why.
+// It unwraps to the underlying error.
+type SyntaxError struct {
+	Query string
+	Pos   int // Byte offset of the mistake in Query
+	Err   error
+}
+
+// Error implements the error interface
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("column %d: %v", e.Column(), e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Column returns the 1-based character column of the mistake
+func (e *SyntaxError) Column() int {
+	pos := min(max(e.Pos, 0), len(e.Query))
+	return utf8.RuneCountInString(e.Query[:pos]) + 1
+}
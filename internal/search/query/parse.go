@@ -0,0 +1,200 @@
+// Package query parses the search syntax shared by the modules:
+//
+//	firefox             fuzzy matched text
+//	"web browser"       exact phrase
+//	type:flatpak        filter on a field, cat:"audio video" quotes spaces
+//	-steam              excludes a term, phrase or filter
+//	firefox | chromium  either side
+//
+// Terms next to each other must all match; | binds loosest. Which fields
+// exist, and what their values mean, is up to the module searching.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parser reads a query one term at a time
+type parser struct {
+	query  string
+	pos    int
+	fields map[string]bool
+}
+
+// Parse parses a query whose filters may use the given fields. Words with
+// a colon that don't start with one of them, such as Re:Zero, are text.
+// An empty query parses to nil.
+func Parse(query string, fields ...string) (Node, error) {
+	p := &parser{
+		query:  query,
+		fields: make(map[string]bool, len(fields)),
+	}
+	for _, field := range fields {
+		p.fields[strings.ToLower(field)] = true
+	}
+
+	var alternatives []Node
+	var terms []Node
+	pipe := -1 // Position of the last |
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.query) {
+			break
+		}
+
+		if p.query[p.pos] == '|' {
+			if len(terms) == 0 {
+				return nil, p.errorf(p.pos, "%w: | needs a term on both sides", ErrMissingTerm)
+			}
+			alternatives = append(alternatives, group(terms))
+			terms = nil
+			pipe = p.pos
+			p.pos++
+			continue
+		}
+
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		if term != nil {
+			terms = append(terms, term)
+		}
+	}
+
+	if len(terms) == 0 {
+		if pipe >= 0 {
+			return nil, p.errorf(pipe, "%w: | needs a term on both sides", ErrMissingTerm)
+		}
+		return nil, nil
+	}
+	alternatives = append(alternatives, group(terms))
+
+	if len(alternatives) == 1 {
+		return alternatives[0], nil
+	}
+	return &Or{Terms: alternatives}, nil
+}
+
+// parseTerm parses a term, possibly excluded by a leading -. Empty
+// phrases parse to nil.
+func (p *parser) parseTerm() (Node, error) {
+	start := p.pos
+	if p.query[p.pos] == '-' {
+		p.pos++
+		if p.atTermEnd() {
+			return nil, p.errorf(start, "%w: nothing to exclude after -", ErrMissingTerm)
+		}
+
+		term, err := p.parseOperand()
+		if err != nil || term == nil {
+			return nil, err
+		}
+		return &Not{Term: term, Pos: start}, nil
+	}
+	return p.parseOperand()
+}
+
+// parseOperand parses a phrase, filter or word
+func (p *parser) parseOperand() (Node, error) {
+	start := p.pos
+	if p.query[p.pos] == '"' {
+		value, err := p.parseQuoted()
+		if err != nil || value == "" {
+			return nil, err
+		}
+		return &Phrase{Value: value, Pos: start}, nil
+	}
+
+	word := p.parseWord()
+	if field, value, ok := strings.Cut(word, ":"); ok && p.fields[strings.ToLower(field)] {
+		// Values may be quoted to contain spaces
+		if value == "" && p.pos < len(p.query) && p.query[p.pos] == '"' {
+			var err error
+			if value, err = p.parseQuoted(); err != nil {
+				return nil, err
+			}
+		}
+		return &Filter{Field: strings.ToLower(field), Value: value, Pos: start}, nil
+	}
+
+	return &Text{Value: word, Pos: start}, nil
+}
+
+// parseQuoted parses a quoted string, p.pos being at its opening quote
+func (p *parser) parseQuoted() (string, error) {
+	start := p.pos
+	end := strings.IndexByte(p.query[start+1:], '"')
+	if end < 0 {
+		return "", p.errorf(start, "%w", ErrUnterminatedQuote)
+	}
+	p.pos = start + 1 + end + 1
+	return p.query[start+1 : start+1+end], nil
+}
+
+// parseWord parses up to the next space, quote or |
+func (p *parser) parseWord() string {
+	start := p.pos
+	for !p.atTermEnd() {
+		_, size := utf8.DecodeRuneInString(p.query[p.pos:])
+		p.pos += size
+		if p.pos < len(p.query) && p.query[p.pos] == '"' {
+			break
+		}
+	}
+	return p.query[start:p.pos]
+}
+
+// atTermEnd reports whether p.pos is at the end of a term
+func (p *parser) atTermEnd() bool {
+	if p.pos >= len(p.query) || p.query[p.pos] == '|' {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(p.query[p.pos:])
+	return unicode.IsSpace(r)
+}
+
+// skipSpace advances p.pos past white space
+func (p *parser) skipSpace() {
+	for p.pos < len(p.query) {
+		r, size := utf8.DecodeRuneInString(p.query[p.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+// errorf returns a *SyntaxError at pos
+func (p *parser) errorf(pos int, format string, args ...any) error {
+	return &SyntaxError{Query: p.query, Pos: pos, Err: fmt.Errorf(format, args...)}
+}
+
+// group combines the terms of one alternative. Its words are kept together
+// as a single Text where the first one was.
+func group(terms []Node) Node {
+	var grouped []Node
+	var text *Text
+	for _, term := range terms {
+		t, ok := term.(*Text)
+		if !ok {
+			grouped = append(grouped, term)
+			continue
+		}
+		if text == nil {
+			text = &Text{Value: t.Value, Pos: t.Pos}
+			grouped = append(grouped, text)
+			continue
+		}
+		text.Value += " " + t.Value
+	}
+
+	if len(grouped) == 1 {
+		return grouped[0]
+	}
+	return &And{Terms: grouped}
+}
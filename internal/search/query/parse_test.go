@@ -0,0 +1,148 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string // Parsed query in query syntax
+	}{
+		{"Empty", "  ", ""},
+		{"Text", "visual studio", "visual studio"},
+		{"Phrase", `"web browser"`, `"web browser"`},
+		{"Filter", "type:flatpak", "type:flatpak"},
+		{"Filter case", "TYPE:Flatpak", "type:Flatpak"},
+		{"Filter without value", "fav:", "fav:"},
+		{"Quoted filter value", `cat:"audio video"`, `cat:"audio video"`},
+		{"Unknown field", "Re:Zero", "Re:Zero"},
+		{"Exclude", "steam -proton", "steam -proton"},
+		{"Exclude filter", "-type:flatpak", "-type:flatpak"},
+		{"Exclude phrase", `-"web browser"`, `-"web browser"`},
+		{"Words kept together", "visual cat:dev studio", "visual studio cat:dev"},
+		{"Or", "firefox | chromium", "firefox | chromium"},
+		{"Or without spaces", "firefox|chromium", "firefox | chromium"},
+		{"Or binds loosest", "fire fox cat:web | chrom", "fire fox cat:web | chrom"},
+		{"Hyphenated word", "wine-staging", "wine-staging"},
+		{"Empty phrase", `"" code`, "code"},
+		{"Unicode", "Ärger -系统", "Ärger -系统"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.query, "type", "cat", "fav")
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+			got := ""
+			if node != nil {
+				got = node.String()
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	node, err := Parse(`fire -"web" | type:game`, "type")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := &Or{Terms: []Node{
+		&And{Terms: []Node{
+			&Text{Value: "fire", Pos: 0},
+			&Not{Term: &Phrase{Value: "web", Pos: 6}, Pos: 5},
+		}},
+		&Filter{Field: "type", Value: "game", Pos: 14},
+	}}
+	if !reflect.DeepEqual(node, want) {
+		t.Errorf("Parse() = %#v, want %#v", node, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		err    error
+		column int
+	}{
+		{"Unterminated quote", `code "web brow`, ErrUnterminatedQuote, 6},
+		{"Unterminated filter value", `cat:"audio`, ErrUnterminatedQuote, 5},
+		{"Leading or", "| firefox", ErrMissingTerm, 1},
+		{"Trailing or", "firefox |", ErrMissingTerm, 9},
+		{"Double or", "firefox || chromium", ErrMissingTerm, 10},
+		{"Nothing excluded", "firefox -", ErrMissingTerm, 9},
+		{"Column in characters", "ärger -", ErrMissingTerm, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query, "cat")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.query, err, tt.err)
+			}
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) || syntaxErr.Column() != tt.column {
+				t.Errorf("Parse(%q) error = %v, want column %d", tt.query, err, tt.column)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	words := map[string]bool{"a": true, "b": true}
+	match := func(n Node) bool {
+		return words[n.String()]
+	}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"a", true},
+		{"c", false},
+		{"-c", true},
+		{"-a", false},
+		{"c | b", true},
+		{"c | -a", false},
+		{`"a" -c`, false}, // Phrases are quoted by String
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+			if got := Match(node, match); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerms(t *testing.T) {
+	node, err := Parse(`fire fox "web" -steam type:game | chrom`, "type")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := FuzzyText(node); got != "fire fox chrom" {
+		t.Errorf("FuzzyText() = %q, want %q", got, "fire fox chrom")
+	}
+	if got, want := Terms(node), []string{"fire fox", "web", "chrom"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Terms() = %q, want %q", got, want)
+	}
+	if got := FuzzyText(&Filter{Field: "type", Value: "game"}); got != "" {
+		t.Errorf("FuzzyText() of a filter = %q, want empty", got)
+	}
+}
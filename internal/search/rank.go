@@ -0,0 +1,92 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/antoniosarro/gofi/internal/domain/entry"
+)
+
+// Weights balance how well results match against the signals of what the
+// user is after when ranking them
+type Weights struct {
+	Match    float64 // Per point of match score
+	Frecency float64 // For frecency scaled to [0, 1)
+	Adaptive float64 // For adaptive scores scaled to [0, 1)
+}
+
+// DefaultWeights let frecency reorder results that match about as well,
+// without lifting a weak match above an exact one. What was launched for
+// the query typed weighs more, so a habit overcomes most match differences.
+var DefaultWeights = Weights{Match: 1, Frecency: 200, Adaptive: 1500}
+
+// Signals score how likely the user is after each result, apart from how
+// well it matches. Missing signals score nothing.
+type Signals struct {
+	// Frecency scores how often and how recently an entry was launched
+	Frecency func(*entry.Entry) float64
+
+	// Adaptive scores how often an entry was launched after typing the
+	// query searched, or a longer one starting with it
+	Adaptive func(*entry.Entry) float64
+}
+
+// Breakdown explains the rank of a result
+type Breakdown struct {
+	MatchType MatchType
+	Match     int     // Score from the engine
+	Relevance int     // Match score, capped by the results the engine ranked above
+	Frecency  float64 // Frecency scaled to [0, 1)
+	Adaptive  float64 // Adaptive score scaled to [0, 1)
+	Total     float64 // Weighted sum of relevance and signals
+}
+
+// String returns the breakdown as a log line
+func (b Breakdown) String() string {
+	return fmt.Sprintf("%s %d, relevance %d, frecency %.2f, adaptive %.2f, total %.1f",
+		b.MatchType, b.Match, b.Relevance, b.Frecency, b.Adaptive, b.Total)
+}
+
+// Result is a ranked search result
+type Result struct {
+	Entry     *entry.Entry
+	Breakdown Breakdown
+}
+
+// Rank orders results by blending their match score with signals scaled
+// to [0, 1). The engine's order is the base: results only move up when
+// signals make up for matching worse, and without signals they keep the
+// engine's order.
+func Rank(scored []ScoredEntry, signals Signals, w Weights) []Result {
+	results := make([]Result, len(scored))
+
+	// Relevance never exceeds that of the results ranked above, so match
+	// types stay ordered even where their scores overlap
+	relevance := 0
+	for i, s := range scored {
+		if i == 0 || s.Score < relevance {
+			relevance = s.Score
+		}
+
+		b := Breakdown{
+			MatchType: s.MatchType,
+			Match:     s.Score,
+			Relevance: relevance,
+		}
+		if signals.Frecency != nil {
+			b.Frecency = signals.Frecency(s.Entry)
+		}
+		if signals.Adaptive != nil {
+			b.Adaptive = signals.Adaptive(s.Entry)
+		}
+		b.Total = w.Match*float64(b.Relevance) + w.Frecency*b.Frecency + w.Adaptive*b.Adaptive
+
+		results[i] = Result{Entry: s.Entry, Breakdown: b}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Breakdown.Total > results[j].Breakdown.Total
+	})
+
+	return results
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antoniosarro/gofi/internal/search/fuzzy"
+)
+
+// DefaultTypoThreshold is how many query characters tolerate one typo, so
+// "fierfox" finds Firefox while queries shorter than it must be spelled right
+const DefaultTypoThreshold = 4
+
+// maxTypos returns how many edits a query may be away from a name, or 0
+// if typos aren't tolerated
+func maxTypos(queryLower string, threshold int) int {
+	if threshold <= 0 {
+		return 0
+	}
+	return utf8.RuneCountInString(queryLower) / threshold
+}
+
+// typoDistance returns the fewest edits between the query and the name, one
+// of its words or the start of it, as long as there are at most maxEdits
+func typoDistance(queryLower, name string, maxEdits int) (int, bool) {
+	best := maxEdits + 1
+	try := func(s string) {
+		if d := utf8.RuneCountInString(s) - utf8.RuneCountInString(queryLower); d > maxEdits || -d > maxEdits {
+			return // Too many insertions or deletions
+		}
+		best = min(best, fuzzy.DamerauLevenshteinDistance(queryLower, s))
+	}
+
+	try(name)
+	if words := strings.Fields(name); len(words) > 1 {
+		for _, word := range words {
+			try(word)
+		}
+	}
+
+	// The name being typed, give or take the typos
+	runes := []rune(name)
+	n := utf8.RuneCountInString(queryLower)
+	for length := max(n-maxEdits, 1); length <= n+maxEdits && length < len(runes); length++ {
+		try(string(runes[:length]))
+	}
+
+	return best, best <= maxEdits
+}
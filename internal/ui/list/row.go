@@ -1,10 +1,16 @@
 package list
 
 import (
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/antoniosarro/gofi/internal/domain/entry"
 	"github.com/antoniosarro/gofi/internal/favorites"
+	"github.com/antoniosarro/gofi/internal/search/fuzzy"
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
 	"github.com/diamondburned/gotk4/pkg/gtk/v4"
 	"github.com/diamondburned/gotk4/pkg/pango"
 )
@@ -15,6 +21,8 @@ type RowOptions struct {
 	EnableHighlight  bool
 	Query            string
 	FavoritesManager *favorites.Manager
+	IsPinned         func(*entry.Entry) bool // Entries pinned by hand, none if nil
+	Nested           bool                    // Desktop action shown below its application
 }
 
 // createRow creates a list row for an entry
@@ -25,14 +33,20 @@ func createRow(e *entry.Entry, opts RowOptions) *gtk.Box {
 	box.SetMarginStart(12)
 	box.SetMarginEnd(12)
 
-	// Icon
-	icon := gtk.NewImage()
-	if e.Icon != "" {
-		icon.SetFromIconName(e.Icon)
-	} else {
-		icon.SetFromIconName("application-x-executable")
+	// Nested actions are indented below their application and only show the action name
+	name := e.Name
+	iconSize := 32
+	if opts.Nested && e.Parent != nil {
+		box.SetMarginStart(56)
+		box.AddCSSClass("app-action")
+		name = strings.TrimPrefix(e.Name, e.Parent.Name+entry.ActionSeparator)
+		iconSize = 16
 	}
-	icon.SetPixelSize(32)
+
+	// Artwork or icon, see setEntryIcon
+	icon := gtk.NewImage()
+	setEntryIcon(icon, e)
+	icon.SetPixelSize(iconSize)
 	box.Append(icon)
 
 	// Text container
@@ -45,15 +59,15 @@ func createRow(e *entry.Entry, opts RowOptions) *gtk.Box {
 	nameLabel.AddCSSClass("app-name")
 
 	if opts.EnableHighlight && opts.Query != "" {
-		nameLabel.SetMarkup(highlightText(e.Name, opts.Query))
+		nameLabel.SetMarkup(highlightText(name, opts.Query))
 	} else {
-		nameLabel.SetText(e.Name)
+		nameLabel.SetText(name)
 	}
 
 	textBox.Append(nameLabel)
 
 	// Description with highlighting
-	if e.Comment != "" {
+	if e.Comment != "" && !opts.Nested {
 		descLabel := gtk.NewLabel("")
 		descLabel.SetXAlign(0)
 		descLabel.SetEllipsize(pango.EllipsizeEnd)
@@ -71,8 +85,16 @@ func createRow(e *entry.Entry, opts RowOptions) *gtk.Box {
 
 	box.Append(textBox)
 
-	// Favorite star icon
-	if opts.FavoritesManager != nil && opts.FavoritesManager.IsFavorite(e) {
+	// Pin icon for entries pinned by hand, else the favorite star icon
+	if opts.IsPinned != nil && opts.IsPinned(e) {
+		pinIcon := gtk.NewImage()
+		pinIcon.SetFromIconName("view-pin-symbolic")
+		pinIcon.SetPixelSize(16)
+		pinIcon.SetTooltipText("Pinned (Ctrl+P to unpin)")
+		pinIcon.SetVAlign(gtk.AlignCenter)
+		pinIcon.AddCSSClass("pin-icon")
+		box.Append(pinIcon)
+	} else if opts.FavoritesManager != nil && opts.FavoritesManager.IsFavorite(e) {
 		starIcon := gtk.NewImage()
 		starIcon.SetFromIconName("starred-symbolic")
 		starIcon.SetPixelSize(16)
@@ -81,8 +103,18 @@ func createRow(e *entry.Entry, opts RowOptions) *gtk.Box {
 		box.Append(starIcon)
 	}
 
+	// Expander hint for applications with desktop actions
+	if len(e.Actions) > 0 {
+		expander := gtk.NewImage()
+		expander.SetFromIconName("view-more-symbolic")
+		expander.SetPixelSize(16)
+		expander.SetTooltipText("Actions (Tab)")
+		expander.SetVAlign(gtk.AlignCenter)
+		box.Append(expander)
+	}
+
 	// App type tag (only if enabled)
-	if opts.ShowTags {
+	if opts.ShowTags && !opts.Nested {
 		appType := e.GetAppType()
 		if appType != entry.AppTypeOther {
 			tag := gtk.NewLabel(string(appType))
@@ -96,45 +128,103 @@ func createRow(e *entry.Entry, opts RowOptions) *gtk.Box {
 	return box
 }
 
-// highlightText highlights the query in the text using Pango markup
-func highlightText(text, query string) string {
-	if query == "" {
-		return text
+// fallbackIcon is shown for entries without a usable image or icon
+const fallbackIcon = "application-x-executable"
+
+// setEntryIcon shows the entry's image if it is a local file, then its
+// icon, either a file or a themed icon name, then a generic icon. Remote
+// images are not fetched.
+func setEntryIcon(image *gtk.Image, e *entry.Entry) {
+	if path, ok := localFile(e.Image); ok && fileExists(path) {
+		image.SetFromFile(path)
+		return
 	}
 
-	// Escape text for Pango markup
-	text = strings.ReplaceAll(text, "&", "&amp;")
-	text = strings.ReplaceAll(text, "<", "&lt;")
-	text = strings.ReplaceAll(text, ">", "&gt;")
+	if path, ok := localFile(e.Icon); ok {
+		if fileExists(path) {
+			image.SetFromFile(path)
+			return
+		}
+	} else if e.Icon != "" && hasThemedIcon(e.Icon) {
+		image.SetFromIconName(e.Icon)
+		return
+	}
 
-	queryLower := strings.ToLower(query)
-	textLower := strings.ToLower(text)
+	image.SetFromIconName(fallbackIcon)
+}
 
-	// Find all occurrences of the query
-	result := ""
-	lastIndex := 0
+// hasThemedIcon reports whether the icon theme provides an icon
+func hasThemedIcon(name string) bool {
+	theme := gtk.IconThemeGetForDisplay(gdk.DisplayGetDefault())
+	return theme == nil || theme.HasIcon(name)
+}
 
-	for {
-		index := strings.Index(textLower[lastIndex:], queryLower)
-		if index == -1 {
-			// No more matches, append the rest
-			result += text[lastIndex:]
-			break
-		}
+// fileExists reports whether a file exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
 
-		// Adjust index to be relative to the original string
-		index += lastIndex
+// localFile returns the path of an absolute file path or file:// URL
+func localFile(location string) (string, bool) {
+	if filepath.IsAbs(location) {
+		return location, true
+	}
+	if u, err := url.Parse(location); err == nil && u.Scheme == "file" {
+		return u.Path, true
+	}
+	return "", false
+}
 
-		// Append text before match
-		result += text[lastIndex:index]
+// Pango markup around highlighted characters
+const (
+	highlightStart = `<span background="#89b4fa" foreground="#1e1e2e" weight="bold">`
+	highlightEnd   = `</span>`
+)
+
+// markupEscaper escapes text for Pango markup
+var markupEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
 
-		// Append highlighted match
-		matchedText := text[index : index+len(query)]
-		result += `<span background="#89b4fa" foreground="#1e1e2e" weight="bold">` + matchedText + `</span>`
+// highlightMatcher finds the characters the query matches, as the search
+// engine does
+var highlightMatcher = fuzzy.New()
 
-		// Move past this match
-		lastIndex = index + len(query)
+// highlightText highlights the characters of the text the query matches
+// using Pango markup
+func highlightText(text, query string) string {
+	var matched []int
+	if query != "" {
+		if result := highlightMatcher.Match(query, text); result != nil {
+			matched = result.MatchedIndices
+		}
+	}
+
+	var b strings.Builder
+	open := false
+	next := 0 // Next matched rune index
+	i := 0
+	for _, r := range text {
+		hit := next < len(matched) && matched[next] == i
+		if hit {
+			next++
+		}
+		// Combining marks belong to the highlighted letter before them
+		hit = hit || open && unicode.Is(unicode.Mn, r)
+
+		if hit != open {
+			if hit {
+				b.WriteString(highlightStart)
+			} else {
+				b.WriteString(highlightEnd)
+			}
+			open = hit
+		}
+		b.WriteString(markupEscaper.Replace(string(r)))
+		i++
+	}
+	if open {
+		b.WriteString(highlightEnd)
 	}
 
-	return result
+	return b.String()
 }
@@ -50,6 +50,36 @@ func TestHighlightText(t *testing.T) {
 			query:    "test",
 			contains: []string{"&amp;", "&lt;", "&gt;"},
 		},
+		{
+			name:     "Fuzzy match",
+			text:     "Visual Studio Code",
+			query:    "vsc",
+			contains: []string{">V</span>isual", ">S</span>tudio", ">C</span>ode"},
+		},
+		{
+			name:     "Diacritics",
+			text:     "Ärger",
+			query:    "arger",
+			contains: []string{`weight="bold">Ärger</span>`},
+		},
+		{
+			name:     "Decomposed diacritics",
+			text:     "A\u0308rger",
+			query:    "arg",
+			contains: []string{"bold\">A\u0308rg</span>er"},
+		},
+		{
+			name:     "CJK",
+			text:     "系统设置",
+			query:    "设置",
+			contains: []string{`系统<span`, `bold">设置</span>`},
+		},
+		{
+			name:     "Emoji",
+			text:     "🎮 Steam & Games",
+			query:    "steam",
+			contains: []string{`🎮 <span`, `bold">Steam</span> &amp; Games`},
+		},
 	}
 
 	for _, tt := range tests {
@@ -128,3 +158,39 @@ func TestCreateRowWithTag(t *testing.T) {
 		t.Fatal("createRow() returned nil")
 	}
 }
+
+func TestLocalFile(t *testing.T) {
+	tests := []struct {
+		location string
+		wantPath string
+		wantOK   bool
+	}{
+		{"/home/user/.config/heroic/images-cache/abc", "/home/user/.config/heroic/images-cache/abc", true},
+		{"file:///home/user/cover%20art.png", "/home/user/cover art.png", true},
+		{"https://example.com/cover.jpg", "", false},
+		{"firefox", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		path, ok := localFile(tt.location)
+		if path != tt.wantPath || ok != tt.wantOK {
+			t.Errorf("localFile(%q) = %q, %v, want %q, %v", tt.location, path, ok, tt.wantPath, tt.wantOK)
+		}
+	}
+}
+
+func TestCreateRowWithMissingImage(t *testing.T) {
+	e := &entry.Entry{
+		Name:  "Fortnite",
+		Icon:  "applications-games",
+		Image: "/nonexistent/cover.jpg",
+		Path:  "heroic-legendary-Fortnite",
+	}
+
+	row := createRow(e, RowOptions{})
+
+	if row == nil {
+		t.Fatal("createRow() returned nil")
+	}
+}
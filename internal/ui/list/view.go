@@ -16,7 +16,9 @@ type View struct {
 	showTags         bool
 	enableHighlight  bool
 	favoritesManager *favorites.Manager
+	isPinned         func(*entry.Entry) bool
 	currentQuery     string
+	expanded         *entry.Entry // Entry whose actions are shown below it
 }
 
 // Option is a functional option for View
@@ -43,6 +45,13 @@ func WithFavoritesManager(fm *favorites.Manager) Option {
 	}
 }
 
+// WithPinned sets how entries pinned by hand are told apart, to mark them
+func WithPinned(isPinned func(*entry.Entry) bool) Option {
+	return func(v *View) {
+		v.isPinned = isPinned
+	}
+}
+
 // New creates a new list view with pagination
 func New(entries []*entry.Entry, itemsPerPage int, opts ...Option) *View {
 	v := &View{
@@ -86,11 +95,14 @@ func (v *View) populate() {
 
 	// Add new rows for current page
 	for i := start; i < end && i < len(v.entries); i++ {
-		row := createRow(v.entries[i], RowOptions{
+		e := v.entries[i]
+		row := createRow(e, RowOptions{
 			ShowTags:         v.showTags,
 			EnableHighlight:  v.enableHighlight,
 			Query:            v.currentQuery,
 			FavoritesManager: v.favoritesManager,
+			IsPinned:         v.isPinned,
+			Nested:           v.expanded != nil && e.Parent == v.expanded,
 		})
 		v.listBox.Append(row)
 	}
@@ -120,6 +132,7 @@ func (v *View) onRowActivated(row *gtk.ListBoxRow) {
 func (v *View) Update(entries []*entry.Entry, query string) {
 	v.entries = entries
 	v.currentQuery = query
+	v.expanded = nil
 	v.paginator.SetTotalItems(len(entries))
 	v.paginator.Reset()
 	v.populate()
@@ -192,6 +205,117 @@ func (v *View) ActivateSelected() {
 	}
 }
 
+// ToggleActions shows or hides the desktop actions of the selected entry
+func (v *View) ToggleActions() bool {
+	selected := v.SelectedEntry()
+	if selected == nil {
+		return false
+	}
+	if v.expanded != nil && (selected == v.expanded || selected.Parent == v.expanded) {
+		return v.CollapseActions()
+	}
+	return v.ExpandActions()
+}
+
+// ExpandActions shows the desktop actions of the selected entry as
+// secondary rows below it
+func (v *View) ExpandActions() bool {
+	selected := v.SelectedEntry()
+	if selected == nil || selected == v.expanded || len(selected.Actions) == 0 {
+		return false
+	}
+
+	v.removeExpanded()
+
+	index := v.indexOf(selected)
+	actions := selected.ActionEntries()
+	entries := make([]*entry.Entry, 0, len(v.entries)+len(actions))
+	entries = append(entries, v.entries[:index+1]...)
+	entries = append(entries, actions...)
+	entries = append(entries, v.entries[index+1:]...)
+
+	v.entries = entries
+	v.expanded = selected
+	v.paginator.SetTotalItems(len(v.entries))
+	v.populate()
+	v.selectIndex(index)
+	return true
+}
+
+// CollapseActions hides the expanded desktop actions and selects their entry
+func (v *View) CollapseActions() bool {
+	if v.expanded == nil {
+		return false
+	}
+
+	parent := v.expanded
+	v.removeExpanded()
+	v.paginator.SetTotalItems(len(v.entries))
+	v.populate()
+	v.selectIndex(v.indexOf(parent))
+	return true
+}
+
+// removeExpanded removes the action rows of the expanded entry
+func (v *View) removeExpanded() {
+	if v.expanded == nil {
+		return
+	}
+
+	entries := make([]*entry.Entry, 0, len(v.entries))
+	for _, e := range v.entries {
+		if e.Parent != v.expanded {
+			entries = append(entries, e)
+		}
+	}
+	v.entries = entries
+	v.expanded = nil
+}
+
+// SelectEntry selects the row of an entry if it is on the current page
+func (v *View) SelectEntry(e *entry.Entry) bool {
+	index := v.indexOf(e)
+	start, end := v.paginator.GetPageItems()
+	if index < start || index >= end {
+		return false
+	}
+	v.selectIndex(index)
+	return true
+}
+
+// SelectedEntry returns the entry of the selected row
+func (v *View) SelectedEntry() *entry.Entry {
+	selected := v.listBox.SelectedRow()
+	if selected == nil {
+		return nil
+	}
+
+	start, _ := v.paginator.GetPageItems()
+	index := start + selected.Index()
+	if index < 0 || index >= len(v.entries) {
+		return nil
+	}
+	return v.entries[index]
+}
+
+// indexOf returns the position of an entry in the list
+func (v *View) indexOf(e *entry.Entry) int {
+	for i, candidate := range v.entries {
+		if candidate == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectIndex selects the row of the entry at index if it is on the current page
+func (v *View) selectIndex(index int) {
+	start, end := v.paginator.GetPageItems()
+	if index >= start && index < end {
+		v.listBox.SelectRow(v.listBox.RowAtIndex(index - start))
+	}
+}
+
 // OnActivate sets the activation callback
 func (v *View) OnActivate(fn func(*entry.Entry)) {
 	v.onActivate = fn
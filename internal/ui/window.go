@@ -2,10 +2,14 @@ package ui
 
 import (
 	"log"
+	"strings"
 
 	"github.com/antoniosarro/gofi/internal/config"
 	"github.com/antoniosarro/gofi/internal/domain/entry"
 	"github.com/antoniosarro/gofi/internal/scanner"
+	"github.com/antoniosarro/gofi/internal/scanner/watcher"
+	"github.com/antoniosarro/gofi/internal/search"
+	"github.com/antoniosarro/gofi/internal/search/query"
 	"github.com/antoniosarro/gofi/internal/ui/list"
 	"github.com/diamondburned/gotk4/pkg/gdk/v4"
 	"github.com/diamondburned/gotk4/pkg/glib/v2"
@@ -32,6 +36,7 @@ type Window struct {
 	moduleConfig  *config.ModuleConfig
 	itemsPerPage  int
 	debounceTimer glib.SourceHandle
+	lastHidden    *entry.Entry // Entry Ctrl+Z shows again
 }
 
 // New creates a new launcher window
@@ -88,6 +93,7 @@ func New(app *gtk.Application, s *scanner.Scanner, moduleConfig *config.ModuleCo
 		list.WithShowTags(moduleConfig.EnableTags),
 		list.WithHighlight(moduleConfig.EnableHighlight),
 		list.WithFavoritesManager(s.GetFavoritesManager()),
+		list.WithPinned(s.IsPinned),
 	)
 	w.listView.OnActivate(w.onAppActivate)
 	w.scrolled.SetChild(w.listView.Widget())
@@ -157,12 +163,41 @@ func (w *Window) onSearchChangedDebounced() {
 func (w *Window) onSearchChanged() {
 	query := w.searchEntry.Text()
 	filtered := w.scanner.Filter(query, entry.AppTypeAll)
-	w.listView.Update(filtered, query)
+	w.listView.Update(filtered, w.showQuery(query))
 	if w.moduleConfig.EnablePagination {
 		w.updatePageLabel()
 	}
 }
 
+// showQuery points out mistakes in the query, such as type:flatpk, and
+// returns the text to highlight in results
+func (w *Window) showQuery(q string) string {
+	node, err := search.ParseQuery(q)
+	if err != nil {
+		// The query is searched as text
+		w.searchEntry.AddCSSClass("error")
+		w.searchEntry.SetTooltipText(err.Error())
+		return q
+	}
+
+	w.searchEntry.RemoveCSSClass("error")
+	w.searchEntry.SetTooltipText("")
+	return strings.Join(query.Terms(node), " ")
+}
+
+// Watch refreshes the list whenever the watcher applies changes to the scanner
+func (w *Window) Watch(events <-chan watcher.Event) {
+	go func() {
+		for range events {
+			// Widgets may only be touched from the main loop
+			glib.IdleAdd(func() bool {
+				w.onSearchChanged()
+				return false
+			})
+		}
+	}()
+}
+
 // updatePageLabel updates the pagination label
 func (w *Window) updatePageLabel() {
 	if w.pageLabel != nil {
@@ -193,6 +228,10 @@ func (w *Window) scrollToSelected() {
 
 // onKeyPressed handles keyboard shortcuts
 func (w *Window) onKeyPressed(keyval uint, _ uint, state gdk.ModifierType) bool {
+	if state&(gdk.ControlMask|gdk.AltMask) != 0 && w.onPinKeyPressed(keyval, state) {
+		return true
+	}
+
 	switch keyval {
 	case gdk.KEY_Escape:
 		w.window.Close()
@@ -207,11 +246,20 @@ func (w *Window) onKeyPressed(keyval uint, _ uint, state gdk.ModifierType) bool
 		w.scrollToSelected()
 		return true
 
+	case gdk.KEY_Tab:
+		w.listView.ToggleActions()
+		w.updatePageLabel()
+		w.scrollToSelected()
+		return true
+
 	case gdk.KEY_Page_Down, gdk.KEY_Right:
 		if w.moduleConfig.EnablePagination {
 			w.listView.NextPage()
 			w.updatePageLabel()
 			w.scrollToSelected()
+		} else if keyval == gdk.KEY_Right {
+			w.listView.ExpandActions()
+			w.scrollToSelected()
 		}
 		return true
 	case gdk.KEY_Page_Up, gdk.KEY_Left:
@@ -219,6 +267,9 @@ func (w *Window) onKeyPressed(keyval uint, _ uint, state gdk.ModifierType) bool
 			w.listView.PreviousPage()
 			w.updatePageLabel()
 			w.scrollToSelected()
+		} else if keyval == gdk.KEY_Left {
+			w.listView.CollapseActions()
+			w.scrollToSelected()
 		}
 		return true
 
@@ -229,11 +280,69 @@ func (w *Window) onKeyPressed(keyval uint, _ uint, state gdk.ModifierType) bool
 	return false
 }
 
+// onPinKeyPressed handles the shortcuts pinning and hiding the selected
+// entry: Ctrl+P pins or unpins it, Alt+Up and Alt+Down move it among the
+// pinned entries, Ctrl+H hides it and Ctrl+Z shows the last hidden entry
+// again
+func (w *Window) onPinKeyPressed(keyval uint, state gdk.ModifierType) bool {
+	ctrl := state&gdk.ControlMask != 0
+	alt := state&gdk.AltMask != 0
+
+	e := w.listView.SelectedEntry()
+	var err error
+	switch {
+	case ctrl && keyval == gdk.KEY_z:
+		if w.lastHidden == nil {
+			return false // Leave Ctrl+Z to the search entry
+		}
+		e, w.lastHidden = w.lastHidden, nil
+		err = w.scanner.Unhide(e)
+	case e == nil:
+		return false
+	case ctrl && keyval == gdk.KEY_p:
+		if w.scanner.IsPinned(e) {
+			err = w.scanner.Unpin(e)
+		} else {
+			err = w.scanner.Pin(e)
+		}
+	case alt && (keyval == gdk.KEY_Up || keyval == gdk.KEY_Down):
+		if !w.scanner.IsPinned(e) {
+			return false
+		}
+		offset := 1
+		if keyval == gdk.KEY_Up {
+			offset = -1
+		}
+		err = w.scanner.MovePin(e, offset)
+	case ctrl && keyval == gdk.KEY_h:
+		err = w.scanner.Hide(e)
+		w.lastHidden = e
+	default:
+		return false
+	}
+
+	if err != nil {
+		log.Printf("Warning: Failed to save pins: %v", err)
+	}
+
+	// Show the change, keeping the entry selected if it is still listed
+	w.onSearchChanged()
+	if e.Parent != nil {
+		e = e.Parent
+	}
+	if w.listView.SelectEntry(e) {
+		w.scrollToSelected()
+	}
+	return true
+}
+
 // onAppActivate handles application launch
 func (w *Window) onAppActivate(e *entry.Entry) {
 	// Record launch event SYNCHRONOUSLY before closing
 	if fm := w.scanner.GetFavoritesManager(); fm != nil {
 		fm.RecordLaunch(e)
+		// Learn what is picked for the text typed, filters left out
+		fm.RecordSelection(search.QueryText(w.searchEntry.Text()), e)
 		// Save immediately (synchronous)
 		if err := fm.Save(); err != nil {
 			log.Printf("Warning: Failed to save favorites: %v", err)